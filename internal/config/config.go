@@ -14,12 +14,21 @@ type Config struct {
 	MidtransServerKey    string
 	MidtransClientKey    string
 	MidtransIsProduction bool
+
+	// Payment gateway: "midtrans" (default) atau "fake" untuk QA/CI offline
+	PaymentGateway        string
+	FakeGatewayServerKey  string
+	FakeGatewayWebhookURL string
+
+	// Matikan hanya untuk load test lokal
+	RateLimitEnabled bool
 }
 
 var AppConfig Config
 
 func LoadConfig() {
 	isProd, _ := strconv.ParseBool(getEnv("MIDTRANS_IS_PRODUCTION", "false"))
+	rateLimitEnabled, _ := strconv.ParseBool(getEnv("RATE_LIMIT_ENABLED", "true"))
 
 	AppConfig = Config{
 		Port:                 getEnv("PORT", "8080"),
@@ -27,9 +36,15 @@ func LoadConfig() {
 		MidtransServerKey:    getEnv("MIDTRANS_SERVER_KEY", ""),
 		MidtransClientKey:    getEnv("MIDTRANS_CLIENT_KEY", ""),
 		MidtransIsProduction: isProd,
+
+		PaymentGateway:        getEnv("PAYMENT_GATEWAY", "midtrans"),
+		FakeGatewayServerKey:  getEnv("FAKE_GATEWAY_SERVER_KEY", "fake-server-key"),
+		FakeGatewayWebhookURL: getEnv("FAKE_GATEWAY_WEBHOOK_URL", ""),
+
+		RateLimitEnabled: rateLimitEnabled,
 	}
 
-	if AppConfig.MidtransServerKey == "" {
+	if AppConfig.PaymentGateway == "midtrans" && AppConfig.MidtransServerKey == "" {
 		log.Println("WARNING: MIDTRANS_SERVER_KEY is missing in .env")
 	}
 
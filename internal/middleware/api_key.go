@@ -0,0 +1,123 @@
+package middleware
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"ezytix-be/internal/config"
+	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidAPIKey = apperror.Unauthorized("INVALID_API_KEY", "invalid, expired or revoked API key")
+var ErrAPIKeyNotAllowed = apperror.Forbidden("API_KEY_NOT_ALLOWED", "API keys are not accepted on this endpoint")
+var ErrInsufficientScope = apperror.Forbidden("INSUFFICIENT_SCOPE", "API key does not have the required scope")
+
+// APIKeyPrincipal adalah identitas request yang masuk lewat API key partner.
+type APIKeyPrincipal struct {
+	KeyID              uint
+	UserID             uint
+	Role               string
+	Scopes             []string
+	RateLimitPerMinute int
+}
+
+func (p *APIKeyPrincipal) HasScope(scope string) bool {
+	for _, s := range p.Scopes {
+		if s == scope {
+			return true
+		}
+	}
+	return false
+}
+
+// APIKeyAuthenticator mencocokkan API key mentah dengan key yang tersimpan.
+// Diimplementasikan oleh modul apikey agar middleware tidak bergantung ke
+// repository.
+type APIKeyAuthenticator interface {
+	AuthenticateAPIKey(rawKey, clientIP string) (*APIKeyPrincipal, error)
+}
+
+var apiKeyAuthenticator APIKeyAuthenticator
+
+func SetAPIKeyAuthenticator(authenticator APIKeyAuthenticator) {
+	apiKeyAuthenticator = authenticator
+}
+
+// CurrentAPIKey mengembalikan API key yang dipakai request, jika ada.
+func CurrentAPIKey(c *fiber.Ctx) (*APIKeyPrincipal, bool) {
+	principal, ok := c.Locals("api_key").(*APIKeyPrincipal)
+	return principal, ok && principal != nil
+}
+
+// RequireAuth dipakai di route yang terbuka untuk partner: menerima login
+// user seperti JWTMiddleware, atau API key. Scope dicek per route dengan
+// RequireScope.
+func RequireAuth(c *fiber.Ctx) error {
+	if rawKey := apiKeyFromRequest(c); rawKey != "" {
+		return authenticateAPIKey(c, rawKey)
+	}
+	return JWTMiddleware(c)
+}
+
+// OptionalAPIKey dipakai di route publik. Request tanpa API key tetap lolos;
+// request dengan API key diautentikasi agar dibatasi sesuai limit key-nya,
+// bukan limit per IP.
+func OptionalAPIKey(c *fiber.Ctx) error {
+	if rawKey := apiKeyFromRequest(c); rawKey != "" {
+		return authenticateAPIKey(c, rawKey)
+	}
+	return c.Next()
+}
+
+// RequireScope hanya berlaku untuk request dengan API key. User yang login
+// lewat cookie atau Bearer token punya akses penuh seperti sebelumnya.
+func RequireScope(scope string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		principal, ok := CurrentAPIKey(c)
+		if !ok || principal.HasScope(scope) {
+			return c.Next()
+		}
+		return ErrInsufficientScope.Withf("API key does not have the %s scope", scope)
+	}
+}
+
+// apiKeyFromRequest membaca key dari header X-API-Key atau dari
+// Authorization: Bearer jika tokennya berawalan prefix API key.
+func apiKeyFromRequest(c *fiber.Ctx) string {
+	if key := strings.TrimSpace(c.Get("X-API-Key")); key != "" {
+		return key
+	}
+	if token, ok := bearerToken(c.Get(fiber.HeaderAuthorization)); ok && strings.HasPrefix(token, models.APIKeyPrefix) {
+		return token
+	}
+	return ""
+}
+
+func authenticateAPIKey(c *fiber.Ctx, rawKey string) error {
+	if apiKeyAuthenticator == nil {
+		return ErrInvalidAPIKey
+	}
+
+	principal, err := apiKeyAuthenticator.AuthenticateAPIKey(rawKey, c.IP())
+	if err != nil {
+		return err
+	}
+
+	if config.AppConfig.RateLimitEnabled {
+		key := fmt.Sprintf("api-key:%d", principal.KeyID)
+		if err := consumeRateLimit(c, DefaultRateLimitStore, key, principal.RateLimitPerMinute, time.Minute, ErrRateLimited); err != nil {
+			return err
+		}
+	}
+
+	// Handler yang sudah ada cukup membaca claims user seperti biasa
+	c.Locals("user", &jwt.JWTClaims{UserID: principal.UserID, Role: principal.Role})
+	c.Locals("api_key", principal)
+
+	return c.Next()
+}
@@ -0,0 +1,68 @@
+package middleware
+
+import (
+	"errors"
+	"log"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"ezytix-be/pkg/apperror"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+// ErrorHandler adalah fiber.Config.ErrorHandler. Semua error yang dikembalikan
+// handler diubah ke satu format: {"status", "code", "message", "errors"?}.
+func ErrorHandler(c *fiber.Ctx, err error) error {
+	var fiberErr *fiber.Error
+	if errors.As(err, &fiberErr) {
+		return c.Status(fiberErr.Code).JSON(fiber.Map{
+			"status":  "error",
+			"code":    codeFromStatus(fiberErr.Code),
+			"message": fiberErr.Message,
+		})
+	}
+
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		err = apperror.NotFound("RESOURCE_NOT_FOUND", "resource not found")
+	}
+
+	appErr := apperror.From(err)
+	message := err.Error()
+	if appErr.Kind == apperror.KindInternal {
+		log.Printf("[ERROR] %s %s: %v", c.Method(), c.Path(), err)
+		message = "internal server error"
+	}
+
+	if appErr.RetryAfter > 0 {
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(retryAfterSeconds(appErr.RetryAfter)))
+	}
+
+	body := fiber.Map{
+		"status":  "error",
+		"code":    appErr.Code,
+		"message": message,
+	}
+	if appErr.Details != nil {
+		body["errors"] = appErr.Details
+	}
+
+	return c.Status(apperror.HTTPStatus(appErr.Kind)).JSON(body)
+}
+
+// retryAfterSeconds membulatkan ke atas agar klien tidak mencoba terlalu cepat.
+func retryAfterSeconds(d time.Duration) int {
+	return int(math.Ceil(d.Seconds()))
+}
+
+func codeFromStatus(status int) string {
+	text := http.StatusText(status)
+	if text == "" {
+		return apperror.CodeInternal
+	}
+	return strings.ToUpper(strings.ReplaceAll(text, " ", "_"))
+}
@@ -1,27 +1,65 @@
 package middleware
 
 import (
+	"strings"
+
+	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
 	"ezytix-be/pkg/jwt"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+var ErrMissingAccessToken = apperror.Unauthorized("MISSING_ACCESS_TOKEN", "missing access token")
+var ErrInvalidAccessToken = apperror.Unauthorized("INVALID_ACCESS_TOKEN", "invalid or expired access token")
+var ErrInvalidAuthorizationHeader = apperror.Unauthorized("INVALID_AUTHORIZATION_HEADER", "authorization header must use the Bearer scheme")
+
+// JWTMiddleware menerima access token dari header Authorization: Bearer
+// (aplikasi mobile, CLI) atau dari cookie access_token (browser). API key
+// partner ditolak di sini; route yang terbuka untuk partner memakai RequireAuth.
 func JWTMiddleware(c *fiber.Ctx) error {
-	token := c.Cookies("access_token")
-	if token == "" {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "missing access token cookie",
-		})
+	token, err := accessToken(c)
+	if err != nil {
+		return err
+	}
+	if strings.HasPrefix(token, models.APIKeyPrefix) {
+		return ErrAPIKeyNotAllowed
 	}
 
 	claims, err := jwt.ValidateAccessToken(token)
 	if err != nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"error": "invalid or expired access token",
-		})
+		return ErrInvalidAccessToken
 	}
 
 	c.Locals("user", claims)
 
 	return c.Next()
 }
+
+// accessToken mendahulukan header Authorization agar klien yang juga membawa
+// cookie tetap memakai token yang dikirim secara eksplisit.
+func accessToken(c *fiber.Ctx) (string, error) {
+	if header := c.Get(fiber.HeaderAuthorization); header != "" {
+		token, ok := bearerToken(header)
+		if !ok {
+			return "", ErrInvalidAuthorizationHeader
+		}
+		return token, nil
+	}
+
+	token := c.Cookies("access_token")
+	if token == "" {
+		return "", ErrMissingAccessToken
+	}
+	return token, nil
+}
+
+func bearerToken(header string) (string, bool) {
+	scheme, token, ok := strings.Cut(strings.TrimSpace(header), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+
+	token = strings.TrimSpace(token)
+	return token, token != ""
+}
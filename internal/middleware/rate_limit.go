@@ -0,0 +1,163 @@
+package middleware
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	"ezytix-be/internal/config"
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/jwt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrRateLimited = apperror.TooManyRequests("RATE_LIMITED", "too many requests, please try again later")
+
+// RateLimitStore menyimpan hitungan request per key dalam fixed window.
+// MemoryStore cukup untuk satu instance; jika aplikasi dijalankan lebih dari
+// satu instance, pasang store bersama (misalnya Redis) yang memenuhi interface ini.
+type RateLimitStore interface {
+	// Increment menambah hitungan key dan mengembalikan jumlah request di
+	// window berjalan beserta waktu window tersebut berakhir.
+	Increment(key string, window time.Duration) (count int, resetAt time.Time, err error)
+}
+
+// DefaultRateLimitStore dipakai oleh RateLimit jika Store tidak diisi.
+var DefaultRateLimitStore RateLimitStore = NewMemoryStore(time.Minute)
+
+// KeyFunc menentukan identitas pemakai yang dibatasi, misalnya IP atau user.
+type KeyFunc func(c *fiber.Ctx) string
+
+func KeyByIP(c *fiber.Ctx) string {
+	return "ip:" + c.IP()
+}
+
+// KeyByUser membatasi per user yang login. Request tanpa token jatuh ke IP,
+// jadi middleware ini boleh dipasang sebelum atau sesudah JWTMiddleware.
+func KeyByUser(c *fiber.Ctx) string {
+	if claims, ok := c.Locals("user").(*jwt.JWTClaims); ok && claims != nil {
+		return fmt.Sprintf("user:%d", claims.UserID)
+	}
+	return KeyByIP(c)
+}
+
+type RateLimitConfig struct {
+	// Name membedakan hitungan antar grup route yang memakai key yang sama
+	Name    string
+	Max     int
+	Window  time.Duration
+	KeyFunc KeyFunc
+	Store   RateLimitStore
+	// Error yang dikembalikan saat batas tercapai, default ErrRateLimited
+	Error *apperror.Error
+}
+
+// RateLimit membatasi jumlah request per key dalam satu window. Header
+// X-RateLimit-* selalu dikirim; Retry-After dikirim saat request ditolak.
+func RateLimit(cfg RateLimitConfig) fiber.Handler {
+	if cfg.KeyFunc == nil {
+		cfg.KeyFunc = KeyByIP
+	}
+	if cfg.Store == nil {
+		cfg.Store = DefaultRateLimitStore
+	}
+	if cfg.Error == nil {
+		cfg.Error = ErrRateLimited
+	}
+
+	return func(c *fiber.Ctx) error {
+		if !config.AppConfig.RateLimitEnabled {
+			return c.Next()
+		}
+		// Request dengan API key sudah dibatasi oleh limit per key-nya sendiri
+		if _, ok := CurrentAPIKey(c); ok {
+			return c.Next()
+		}
+
+		key := cfg.Name + ":" + cfg.KeyFunc(c)
+		if err := consumeRateLimit(c, cfg.Store, key, cfg.Max, cfg.Window, cfg.Error); err != nil {
+			return err
+		}
+		return c.Next()
+	}
+}
+
+// consumeRateLimit mencatat satu request untuk key dan mengisi header
+// X-RateLimit-*. Error hanya dikembalikan jika batas sudah terlampaui.
+func consumeRateLimit(c *fiber.Ctx, store RateLimitStore, key string, max int, window time.Duration, limitErr *apperror.Error) error {
+	count, resetAt, err := store.Increment(key, window)
+	if err != nil {
+		// Store bermasalah tidak boleh membuat seluruh API ikut mati
+		log.Printf("[RATE LIMIT] store error for %s: %v\n", key, err)
+		return nil
+	}
+
+	remaining := max - count
+	if remaining < 0 {
+		remaining = 0
+	}
+	c.Set("X-RateLimit-Limit", strconv.Itoa(max))
+	c.Set("X-RateLimit-Remaining", strconv.Itoa(remaining))
+	c.Set("X-RateLimit-Reset", strconv.FormatInt(resetAt.Unix(), 10))
+
+	if count > max {
+		return limitErr.WithRetryAfter(time.Until(resetAt))
+	}
+	return nil
+}
+
+type memoryEntry struct {
+	count   int
+	resetAt time.Time
+}
+
+type MemoryStore struct {
+	mu      sync.Mutex
+	entries map[string]*memoryEntry
+}
+
+// NewMemoryStore membuat store in-memory yang membersihkan window kedaluwarsa
+// setiap cleanupInterval.
+func NewMemoryStore(cleanupInterval time.Duration) *MemoryStore {
+	store := &MemoryStore{entries: make(map[string]*memoryEntry)}
+
+	go func() {
+		ticker := time.NewTicker(cleanupInterval)
+		defer ticker.Stop()
+		for range ticker.C {
+			store.cleanup()
+		}
+	}()
+
+	return store
+}
+
+func (s *MemoryStore) Increment(key string, window time.Duration) (int, time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.entries[key]
+	if !ok || !now.Before(entry.resetAt) {
+		entry = &memoryEntry{resetAt: now.Add(window)}
+		s.entries[key] = entry
+	}
+	entry.count++
+
+	return entry.count, entry.resetAt, nil
+}
+
+func (s *MemoryStore) cleanup() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for key, entry := range s.entries {
+		if !now.Before(entry.resetAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -2,17 +2,19 @@ package middleware
 
 import (
 	"github.com/gofiber/fiber/v2"
+	"ezytix-be/pkg/apperror"
 	"ezytix-be/pkg/jwt"
 )
 
+var ErrUnauthorized = apperror.Unauthorized("UNAUTHORIZED", "unauthorized")
+var ErrInsufficientRole = apperror.Forbidden("FORBIDDEN", "forbidden: insufficient permission")
+
 func RequireRole(allowedRoles ...string) fiber.Handler {
 	return func(c *fiber.Ctx) error {
 
 		claims, ok := c.Locals("user").(*jwt.JWTClaims)
 		if !ok {
-			return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-				"error": "unauthorized",
-			})
+			return ErrUnauthorized
 		}
 
 		userRole := claims.Role
@@ -23,8 +25,6 @@ func RequireRole(allowedRoles ...string) fiber.Handler {
 			}
 		}
 
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"error": "forbidden: insufficient permission",
-		})
+		return ErrInsufficientRole
 	}
 }
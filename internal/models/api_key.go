@@ -0,0 +1,52 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+// APIKeyPrefix menandai API key partner sehingga bisa dibedakan dari JWT
+// saat dikirim lewat header Authorization: Bearer.
+const APIKeyPrefix = "ezk_"
+
+// Scope yang bisa diberikan ke API key partner
+const (
+	ScopeFlightsRead   = "flights:read"
+	ScopeBookingsRead  = "bookings:read"
+	ScopeBookingsWrite = "bookings:write"
+	ScopePaymentsWrite = "payments:write"
+)
+
+// APIKey dipakai travel agent (B2B) untuk mengakses API tanpa login. Key
+// mentah hanya ditampilkan sekali saat dibuat; yang disimpan hanya hash-nya.
+// Semua aksi lewat key dianggap dilakukan oleh user pemiliknya.
+type APIKey struct {
+	ID        uint   `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint   `json:"user_id" gorm:"not null;index"`
+	User      User   `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Name      string `json:"name" gorm:"size:100;not null"`
+	KeyPrefix string `json:"key_prefix" gorm:"size:16;not null"`
+	KeyHash   string `json:"-" gorm:"size:64;not null;uniqueIndex"`
+	// Scopes disimpan dipisah spasi, misalnya "flights:read bookings:write"
+	Scopes             string     `json:"-" gorm:"size:255;not null"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute" gorm:"not null;default:60"`
+	LastUsedAt         *time.Time `json:"last_used_at"`
+	LastUsedIP         *string    `json:"last_used_ip" gorm:"size:45"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	RevokedAt          *time.Time `json:"revoked_at"`
+	CreatedBy          uint       `json:"created_by" gorm:"not null"`
+	CreatedAt          time.Time  `json:"created_at"`
+	UpdatedAt          time.Time  `json:"updated_at"`
+}
+
+func (APIKey) TableName() string {
+	return "api_keys"
+}
+
+func (k *APIKey) ScopeList() []string {
+	return strings.Fields(k.Scopes)
+}
+
+func (k *APIKey) IsActive(now time.Time) bool {
+	return k.RevokedAt == nil && (k.ExpiresAt == nil || now.Before(*k.ExpiresAt))
+}
@@ -15,6 +15,7 @@ const (
 
 	TripTypeOneWay    = "one_way"
 	TripTypeRoundTrip = "round_trip"
+	TripTypeMultiCity = "multi_city"
 )
 
 type Booking struct {
@@ -28,6 +29,16 @@ type Booking struct {
 	TripType 		string `json:"trip_type" gorm:"type:trip_type;default:'one_way';not null"`
 	TotalPassengers int     `json:"total_passengers" gorm:"not null"`
 	TotalPrice      decimal.Decimal `json:"total_price" gorm:"type:numeric(15,2);not null"`
+	PromoCode       *string         `json:"promo_code" gorm:"size:30"`
+	BaseFare        decimal.Decimal `json:"base_fare" gorm:"type:numeric(15,2);default:0;not null"`
+	AirportTax      decimal.Decimal `json:"airport_tax" gorm:"type:numeric(15,2);default:0;not null"`
+	VATAmount       decimal.Decimal `json:"vat_amount" gorm:"column:vat_amount;type:numeric(15,2);default:0;not null"`
+	ConvenienceFee  decimal.Decimal `json:"convenience_fee" gorm:"type:numeric(15,2);default:0;not null"`
+	DiscountAmount  decimal.Decimal `json:"discount_amount" gorm:"type:numeric(15,2);default:0;not null"`
+	// Biaya reschedule dan selisih tarif yang tidak dikembalikan saat pindah
+	// ke tarif lebih murah, agar rincian tetap berjumlah total_price.
+	ChangeFee              decimal.Decimal `json:"change_fee" gorm:"type:numeric(15,2);default:0;not null"`
+	RetainedFareDifference decimal.Decimal `json:"retained_fare_difference" gorm:"type:numeric(15,2);default:0;not null"`
 	Status          string  `json:"status" gorm:"size:20;default:'pending';not null"`
 	Details 		[]BookingDetail `json:"details" gorm:"foreignKey:BookingID"`
 	ExpiredAt       *time.Time      `json:"expired_at"`
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	BookingChangeStatusPendingPayment = "pending_payment"
+	BookingChangeStatusCompleted      = "completed"
+	BookingChangeStatusExpired        = "expired"
+
+	// Kode perubahan dipakai juga sebagai order_id pembayaran tambahan,
+	// prefix ini membedakannya dari order booking biasa.
+	BookingChangeCodePrefix = "CHG-"
+)
+
+// BookingChange mencatat satu kali reschedule booking ke penerbangan lain.
+type BookingChange struct {
+	ID             uint                     `json:"id" gorm:"primaryKey;autoIncrement"`
+	ChangeCode     string                   `json:"change_code" gorm:"size:50;uniqueIndex;not null"`
+	OrderID        string                   `json:"order_id" gorm:"size:50;not null;index"`
+	BookingID      uint                     `json:"booking_id" gorm:"not null;index"`
+	UserID         uint                     `json:"user_id" gorm:"not null"`
+	OldFlightID    uint                     `json:"old_flight_id" gorm:"not null"`
+	OldFlight      Flight                   `json:"old_flight" gorm:"foreignKey:OldFlightID"`
+	NewFlightID    uint                     `json:"new_flight_id" gorm:"not null"`
+	NewFlight      Flight                   `json:"new_flight" gorm:"foreignKey:NewFlightID"`
+	OldSeatClass   string                   `json:"old_seat_class" gorm:"size:50;not null"`
+	NewSeatClass   string                   `json:"new_seat_class" gorm:"size:50;not null"`
+	OldFareTotal   decimal.Decimal          `json:"old_fare_total" gorm:"type:numeric(15,2);not null"`
+	NewFareTotal   decimal.Decimal          `json:"new_fare_total" gorm:"type:numeric(15,2);not null"`
+	FareDifference decimal.Decimal          `json:"fare_difference" gorm:"type:numeric(15,2);not null"`
+	ChangeFee      decimal.Decimal          `json:"change_fee" gorm:"type:numeric(15,2);not null"`
+	AmountDue      decimal.Decimal          `json:"amount_due" gorm:"type:numeric(15,2);not null"`
+	Status         string                   `json:"status" gorm:"size:20;default:'pending_payment';not null"`
+	Passengers     []BookingChangePassenger `json:"passengers" gorm:"foreignKey:BookingChangeID"`
+	ExpiredAt      *time.Time               `json:"expired_at"`
+	CompletedAt    *time.Time               `json:"completed_at"`
+	CreatedAt      time.Time                `json:"created_at"`
+	UpdatedAt      time.Time                `json:"updated_at"`
+}
+
+func (BookingChange) TableName() string {
+	return "booking_changes"
+}
+
+// BookingChangePassenger menyimpan harga baru dan nomor tiket hasil re-issue
+// untuk setiap penumpang, sehingga tiket lama tetap bisa ditelusuri.
+type BookingChangePassenger struct {
+	ID              uint            `json:"id" gorm:"primaryKey;autoIncrement"`
+	BookingChangeID uint            `json:"booking_change_id" gorm:"not null;index"`
+	BookingDetailID uint            `json:"booking_detail_id" gorm:"not null"`
+	OldTicketNumber string          `json:"old_ticket_number" gorm:"size:50;not null"`
+	NewTicketNumber string          `json:"new_ticket_number" gorm:"size:50;uniqueIndex;not null"`
+	BaseFare        decimal.Decimal `json:"base_fare" gorm:"type:numeric(15,2);not null"`
+	AirportTax      decimal.Decimal `json:"airport_tax" gorm:"type:numeric(15,2);not null"`
+	VATAmount       decimal.Decimal `json:"vat_amount" gorm:"column:vat_amount;type:numeric(15,2);not null"`
+	Price           decimal.Decimal `json:"price" gorm:"type:numeric(15,2);not null"`
+	CreatedAt       time.Time       `json:"created_at"`
+}
+
+func (BookingChangePassenger) TableName() string {
+	return "booking_change_passengers"
+}
+
+func IsBookingChangeCode(orderID string) bool {
+	return strings.HasPrefix(orderID, BookingChangeCodePrefix)
+}
@@ -10,6 +10,9 @@ const (
 	PassengerTypeAdult  = "Dewasa"
 	PassengerTypeChild  = "Anak-anak"
 	PassengerTypeInfant = "Bayi"
+
+	CheckInStatusNotCheckedIn = "not_checked_in"
+	CheckInStatusCheckedIn    = "checked_in"
 )
 
 type BookingDetail struct {
@@ -25,7 +28,15 @@ type BookingDetail struct {
 	ValidUntil     *time.Time `json:"valid_until" gorm:"type:date"`
 	TicketNumber string `json:"ticket_number" gorm:"size:50;uniqueIndex;not null"`
 	SeatClass string  `json:"seat_class" gorm:"size:50;not null"`
+	BaseFare       decimal.Decimal `json:"base_fare" gorm:"type:numeric(15,2);default:0;not null"`
+	AirportTax     decimal.Decimal `json:"airport_tax" gorm:"type:numeric(15,2);default:0;not null"`
+	VATAmount      decimal.Decimal `json:"vat_amount" gorm:"column:vat_amount;type:numeric(15,2);default:0;not null"`
 	Price          decimal.Decimal `json:"price" gorm:"type:numeric(15,2)"`
+	Seats          []FlightSeat    `json:"seats,omitempty" gorm:"foreignKey:BookingDetailID"`
+	CheckInStatus  string          `json:"check_in_status" gorm:"size:20;default:'not_checked_in';not null"`
+	CheckedInAt    *time.Time      `json:"checked_in_at"`
+	// Nomor urut check-in per leg, dipakai di barcode boarding pass
+	BoardingSequences []LegBoardingSequence `json:"boarding_sequences,omitempty" gorm:"foreignKey:BookingDetailID"`
 
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
@@ -33,4 +44,15 @@ type BookingDetail struct {
 
 func (BookingDetail) TableName() string {
 	return "booking_details"
-}
\ No newline at end of file
+}
+
+// BoardingSequenceOnLeg mengembalikan nomor urut check-in penumpang di leg,
+// atau 0 jika belum check-in.
+func (d BookingDetail) BoardingSequenceOnLeg(leg FlightLeg) int {
+	for _, sequence := range d.BoardingSequences {
+		if sequence.IsOnLeg(leg) {
+			return sequence.Sequence
+		}
+	}
+	return 0
+}
@@ -0,0 +1,40 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	FeeTypeAirportTax     = "airport_tax"
+	FeeTypeVAT            = "vat"
+	FeeTypeConvenienceFee = "convenience_fee"
+
+	FeeCalcPercentage = "percentage"
+	FeeCalcFixed      = "fixed"
+)
+
+// FeeRule adalah satu aturan biaya/pajak.
+//   - airport_tax: per penumpang per leg, AirportID = bandara keberangkatan leg
+//   - vat: per penumpang, persentase dihitung dari base fare
+//   - convenience_fee: per order, PaymentType = metode pembayaran
+//
+// Kolom filter yang kosong berarti berlaku untuk semua.
+type FeeRule struct {
+	ID            uint            `json:"id" gorm:"primaryKey;autoIncrement"`
+	Name          string          `json:"name" gorm:"size:100;not null"`
+	FeeType       string          `json:"fee_type" gorm:"size:30;not null;index"`
+	CalcType      string          `json:"calc_type" gorm:"size:20;not null"`
+	Value         decimal.Decimal `json:"value" gorm:"type:numeric(15,2);not null"`
+	AirportID     *uint           `json:"airport_id"`
+	PaymentType   string          `json:"payment_type" gorm:"size:30"`
+	PassengerType string          `json:"passenger_type" gorm:"size:20"`
+	IsActive      bool            `json:"is_active" gorm:"default:true;not null"`
+	CreatedAt     time.Time       `json:"created_at"`
+	UpdatedAt     time.Time       `json:"updated_at"`
+}
+
+func (FeeRule) TableName() string {
+	return "fee_rules"
+}
@@ -6,6 +6,11 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+const (
+	FareTypePercentage = "percentage"
+	FareTypeFixed      = "fixed"
+)
+
 type FlightClass struct {
 	ID          uint      `json:"id" gorm:"primaryKey;autoIncrement"`
 	FlightID    uint      `json:"flight_id"`
@@ -13,6 +18,11 @@ type FlightClass struct {
 	ClassCode  string          `json:"class_code"`
 	Price      decimal.Decimal `json:"price" gorm:"type:numeric(15,2);not null"`
 	TotalSeats  int       `json:"total_seats" gorm:"not null"`
+	// Tarif anak & bayi: persentase dari Price atau nominal tetap
+	ChildFareType   string          `json:"child_fare_type" gorm:"size:20;default:'percentage';not null"`
+	ChildFareValue  decimal.Decimal `json:"child_fare_value" gorm:"type:numeric(15,2);default:100;not null"`
+	InfantFareType  string          `json:"infant_fare_type" gorm:"size:20;default:'percentage';not null"`
+	InfantFareValue decimal.Decimal `json:"infant_fare_value" gorm:"type:numeric(15,2);default:10;not null"`
 	CreatedAt   time.Time `json:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at"`
 	DeletedAt   *time.Time `json:"deleted_at"`
@@ -14,7 +14,7 @@ type FlightLeg struct {
 	OriginAirport        *Airport `gorm:"foreignKey:OriginAirportID" json:"origin_airport,omitempty"`
 	DestinationAirportID uint      `gorm:"not null" json:"destination_airport_id"`
 	DestinationAirport   *Airport `gorm:"foreignKey:DestinationAirportID" json:"destination_airport,omitempty"`
-	FlightNumber          string    `json:"flight_number"`
+	FlightNumber          string    `json:"flight_number" gorm:"size:50;not null"`
 	Duration     		 int       `json:"duration"` // Dalam menit
 	TransitNotes          string    `json:"transit_notes"`
 	CreatedAt             time.Time `json:"created_at"`
@@ -0,0 +1,64 @@
+package models
+
+import "time"
+
+const (
+	SeatStatusAvailable = "available"
+	SeatStatusOccupied  = "occupied"
+	SeatStatusBlocked   = "blocked"
+)
+
+// FlightSeatOnLeg memilih kursi milik leg fisik dari baris flight_legs dengan
+// id tertentu.
+const FlightSeatOnLeg = "(flight_seats.flight_number, flight_seats.origin_airport_id, flight_seats.departure_time) = " +
+	"(SELECT flight_number, origin_airport_id, departure_time FROM flight_legs WHERE id = ?)"
+
+// FlightSeat adalah satu kursi fisik pada sebuah leg. Kursi dianggap terisi
+// selama BookingDetailID terisi, baik booking masih pending (hold) maupun paid.
+// Kursi dikunci ke leg fisik, sama seperti leg_inventories, sehingga flight
+// yang berbagi leg memakai satu denah dan tidak bisa menjual kursi yang sama.
+type FlightSeat struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	FlightNumber    string    `json:"flight_number" gorm:"size:50;not null"`
+	OriginAirportID uint      `json:"origin_airport_id" gorm:"not null"`
+	DepartureTime   time.Time `json:"departure_time" gorm:"not null"`
+	SeatNumber      string    `json:"seat_number" gorm:"size:5;not null"`
+	RowNumber       int       `json:"row_number" gorm:"not null"`
+	ColumnLetter    string    `json:"column_letter" gorm:"size:2;not null"`
+	SeatClass       string    `json:"seat_class" gorm:"size:50;not null"`
+	IsExitRow       bool      `json:"is_exit_row" gorm:"default:false"`
+	IsWindow        bool      `json:"is_window" gorm:"default:false"`
+	IsAisle         bool      `json:"is_aisle" gorm:"default:false"`
+	IsBlocked       bool      `json:"is_blocked" gorm:"default:false"`
+	BookingDetailID *uint     `json:"booking_detail_id" gorm:"index"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (FlightSeat) TableName() string {
+	return "flight_seats"
+}
+
+func (s FlightSeat) Status() string {
+	if s.IsBlocked {
+		return SeatStatusBlocked
+	}
+	if s.BookingDetailID != nil {
+		return SeatStatusOccupied
+	}
+	return SeatStatusAvailable
+}
+
+// IsOnLeg bernilai true jika kursi berada di leg fisik yang sama dengan leg.
+func (s FlightSeat) IsOnLeg(leg FlightLeg) bool {
+	return s.FlightNumber == leg.FlightNumber &&
+		s.OriginAirportID == leg.OriginAirportID &&
+		s.DepartureTime.Equal(leg.DepartureTime)
+}
+
+// PlaceOnLeg menempelkan kursi ke leg fisik milik leg.
+func (s *FlightSeat) PlaceOnLeg(leg FlightLeg) {
+	s.FlightNumber = leg.FlightNumber
+	s.OriginAirportID = leg.OriginAirportID
+	s.DepartureTime = leg.DepartureTime
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// LegBoardingSequence adalah nomor urut check-in penumpang di satu leg fisik.
+// Nomor dihitung per leg fisik agar penumpang dari flight yang berbagi leg
+// tidak mendapat nomor yang sama.
+type LegBoardingSequence struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	BookingDetailID uint      `json:"booking_detail_id" gorm:"not null"`
+	FlightNumber    string    `json:"flight_number" gorm:"size:50;not null"`
+	OriginAirportID uint      `json:"origin_airport_id" gorm:"not null"`
+	DepartureTime   time.Time `json:"departure_time" gorm:"not null"`
+	Sequence        int       `json:"sequence" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+func (LegBoardingSequence) TableName() string {
+	return "leg_boarding_sequences"
+}
+
+// IsOnLeg bernilai true jika nomor urut milik leg fisik yang sama dengan leg.
+func (s LegBoardingSequence) IsOnLeg(leg FlightLeg) bool {
+	return s.FlightNumber == leg.FlightNumber &&
+		s.OriginAirportID == leg.OriginAirportID &&
+		s.DepartureTime.Equal(leg.DepartureTime)
+}
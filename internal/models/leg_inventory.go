@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// LegInventoryJoin menghubungkan flight_legs (alias fl) dengan leg_inventories
+// (alias li). Satu leg fisik bisa dipakai beberapa flight/itinerary, jadi stok
+// dicari berdasarkan nomor penerbangan, bandara asal dan jam berangkat.
+const LegInventoryJoin = "li.flight_number = fl.flight_number AND li.origin_airport_id = fl.origin_airport_id AND li.departure_time = fl.departure_time"
+
+type LegInventory struct {
+	ID              uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	FlightNumber    string    `json:"flight_number" gorm:"size:50;not null"`
+	OriginAirportID uint      `json:"origin_airport_id" gorm:"not null"`
+	DepartureTime   time.Time `json:"departure_time" gorm:"not null"`
+	SeatClass       string    `json:"seat_class" gorm:"size:50;not null"`
+	AvailableSeats  int       `json:"available_seats" gorm:"not null"`
+	CreatedAt       time.Time `json:"created_at"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+func (LegInventory) TableName() string {
+	return "leg_inventories"
+}
@@ -0,0 +1,19 @@
+package models
+
+import "time"
+
+// PasswordReset menyimpan token lupa password. Yang disimpan hanya hash
+// SHA-256 dari token; token aslinya hanya dikirim lewat email.
+type PasswordReset struct {
+	ID        uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID    uint       `json:"user_id" gorm:"not null;index"`
+	User      User       `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	TokenHash string     `json:"-" gorm:"size:64;uniqueIndex;not null"`
+	ExpiredAt time.Time  `json:"expired_at" gorm:"not null"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (PasswordReset) TableName() string {
+	return "password_resets"
+}
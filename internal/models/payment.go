@@ -12,6 +12,8 @@ const (
 	PaymentStatusExpire     = "expire"
 	PaymentStatusDeny       = "deny"
 	PaymentStatusCancel     = "cancel"
+	PaymentStatusRefund        = "refund"
+	PaymentStatusPartialRefund = "partial_refund"
 )
 
 type Payment struct {
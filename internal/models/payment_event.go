@@ -0,0 +1,30 @@
+package models
+
+import "time"
+
+const (
+	PaymentEventStatusProcessed = "processed"
+	PaymentEventStatusIgnored   = "ignored"
+	PaymentEventStatusFailed    = "failed"
+)
+
+type PaymentEvent struct {
+	ID                uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	DedupKey          string     `json:"dedup_key" gorm:"size:255;uniqueIndex;not null"`
+	OrderID           string     `json:"order_id" gorm:"size:50;index"`
+	TransactionID     string     `json:"transaction_id" gorm:"size:100;index"`
+	TransactionStatus string     `json:"transaction_status" gorm:"size:50"`
+	StatusCode        string     `json:"status_code" gorm:"size:10"`
+	TransactionTime   string     `json:"transaction_time" gorm:"size:50"`
+	RawPayload        string     `json:"raw_payload" gorm:"type:jsonb;not null"`
+	ProcessingStatus  string     `json:"processing_status" gorm:"size:20;not null"`
+	ProcessingNote    string     `json:"processing_note" gorm:"type:text"`
+	ReplayCount       int        `json:"replay_count" gorm:"default:0"`
+	ProcessedAt       *time.Time `json:"processed_at"`
+	CreatedAt         time.Time  `json:"created_at"`
+	UpdatedAt         time.Time  `json:"updated_at"`
+}
+
+func (PaymentEvent) TableName() string {
+	return "payment_events"
+}
@@ -0,0 +1,60 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	PromoDiscountPercentage = "percentage"
+	PromoDiscountFixed      = "fixed"
+
+	PromoRedemptionStatusActive   = "active"
+	PromoRedemptionStatusReleased = "released"
+)
+
+type Promotion struct {
+	ID            uint             `json:"id" gorm:"primaryKey;autoIncrement"`
+	Code          string           `json:"code" gorm:"size:30;uniqueIndex;not null"`
+	Description   string           `json:"description" gorm:"type:text"`
+	DiscountType  string           `json:"discount_type" gorm:"size:20;not null"`
+	DiscountValue decimal.Decimal  `json:"discount_value" gorm:"type:numeric(15,2);not null"`
+	MaxDiscount   *decimal.Decimal `json:"max_discount" gorm:"type:numeric(15,2)"`
+	MinSpend      decimal.Decimal  `json:"min_spend" gorm:"type:numeric(15,2);default:0;not null"`
+
+	// Batasan opsional, nil/kosong berarti berlaku untuk semua
+	OriginAirportID      *uint  `json:"origin_airport_id"`
+	DestinationAirportID *uint  `json:"destination_airport_id"`
+	AirlineID            *uint  `json:"airline_id"`
+	SeatClass            string `json:"seat_class" gorm:"size:50"`
+
+	ValidFrom    time.Time `json:"valid_from" gorm:"not null"`
+	ValidUntil   time.Time `json:"valid_until" gorm:"not null"`
+	UsageLimit   int       `json:"usage_limit" gorm:"default:0;not null"`    // 0 = tanpa batas
+	PerUserLimit int       `json:"per_user_limit" gorm:"default:0;not null"` // 0 = tanpa batas
+	UsedCount    int       `json:"used_count" gorm:"default:0;not null"`
+	IsActive     bool      `json:"is_active" gorm:"default:true;not null"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+func (Promotion) TableName() string {
+	return "promotions"
+}
+
+type PromoRedemption struct {
+	ID             uint            `json:"id" gorm:"primaryKey;autoIncrement"`
+	PromotionID    uint            `json:"promotion_id" gorm:"not null;index"`
+	UserID         uint            `json:"user_id" gorm:"not null;index"`
+	OrderID        string          `json:"order_id" gorm:"size:50;uniqueIndex;not null"`
+	DiscountAmount decimal.Decimal `json:"discount_amount" gorm:"type:numeric(15,2);not null"`
+	Status         string          `json:"status" gorm:"size:20;default:'active';not null"`
+	ReleasedAt     *time.Time      `json:"released_at"`
+	CreatedAt      time.Time       `json:"created_at"`
+	UpdatedAt      time.Time       `json:"updated_at"`
+}
+
+func (PromoRedemption) TableName() string {
+	return "promo_redemptions"
+}
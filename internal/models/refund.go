@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	RefundStatusPending = "pending"
+	RefundStatusSuccess = "success"
+	RefundStatusFailed  = "failed"
+)
+
+type Refund struct {
+	ID              uint            `json:"id" gorm:"primaryKey;autoIncrement"`
+	OrderID         string          `json:"order_id" gorm:"size:50;not null;index"`
+	UserID          uint            `json:"user_id" gorm:"not null"`
+	RefundKey       string          `json:"refund_key" gorm:"size:100;uniqueIndex;not null"`
+	PaidAmount      decimal.Decimal `json:"paid_amount" gorm:"type:numeric(15,2);not null"`
+	CancellationFee decimal.Decimal `json:"cancellation_fee" gorm:"type:numeric(15,2);not null"`
+	RefundAmount    decimal.Decimal `json:"refund_amount" gorm:"type:numeric(15,2);not null"`
+	Reason          string          `json:"reason" gorm:"type:text"`
+	Status          string          `json:"status" gorm:"size:20;default:'pending';not null"`
+	FailureReason   string          `json:"failure_reason" gorm:"type:text"`
+	ProcessedAt     *time.Time      `json:"processed_at"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+}
+
+func (Refund) TableName() string {
+	return "refunds"
+}
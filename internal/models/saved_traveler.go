@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+// SavedTraveler adalah data penumpang yang disimpan user agar tidak perlu
+// diketik ulang setiap kali memesan.
+type SavedTraveler struct {
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID         uint       `json:"user_id" gorm:"not null;index"`
+	User           User       `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	Title          string     `json:"title" gorm:"size:10;not null"`
+	FullName       string     `json:"full_name" gorm:"size:255;not null"`
+	DOB            time.Time  `json:"dob" gorm:"column:dob;type:date;not null"`
+	Nationality    string     `json:"nationality" gorm:"size:50;not null"`
+	PassportNumber *string    `json:"passport_number" gorm:"size:50"`
+	IssuingCountry *string    `json:"issuing_country" gorm:"size:50"`
+	ValidUntil     *time.Time `json:"valid_until" gorm:"type:date"`
+	CreatedAt      time.Time  `json:"created_at"`
+	UpdatedAt      time.Time  `json:"updated_at"`
+}
+
+func (SavedTraveler) TableName() string {
+	return "saved_travelers"
+}
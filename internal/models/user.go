@@ -18,6 +18,11 @@ type User struct {
 	Password  string    `json:"-" gorm:"size:255;not null"`
 	Role      UserRole  `json:"role" gorm:"type:enum('customer','admin');default:'customer'"`
 	IsVerified bool       `json:"is_verified" gorm:"default:false"`
+	// PasswordChangedAt dipakai untuk menolak refresh token yang terbit sebelum password diganti
+	PasswordChangedAt *time.Time `json:"-"`
+	// Penguncian login bertahap setelah password salah berulang kali
+	FailedLoginAttempts int        `json:"-" gorm:"not null;default:0"`
+	LoginLockedUntil    *time.Time `json:"-"`
 	CreatedAt time.Time  `json:"created_at"`
 	UpdatedAt time.Time  `json:"updated_at"`
 	DeletedAt *time.Time `json:"-" gorm:"index"`
@@ -2,15 +2,32 @@ package models
 
 import "time"
 
+// UserOTP menyimpan hash kode OTP verifikasi akun, bukan kodenya.
+// LockedUntil terisi setelah terlalu banyak percobaan salah.
 type UserOTP struct {
-	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
-	UserID    uint      `json:"user_id" gorm:"not null"`
-	User      User      `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
-	OTPCode   string    `json:"otp_code" gorm:"size:6;not null"`
-	ExpiredAt time.Time `json:"expired_at" gorm:"not null"`
-	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+	ID             uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID         uint       `json:"user_id" gorm:"not null"`
+	User           User       `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	OTPHash        string     `json:"-" gorm:"column:otp_hash;size:64;not null"`
+	FailedAttempts int        `json:"failed_attempts" gorm:"not null;default:0"`
+	LockedUntil    *time.Time `json:"locked_until"`
+	ExpiredAt      time.Time  `json:"expired_at" gorm:"not null"`
+	CreatedAt      time.Time  `json:"created_at" gorm:"autoCreateTime"`
 }
 
 func (UserOTP) TableName() string {
 	return "user_otps"
-}
\ No newline at end of file
+}
+
+// OTPSendLog mencatat setiap pengiriman OTP untuk cooldown dan batas harian
+// per email maupun per IP.
+type OTPSendLog struct {
+	ID        uint      `json:"id" gorm:"primaryKey;autoIncrement"`
+	Email     string    `json:"email" gorm:"size:255;not null"`
+	IPAddress string    `json:"ip_address" gorm:"size:45;not null"`
+	CreatedAt time.Time `json:"created_at" gorm:"autoCreateTime"`
+}
+
+func (OTPSendLog) TableName() string {
+	return "otp_send_logs"
+}
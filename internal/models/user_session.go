@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// Alasan sebuah sesi dicabut
+const (
+	SessionRevokedLogout          = "logout"
+	SessionRevokedLogoutAll       = "logout_all"
+	SessionRevokedByUser          = "revoked_by_user"
+	SessionRevokedPasswordChanged = "password_changed"
+	SessionRevokedTokenReuse      = "refresh_token_reuse"
+)
+
+// UserSession adalah satu login di satu perangkat. Hanya hash refresh token
+// terakhir yang disimpan; token lama dari sesi yang sama dianggap dicuri
+// jika dipakai lagi.
+type UserSession struct {
+	ID               uint       `json:"id" gorm:"primaryKey;autoIncrement"`
+	UserID           uint       `json:"user_id" gorm:"not null;index"`
+	User             User       `json:"-" gorm:"foreignKey:UserID;constraint:OnUpdate:CASCADE,OnDelete:CASCADE;"`
+	RefreshTokenHash string     `json:"-" gorm:"size:64;not null"`
+	Device           string     `json:"device" gorm:"size:255;not null"`
+	IPAddress        string     `json:"ip_address" gorm:"size:45;not null"`
+	UserAgent        string     `json:"user_agent" gorm:"type:text;not null"`
+	LastUsedAt       time.Time  `json:"last_used_at" gorm:"not null"`
+	ExpiresAt        time.Time  `json:"expires_at" gorm:"not null"`
+	RevokedAt        *time.Time `json:"revoked_at"`
+	RevokedReason    *string    `json:"revoked_reason" gorm:"size:50"`
+	CreatedAt        time.Time  `json:"created_at"`
+	UpdatedAt        time.Time  `json:"updated_at"`
+}
+
+func (UserSession) TableName() string {
+	return "user_sessions"
+}
+
+func (s *UserSession) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -1,7 +1,6 @@
 package admin
 
 import (
-	"ezytix-be/pkg/jwt"
 	"github.com/gofiber/fiber/v2"
 )
 
@@ -13,26 +12,16 @@ func NewAdminHandler(service AdminService) *AdminHandler {
 	return &AdminHandler{service}
 }
 
+// GetDashboardStats hanya bisa diakses admin; pengecekan role dilakukan oleh
+// middleware.RequireRole di routes.go.
 func (h *AdminHandler) GetDashboardStats(c *fiber.Ctx) error {
-	claims := c.Locals("user").(*jwt.JWTClaims)
-	if claims.Role != "admin" {
-		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Akses ditolak, hanya untuk admin",
-		})
-	}
-
 	stats, err := h.service.GetDashboardStats()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Gagal mengambil data statistik",
-			"error":   err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"status": "success",
 		"data":   stats,
 	})
-}
\ No newline at end of file
+}
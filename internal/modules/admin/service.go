@@ -1,5 +1,7 @@
 package admin
 
+import "ezytix-be/pkg/apperror"
+
 type AdminService interface {
 	GetDashboardStats() (*DashboardStatsResponse, error)
 }
@@ -15,17 +17,17 @@ func NewAdminService(repo AdminRepository) AdminService {
 func (s *adminService) GetDashboardStats() (*DashboardStatsResponse, error) {
 	customers, err := s.repo.CountCustomers()
 	if err != nil {
-		return nil, err
+		return nil, apperror.Internal(err)
 	}
 
 	bookings, err := s.repo.CountBookingsToday()
 	if err != nil {
-		return nil, err
+		return nil, apperror.Internal(err)
 	}
 
 	revenue, err := s.repo.SumRevenueToday()
 	if err != nil {
-		return nil, err
+		return nil, apperror.Internal(err)
 	}
 
 	return &DashboardStatsResponse{
@@ -3,9 +3,14 @@ package airline
 import (
 	"strconv"
 
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/validation"
+
 	"github.com/gofiber/fiber/v2"
 )
 
+var ErrInvalidAirlineID = apperror.BadRequest("INVALID_AIRLINE_ID", "invalid airline ID")
+
 type AirlineHandler struct {
 	service AirlineService
 }
@@ -17,17 +22,13 @@ func NewAirlineHandler(service AirlineService) *AirlineHandler {
 func (h *AirlineHandler) CreateAirline(c *fiber.Ctx) error {
 	var req CreateAirlineRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	airline, err := h.service.CreateAirline(req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -39,9 +40,7 @@ func (h *AirlineHandler) CreateAirline(c *fiber.Ctx) error {
 func (h *AirlineHandler) GetAllAirlines(c *fiber.Ctx) error {
 	airlines, err := h.service.GetAllAirlines()
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": "failed to fetch airlines",
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -50,19 +49,14 @@ func (h *AirlineHandler) GetAllAirlines(c *fiber.Ctx) error {
 }
 
 func (h *AirlineHandler) GetAirlineByID(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid airline ID",
-		})
+		return ErrInvalidAirlineID
 	}
 
 	airline, err := h.service.GetAirlineByID(uint(id))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "airline not found",
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -71,26 +65,19 @@ func (h *AirlineHandler) GetAirlineByID(c *fiber.Ctx) error {
 }
 
 func (h *AirlineHandler) UpdateAirline(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid airline ID",
-		})
+		return ErrInvalidAirlineID
 	}
 
 	var req UpdateAirlineRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	airline, err := h.service.UpdateAirline(uint(id), req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -100,21 +87,16 @@ func (h *AirlineHandler) UpdateAirline(c *fiber.Ctx) error {
 }
 
 func (h *AirlineHandler) DeleteAirline(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
+	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid airline ID",
-		})
+		return ErrInvalidAirlineID
 	}
 
 	if err := h.service.DeleteAirline(uint(id)); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"message": "airline deleted successfully",
 	})
-}
\ No newline at end of file
+}
@@ -1,9 +1,15 @@
 package airline
 
 import (
-	"errors"
-	"strings" 
+	"strings"
+
 	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
+)
+
+var (
+	ErrAirlineNotFound   = apperror.NotFound("AIRLINE_NOT_FOUND", "airline not found")
+	ErrAirlineIATAExists = apperror.Conflict("AIRLINE_IATA_EXISTS", "airline with this IATA code already exists")
 )
 
 type AirlineService interface {
@@ -33,7 +39,7 @@ func (s *airlineService) CreateAirline(req CreateAirlineRequest) (*models.Airlin
 
 	if err := s.repo.CreateAirline(airline); err != nil {
 		if strings.Contains(err.Error(), "duplicate key") {
-			return nil, errors.New("airline with this IATA code already exists")
+			return nil, ErrAirlineIATAExists
 		}
 		return nil, err
 	}
@@ -48,7 +54,7 @@ func (s *airlineService) GetAllAirlines() ([]models.Airline, error) {
 func (s *airlineService) GetAirlineByID(id uint) (*models.Airline, error) {
 	airline, err := s.repo.GetAirlineByID(id)
 	if err != nil {
-		return nil, errors.New("airline not found")
+		return nil, ErrAirlineNotFound
 	}
 	return airline, nil
 }
@@ -56,7 +62,7 @@ func (s *airlineService) GetAirlineByID(id uint) (*models.Airline, error) {
 func (s *airlineService) UpdateAirline(id uint, req UpdateAirlineRequest) (*models.Airline, error) {
 	existingAirline, err := s.repo.GetAirlineByID(id)
 	if err != nil {
-		return nil, errors.New("airline not found")
+		return nil, ErrAirlineNotFound
 	}
 
 	if req.Name != "" {
@@ -79,7 +85,7 @@ func (s *airlineService) UpdateAirline(id uint, req UpdateAirlineRequest) (*mode
 func (s *airlineService) DeleteAirline(id uint) error {
 	_, err := s.repo.GetAirlineByID(id)
 	if err != nil {
-		return errors.New("airline not found")
+		return ErrAirlineNotFound
 	}
 
 	return s.repo.DeleteAirline(id)
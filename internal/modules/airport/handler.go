@@ -3,9 +3,14 @@ package airport
 import (
 	"strconv"
 
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/validation"
+
 	"github.com/gofiber/fiber/v2"
 )
 
+var ErrInvalidAirportID = apperror.BadRequest("INVALID_AIRPORT_ID", "invalid airport ID")
+
 type AirportHandler struct {
 	service AirportService
 }
@@ -19,37 +24,35 @@ func NewAirportHandler(service AirportService) *AirportHandler {
 func (h *AirportHandler) CreateAirport(c *fiber.Ctx) error {
 	var req CreateAirportRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	airport, err := h.service.CreateAirport(req)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.Status(201).JSON(fiber.Map{
 		"message": "airport created",
-		"airport":    airport,
+		"airport": airport,
 	})
 }
 
 func (h *AirportHandler) UpdateAirport(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
-
+	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid airport id"})
+		return ErrInvalidAirportID
 	}
 
 	var req UpdateAirportRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request body"})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	airport, err := h.service.UpdateAirport(uint(id), req)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -59,15 +62,13 @@ func (h *AirportHandler) UpdateAirport(c *fiber.Ctx) error {
 }
 
 func (h *AirportHandler) DeleteAirport(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
-
+	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid airport id"})
+		return ErrInvalidAirportID
 	}
 
 	if err := h.service.DeleteAirport(uint(id)); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -78,7 +79,7 @@ func (h *AirportHandler) DeleteAirport(c *fiber.Ctx) error {
 func (h *AirportHandler) GetAllAirports(c *fiber.Ctx) error {
 	airports, err := h.service.GetAllAirports()
 	if err != nil {
-		return c.Status(500).JSON(fiber.Map{"error": "failed to fetch airports"})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -87,16 +88,14 @@ func (h *AirportHandler) GetAllAirports(c *fiber.Ctx) error {
 }
 
 func (h *AirportHandler) GetAirportByID(c *fiber.Ctx) error {
-	idStr := c.Params("id")
-	id, err := strconv.Atoi(idStr)
-
+	id, err := strconv.Atoi(c.Params("id"))
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid airport id"})
+		return ErrInvalidAirportID
 	}
 
 	airport, err := h.service.GetAirportByID(uint(id))
 	if err != nil {
-		return c.Status(404).JSON(fiber.Map{"error": "airport not found"})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
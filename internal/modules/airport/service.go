@@ -1,10 +1,16 @@
 package airport
 
 import (
-	"errors"
 	"strings"
 
 	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
+)
+
+var (
+	ErrAirportNotFound    = apperror.NotFound("AIRPORT_NOT_FOUND", "airport not found")
+	ErrInvalidAirportCode = apperror.Validation("INVALID_AIRPORT_CODE", "airport code must be a 3 letter IATA code")
+	ErrAirportCodeExists  = apperror.Conflict("AIRPORT_CODE_EXISTS", "airport code is already used by another airport")
 )
 
 type AirportService interface {
@@ -28,12 +34,12 @@ func NewAirportService(repo AirportRepository) AirportService {
 func (s *airportService) CreateAirport(req CreateAirportRequest) (*models.Airport, error) {
 	code := strings.ToUpper(strings.TrimSpace(req.Code))
 	if len(code) != 3 {
-		return nil, errors.New("airport code harus 3 huruf (IATA)")
+		return nil, ErrInvalidAirportCode
 	}
 
 	existing, _ := s.repo.FindAirportByCode(code)
 	if existing != nil {
-		return nil, errors.New("airport code sudah digunakan")
+		return nil, ErrAirportCodeExists
 	}
 
 	airport := &models.Airport{
@@ -53,18 +59,18 @@ func (s *airportService) CreateAirport(req CreateAirportRequest) (*models.Airpor
 func (s *airportService) UpdateAirport(id uint, req UpdateAirportRequest) (*models.Airport, error) {
 	airport, err := s.repo.FindAirportByID(id)
 	if err != nil {
-		return nil, errors.New("airport tidak ditemukan")
+		return nil, ErrAirportNotFound
 	}
 
 	if req.Code != nil {
 		code := strings.ToUpper(strings.TrimSpace(*req.Code))
 		if len(code) != 3 {
-			return nil, errors.New("airport code harus 3 huruf (IATA)")
+			return nil, ErrInvalidAirportCode
 		}
 
 		existing, _ := s.repo.FindAirportByCode(code)
 		if existing != nil && existing.ID != id {
-			return nil, errors.New("airport code sudah dipakai airport lain")
+			return nil, ErrAirportCodeExists
 		}
 
 		airport.Code = code
@@ -101,7 +107,7 @@ func (s *airportService) UpdateAirport(id uint, req UpdateAirportRequest) (*mode
 func (s *airportService) DeleteAirport(id uint) error {
 	_, err := s.repo.FindAirportByID(id)
 	if err != nil {
-		return errors.New("airport tidak ditemukan")
+		return ErrAirportNotFound
 	}
 
 	return s.repo.DeleteAirport(id)
@@ -110,7 +116,7 @@ func (s *airportService) DeleteAirport(id uint) error {
 func (s *airportService) GetAirportByID(id uint) (*models.Airport, error) {
 	airport, err := s.repo.FindAirportByID(id)
 	if err != nil {
-		return nil, errors.New("airport tidak ditemukan")
+		return nil, ErrAirportNotFound
 	}
 	return airport, nil
 }
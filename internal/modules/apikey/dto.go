@@ -0,0 +1,62 @@
+package apikey
+
+import (
+	"time"
+
+	"ezytix-be/internal/models"
+)
+
+type CreateAPIKeyRequest struct {
+	UserID             uint       `json:"user_id" validate:"required"`
+	Name               string     `json:"name" validate:"required,max=100"`
+	Scopes             []string   `json:"scopes" validate:"required,min=1,dive,oneof=flights:read bookings:read bookings:write payments:write"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute" validate:"omitempty,min=1,max=6000"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+type UpdateAPIKeyRequest struct {
+	Name               string     `json:"name" validate:"required,max=100"`
+	Scopes             []string   `json:"scopes" validate:"required,min=1,dive,oneof=flights:read bookings:read bookings:write payments:write"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute" validate:"omitempty,min=1,max=6000"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+}
+
+type APIKeyResponse struct {
+	ID                 uint       `json:"id"`
+	UserID             uint       `json:"user_id"`
+	Name               string     `json:"name"`
+	KeyPrefix          string     `json:"key_prefix"`
+	Scopes             []string   `json:"scopes"`
+	RateLimitPerMinute int        `json:"rate_limit_per_minute"`
+	LastUsedAt         *time.Time `json:"last_used_at"`
+	LastUsedIP         *string    `json:"last_used_ip"`
+	ExpiresAt          *time.Time `json:"expires_at"`
+	RevokedAt          *time.Time `json:"revoked_at"`
+	Active             bool       `json:"active"`
+	CreatedBy          uint       `json:"created_by"`
+	CreatedAt          time.Time  `json:"created_at"`
+}
+
+// CreatedAPIKeyResponse satu-satunya response yang memuat key mentah
+type CreatedAPIKeyResponse struct {
+	APIKeyResponse
+	Key string `json:"key"`
+}
+
+func toAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:                 key.ID,
+		UserID:             key.UserID,
+		Name:               key.Name,
+		KeyPrefix:          key.KeyPrefix,
+		Scopes:             key.ScopeList(),
+		RateLimitPerMinute: key.RateLimitPerMinute,
+		LastUsedAt:         key.LastUsedAt,
+		LastUsedIP:         key.LastUsedIP,
+		ExpiresAt:          key.ExpiresAt,
+		RevokedAt:          key.RevokedAt,
+		Active:             key.IsActive(time.Now()),
+		CreatedBy:          key.CreatedBy,
+		CreatedAt:          key.CreatedAt,
+	}
+}
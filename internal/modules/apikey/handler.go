@@ -0,0 +1,128 @@
+package apikey
+
+import (
+	"strconv"
+
+	"ezytix-be/internal/middleware"
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/jwt"
+	"ezytix-be/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var (
+	ErrInvalidAPIKeyID = apperror.BadRequest("INVALID_API_KEY_ID", "invalid API key ID")
+	ErrInvalidUserID   = apperror.BadRequest("INVALID_USER_ID", "user_id must be a positive number")
+)
+
+type APIKeyHandler struct {
+	service APIKeyService
+}
+
+func NewAPIKeyHandler(service APIKeyService) *APIKeyHandler {
+	return &APIKeyHandler{service}
+}
+
+func (h *APIKeyHandler) CreateAPIKey(c *fiber.Ctx) error {
+	claims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || claims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	var req CreateAPIKeyRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	key, err := h.service.Create(claims.UserID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "API key created, store it now because it will not be shown again",
+		"data":    key,
+	})
+}
+
+func (h *APIKeyHandler) GetAPIKeys(c *fiber.Ctx) error {
+	var userID uint
+	if raw := c.Query("user_id"); raw != "" {
+		id, err := strconv.ParseUint(raw, 10, 32)
+		if err != nil || id == 0 {
+			return ErrInvalidUserID
+		}
+		userID = uint(id)
+	}
+
+	keys, err := h.service.List(userID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": keys,
+	})
+}
+
+func (h *APIKeyHandler) GetAPIKey(c *fiber.Ctx) error {
+	id, err := apiKeyID(c)
+	if err != nil {
+		return err
+	}
+
+	key, err := h.service.Get(id)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": key,
+	})
+}
+
+func (h *APIKeyHandler) UpdateAPIKey(c *fiber.Ctx) error {
+	id, err := apiKeyID(c)
+	if err != nil {
+		return err
+	}
+
+	var req UpdateAPIKeyRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	key, err := h.service.Update(id, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "API key updated successfully",
+		"data":    key,
+	})
+}
+
+func (h *APIKeyHandler) RevokeAPIKey(c *fiber.Ctx) error {
+	id, err := apiKeyID(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.service.Revoke(id); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "API key revoked successfully",
+	})
+}
+
+func apiKeyID(c *fiber.Ctx) (uint, error) {
+	id, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil || id == 0 {
+		return 0, ErrInvalidAPIKeyID
+	}
+	return uint(id), nil
+}
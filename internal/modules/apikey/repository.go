@@ -0,0 +1,87 @@
+package apikey
+
+import (
+	"time"
+
+	"ezytix-be/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type APIKeyRepository interface {
+	Create(key *models.APIKey) error
+	FindByID(id uint) (*models.APIKey, error)
+	FindByHash(keyHash string) (*models.APIKey, error)
+	List(userID uint) ([]models.APIKey, error)
+	Update(key *models.APIKey) error
+	Revoke(id uint, now time.Time) (bool, error)
+	TouchLastUsed(id uint, ip string, now time.Time) error
+	FindUserByID(id uint) (*models.User, error)
+}
+
+type apiKeyRepository struct {
+	db *gorm.DB
+}
+
+func NewAPIKeyRepository(db *gorm.DB) APIKeyRepository {
+	return &apiKeyRepository{db}
+}
+
+func (r *apiKeyRepository) Create(key *models.APIKey) error {
+	return r.db.Create(key).Error
+}
+
+func (r *apiKeyRepository) FindByID(id uint) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.First(&key, id).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// FindByHash ikut memuat pemilik key karena role dan status akunnya dicek
+// di setiap request.
+func (r *apiKeyRepository) FindByHash(keyHash string) (*models.APIKey, error) {
+	var key models.APIKey
+	if err := r.db.Preload("User").Where("key_hash = ?", keyHash).First(&key).Error; err != nil {
+		return nil, err
+	}
+	return &key, nil
+}
+
+// List mengembalikan semua key, atau hanya milik satu user jika userID diisi.
+func (r *apiKeyRepository) List(userID uint) ([]models.APIKey, error) {
+	var keys []models.APIKey
+	query := r.db.Order("created_at DESC")
+	if userID != 0 {
+		query = query.Where("user_id = ?", userID)
+	}
+	err := query.Find(&keys).Error
+	return keys, err
+}
+
+func (r *apiKeyRepository) Update(key *models.APIKey) error {
+	return r.db.Model(key).Select("name", "scopes", "rate_limit_per_minute", "expires_at").Updates(key).Error
+}
+
+// Revoke bersyarat pada revoked_at IS NULL; false berarti key sudah dicabut.
+func (r *apiKeyRepository) Revoke(id uint, now time.Time) (bool, error) {
+	result := r.db.Model(&models.APIKey{}).
+		Where("id = ? AND revoked_at IS NULL", id).
+		Updates(map[string]interface{}{"revoked_at": now, "updated_at": now})
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *apiKeyRepository) TouchLastUsed(id uint, ip string, now time.Time) error {
+	return r.db.Model(&models.APIKey{}).
+		Where("id = ?", id).
+		UpdateColumns(map[string]interface{}{"last_used_at": now, "last_used_ip": ip}).Error
+}
+
+func (r *apiKeyRepository) FindUserByID(id uint) (*models.User, error) {
+	var user models.User
+	if err := r.db.Where("deleted_at IS NULL").First(&user, id).Error; err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
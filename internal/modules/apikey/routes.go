@@ -0,0 +1,26 @@
+package apikey
+
+import (
+	"ezytix-be/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func APIKeyRegisterRoutes(app *fiber.App, db *gorm.DB) {
+	repo := NewAPIKeyRepository(db)
+	service := NewAPIKeyService(repo)
+	handler := NewAPIKeyHandler(service)
+
+	// RequireAuth dan OptionalAPIKey di modul lain memakai service ini
+	middleware.SetAPIKeyAuthenticator(service)
+
+	admin := app.Group("/api/v1/admin/api-keys")
+	admin.Use(middleware.JWTMiddleware)
+	admin.Use(middleware.RequireRole("admin"))
+	admin.Get("/", handler.GetAPIKeys)
+	admin.Post("/", handler.CreateAPIKey)
+	admin.Get("/:id", handler.GetAPIKey)
+	admin.Put("/:id", handler.UpdateAPIKey)
+	admin.Delete("/:id", handler.RevokeAPIKey)
+}
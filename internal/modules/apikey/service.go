@@ -0,0 +1,252 @@
+package apikey
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"ezytix-be/internal/middleware"
+	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultRateLimitPerMinute = 60
+	// last_used_at cukup diperbarui sekali per menit agar tidak setiap
+	// request partner menjadi UPDATE ke database
+	lastUsedResolution = time.Minute
+	// Panjang awal key yang disimpan apa adanya untuk dikenali admin
+	keyPrefixLength = 12
+)
+
+var (
+	ErrAPIKeyNotFound      = apperror.NotFound("API_KEY_NOT_FOUND", "API key not found")
+	ErrAPIKeyRevoked       = apperror.Conflict("API_KEY_REVOKED", "API key is already revoked")
+	ErrInvalidAPIKeyOwner  = apperror.BadRequest("INVALID_API_KEY_OWNER", "API keys can only be issued to verified customer accounts")
+	ErrInvalidAPIKeyExpiry = apperror.BadRequest("INVALID_API_KEY_EXPIRY", "expires_at must be in the future")
+)
+
+type APIKeyService interface {
+	Create(adminID uint, req CreateAPIKeyRequest) (*CreatedAPIKeyResponse, error)
+	List(userID uint) ([]APIKeyResponse, error)
+	Get(id uint) (*APIKeyResponse, error)
+	Update(id uint, req UpdateAPIKeyRequest) (*APIKeyResponse, error)
+	Revoke(id uint) error
+	AuthenticateAPIKey(rawKey, clientIP string) (*middleware.APIKeyPrincipal, error)
+}
+
+type apiKeyService struct {
+	repo APIKeyRepository
+}
+
+func NewAPIKeyService(repo APIKeyRepository) APIKeyService {
+	return &apiKeyService{repo: repo}
+}
+
+func (s *apiKeyService) Create(adminID uint, req CreateAPIKeyRequest) (*CreatedAPIKeyResponse, error) {
+	user, err := s.repo.FindUserByID(req.UserID)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrInvalidAPIKeyOwner.Withf("user %d not found", req.UserID)
+		}
+		return nil, apperror.Internal(err)
+	}
+	// Key milik admin akan membawa hak admin ke integrasi partner
+	if user.Role != models.RoleCustomer || !user.IsVerified {
+		return nil, ErrInvalidAPIKeyOwner
+	}
+	if err := validateExpiry(req.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	rawKey, err := generateAPIKey()
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	key := &models.APIKey{
+		UserID:             user.ID,
+		Name:               strings.TrimSpace(req.Name),
+		KeyPrefix:          rawKey[:keyPrefixLength],
+		KeyHash:            hashAPIKey(rawKey),
+		Scopes:             normalizeScopes(req.Scopes),
+		RateLimitPerMinute: rateLimitOrDefault(req.RateLimitPerMinute),
+		ExpiresAt:          req.ExpiresAt,
+		CreatedBy:          adminID,
+	}
+	if err := s.repo.Create(key); err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	log.Printf("[API KEY] key %d (%s) issued to user %d by admin %d\n", key.ID, key.KeyPrefix, key.UserID, adminID)
+
+	return &CreatedAPIKeyResponse{
+		APIKeyResponse: toAPIKeyResponse(key),
+		Key:            rawKey,
+	}, nil
+}
+
+func (s *apiKeyService) List(userID uint) ([]APIKeyResponse, error) {
+	keys, err := s.repo.List(userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	result := make([]APIKeyResponse, 0, len(keys))
+	for i := range keys {
+		result = append(result, toAPIKeyResponse(&keys[i]))
+	}
+	return result, nil
+}
+
+func (s *apiKeyService) Get(id uint) (*APIKeyResponse, error) {
+	key, err := s.findKey(id)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toAPIKeyResponse(key)
+	return &resp, nil
+}
+
+func (s *apiKeyService) Update(id uint, req UpdateAPIKeyRequest) (*APIKeyResponse, error) {
+	key, err := s.findKey(id)
+	if err != nil {
+		return nil, err
+	}
+	if key.RevokedAt != nil {
+		return nil, ErrAPIKeyRevoked
+	}
+	if err := validateExpiry(req.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	key.Name = strings.TrimSpace(req.Name)
+	key.Scopes = normalizeScopes(req.Scopes)
+	key.RateLimitPerMinute = rateLimitOrDefault(req.RateLimitPerMinute)
+	key.ExpiresAt = req.ExpiresAt
+
+	if err := s.repo.Update(key); err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	resp := toAPIKeyResponse(key)
+	return &resp, nil
+}
+
+func (s *apiKeyService) Revoke(id uint) error {
+	if _, err := s.findKey(id); err != nil {
+		return err
+	}
+
+	revoked, err := s.repo.Revoke(id, time.Now())
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if !revoked {
+		return ErrAPIKeyRevoked
+	}
+
+	log.Printf("[API KEY] key %d revoked\n", id)
+	return nil
+}
+
+// AuthenticateAPIKey dipanggil middleware di setiap request partner. Semua
+// kegagalan dijawab dengan error yang sama agar key yang valid tidak bisa
+// dibedakan dari key yang sudah dicabut atau kedaluwarsa.
+func (s *apiKeyService) AuthenticateAPIKey(rawKey, clientIP string) (*middleware.APIKeyPrincipal, error) {
+	if !strings.HasPrefix(rawKey, models.APIKeyPrefix) {
+		return nil, middleware.ErrInvalidAPIKey
+	}
+
+	key, err := s.repo.FindByHash(hashAPIKey(rawKey))
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, middleware.ErrInvalidAPIKey
+		}
+		return nil, apperror.Internal(err)
+	}
+
+	now := time.Now()
+	owner := key.User
+	if !key.IsActive(now) || owner.DeletedAt != nil || !owner.IsVerified || owner.Role != models.RoleCustomer {
+		return nil, middleware.ErrInvalidAPIKey
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= lastUsedResolution {
+		if err := s.repo.TouchLastUsed(key.ID, clientIP, now); err != nil {
+			// Gagal mencatat pemakaian tidak perlu menolak request partner
+			log.Printf("[API KEY] failed to record usage of key %d: %v\n", key.ID, err)
+		}
+	}
+
+	return &middleware.APIKeyPrincipal{
+		KeyID:              key.ID,
+		UserID:             owner.ID,
+		Role:               string(owner.Role),
+		Scopes:             key.ScopeList(),
+		RateLimitPerMinute: key.RateLimitPerMinute,
+	}, nil
+}
+
+func (s *apiKeyService) findKey(id uint) (*models.APIKey, error) {
+	key, err := s.repo.FindByID(id)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, ErrAPIKeyNotFound
+		}
+		return nil, apperror.Internal(err)
+	}
+	return key, nil
+}
+
+func validateExpiry(expiresAt *time.Time) error {
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return ErrInvalidAPIKeyExpiry
+	}
+	return nil
+}
+
+func rateLimitOrDefault(perMinute int) int {
+	if perMinute <= 0 {
+		return defaultRateLimitPerMinute
+	}
+	return perMinute
+}
+
+// normalizeScopes membuang duplikat dan mengurutkan scope agar tersimpan
+// dalam bentuk yang konsisten.
+func normalizeScopes(scopes []string) string {
+	seen := make(map[string]bool, len(scopes))
+	unique := make([]string, 0, len(scopes))
+	for _, scope := range scopes {
+		if !seen[scope] {
+			seen[scope] = true
+			unique = append(unique, scope)
+		}
+	}
+	sort.Strings(unique)
+	return strings.Join(unique, " ")
+}
+
+// generateAPIKey membuat key berformat ezk_<64 hex>. Entropi 256 bit membuat
+// SHA-256 biasa cukup untuk menyimpannya, tanpa perlu bcrypt.
+func generateAPIKey() (string, error) {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+	return models.APIKeyPrefix + hex.EncodeToString(secret), nil
+}
+
+func hashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
@@ -1,6 +1,10 @@
 package auth
 
-import "ezytix-be/internal/models"
+import (
+	"time"
+
+	"ezytix-be/internal/models"
+)
 
 type RegisterRequest struct {
 	FullName string `json:"full_name" validate:"required"`
@@ -11,14 +15,14 @@ type RegisterRequest struct {
 }
 
 type LoginRequest struct {
-	Email    string `json:"email,omitempty"`
-	Phone    string `json:"phone,omitempty"`
-	Password string `json:"password"`
+	Email    string `json:"email,omitempty" validate:"required_without=Phone,omitempty,email"`
+	Phone    string `json:"phone,omitempty" validate:"required_without=Email"`
+	Password string `json:"password" validate:"required"`
 }
 
 type ChangePasswordRequest struct {
-    OldPassword string `json:"old_password"`
-    NewPassword string `json:"new_password"`
+    OldPassword string `json:"old_password" validate:"required"`
+    NewPassword string `json:"new_password" validate:"required,min=8"`
 }
 
 
@@ -26,6 +30,18 @@ type LoginResponse struct {
 	User *models.User `json:"user"`
 }
 
+// TokenResponse dikirim ke klien non-browser yang meminta token di body
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int    `json:"expires_in"`
+}
+
+type RefreshRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
 type UpdateProfileRequest struct {
 	FullName string `json:"full_name" validate:"required"`
 	Username string `json:"username" validate:"required,min=4,max=16"`
@@ -40,4 +56,32 @@ type VerifyOTPRequest struct {
 
 type ResendOTPRequest struct {
 	Email string `json:"email" validate:"required,email"`
-}
\ No newline at end of file
+}
+
+type ForgotPasswordRequest struct {
+	Email string `json:"email" validate:"required,email"`
+}
+
+type ResetPasswordRequest struct {
+	Token       string `json:"token" validate:"required"`
+	NewPassword string `json:"new_password" validate:"required,min=8"`
+}
+
+// SessionMeta adalah info perangkat yang dicatat di user_sessions saat login
+// dan setiap kali refresh token dirotasi.
+type SessionMeta struct {
+	Device    string
+	IPAddress string
+	UserAgent string
+}
+
+type SessionResponse struct {
+	ID         uint      `json:"id"`
+	Device     string    `json:"device"`
+	IPAddress  string    `json:"ip_address"`
+	UserAgent  string    `json:"user_agent"`
+	LastUsedAt time.Time `json:"last_used_at"`
+	CreatedAt  time.Time `json:"created_at"`
+	ExpiresAt  time.Time `json:"expires_at"`
+	Current    bool      `json:"current"`
+}
@@ -1,13 +1,21 @@
 package auth
 
 import (
+	"strconv"
+	"strings"
+
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 
+	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
 	jwt "ezytix-be/pkg/jwt"
 	"ezytix-be/pkg/mail"
+	"ezytix-be/pkg/validation"
 )
 
+var ErrInvalidSessionID = apperror.BadRequest("INVALID_SESSION_ID", "invalid session ID")
+
 type AuthHandler struct {
 	service AuthService
 }
@@ -22,17 +30,13 @@ func NewAuthHandler(db *gorm.DB) *AuthHandler {
 func (h *AuthHandler) Register(c *fiber.Ctx) error {
 	var req RegisterRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
-	user, err := h.service.Register(req)
+	user, err := h.service.Register(req, c.IP())
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
@@ -44,73 +48,38 @@ func (h *AuthHandler) Register(c *fiber.Ctx) error {
 func (h *AuthHandler) Login(c *fiber.Ctx) error {
 	var req LoginRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
-	resp, access, refresh, err := h.service.Login(req)
+	resp, access, refresh, err := h.service.Login(req, sessionMeta(c))
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "access_token",
-		Value:    access,
-		HTTPOnly: true,
-		SameSite: "Strict",
-		Path:     "/",
-		MaxAge:   60 * 15,
-	})
-
-	c.Cookie(&fiber.Cookie{
-		Name:     "refresh_token",
-		Value:    refresh,
-		HTTPOnly: true,
-		SameSite: "Strict",
-		Path:     "/",
-		MaxAge:   60 * 60 * 24 * 7, 
-	})
-
-	return c.JSON(fiber.Map{
-		"message": "login success",
-		"user":    resp.User,
-	})
+	return sendTokens(c, "login success", resp.User, access, refresh)
 }
 
 
 func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	// Browser mengirim refresh token lewat cookie, klien lain lewat body
 	refreshToken := c.Cookies("refresh_token")
 	if refreshToken == "" {
-		return c.Status(400).JSON(fiber.Map{"error": "missing refresh token"})
+		var req RefreshRequest
+		if err := c.BodyParser(&req); err == nil {
+			refreshToken = req.RefreshToken
+		}
+	}
+	if refreshToken == "" {
+		return ErrInvalidRefreshToken.Withf("missing refresh token")
 	}
 
-	resp, access, refresh, err := h.service.Refresh(refreshToken)
+	resp, access, refresh, err := h.service.Refresh(refreshToken, sessionMeta(c))
 	if err != nil {
-		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+		return err
 	}
 
-	c.Cookie(&fiber.Cookie{
-		Name:     "access_token",
-		Value:    access,
-		HTTPOnly: true,
-		SameSite: "Strict",
-		Path:     "/",
-		MaxAge:   60 * 15,
-	})
-
-	c.Cookie(&fiber.Cookie{
-		Name:     "refresh_token",
-		Value:    refresh,
-		HTTPOnly: true,
-		SameSite: "Strict",
-		Path:     "/",
-		MaxAge:   60 * 60 * 24 * 7,
-	})
-
-	return c.JSON(fiber.Map{
-		"message": "refresh success",
-		"user":    resp.User,
-	})
+	return sendTokens(c, "refresh success", resp.User, access, refresh)
 }
 
 
@@ -119,9 +88,7 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 
     user, err := h.service.GetUserByID(uint(claims.UserID))
     if err != nil {
-        return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-            "error": "user tidak ditemukan",
-        })
+        return err
     }
 
     return c.JSON(user)
@@ -129,13 +96,141 @@ func (h *AuthHandler) Me(c *fiber.Ctx) error {
 
 func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 	var req VerifyOTPRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	resp, access, refresh, err := h.service.VerifyOTP(req, sessionMeta(c))
+	if err != nil {
+		return err
+	}
+
+	return sendTokens(c, "verifikasi berhasil", resp.User, access, refresh)
+}
+
+func (h *AuthHandler) ResendOTP(c *fiber.Ctx) error {
+	var req ResendOTPRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.service.ResendOTP(req, c.IP()); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "OTP baru telah dikirim ke email",
+	})
+}
+
+func (h *AuthHandler) ForgotPassword(c *fiber.Ctx) error {
+	var req ForgotPasswordRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.service.ForgotPassword(req); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "jika email terdaftar, tautan reset password telah dikirim",
+	})
+}
+
+func (h *AuthHandler) ResetPassword(c *fiber.Ctx) error {
+	var req ResetPasswordRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	if err := h.service.ResetPassword(req); err != nil {
+		return err
+	}
+
+	c.Cookie(&fiber.Cookie{Name: "access_token", Value: "", MaxAge: -1, Path: "/"})
+	c.Cookie(&fiber.Cookie{Name: "refresh_token", Value: "", MaxAge: -1, Path: "/"})
+
+	return c.JSON(fiber.Map{
+		"message": "password berhasil direset, silakan login kembali",
+	})
+}
+
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*jwt.JWTClaims)
+
+	if err := h.service.Logout(claims.UserID, claims.SessionID); err != nil {
+		return err
+	}
+
+	c.Cookie(&fiber.Cookie{Name: "access_token", Value: "", MaxAge: -1, Path: "/"})
+	c.Cookie(&fiber.Cookie{Name: "refresh_token", Value: "", MaxAge: -1, Path: "/"})
+
+	return c.JSON(fiber.Map{"message": "logged out"})
+}
+
+func (h *AuthHandler) LogoutAll(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*jwt.JWTClaims)
+
+	if err := h.service.LogoutAll(claims.UserID); err != nil {
+		return err
 	}
 
-	resp, access, refresh, err := h.service.VerifyOTP(req)
+	c.Cookie(&fiber.Cookie{Name: "access_token", Value: "", MaxAge: -1, Path: "/"})
+	c.Cookie(&fiber.Cookie{Name: "refresh_token", Value: "", MaxAge: -1, Path: "/"})
+
+	return c.JSON(fiber.Map{"message": "logged out from all devices"})
+}
+
+func (h *AuthHandler) ListSessions(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*jwt.JWTClaims)
+
+	sessions, err := h.service.ListSessions(claims.UserID, claims.SessionID)
 	if err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message":  "sessions retrieved successfully",
+		"sessions": sessions,
+	})
+}
+
+func (h *AuthHandler) RevokeSession(c *fiber.Ctx) error {
+	claims := c.Locals("user").(*jwt.JWTClaims)
+
+	sessionID, err := strconv.ParseUint(c.Params("id"), 10, 32)
+	if err != nil {
+		return ErrInvalidSessionID
+	}
+
+	if err := h.service.RevokeSession(claims.UserID, uint(sessionID)); err != nil {
+		return err
+	}
+
+	if uint(sessionID) == claims.SessionID {
+		c.Cookie(&fiber.Cookie{Name: "access_token", Value: "", MaxAge: -1, Path: "/"})
+		c.Cookie(&fiber.Cookie{Name: "refresh_token", Value: "", MaxAge: -1, Path: "/"})
+	}
+
+	return c.JSON(fiber.Map{"message": "session revoked"})
+}
+
+// sendTokens mengirim token lewat cookie HttpOnly untuk browser. Aplikasi
+// mobile dan CLI mengirim header X-Token-Delivery: body agar token dikembalikan
+// di response lalu dipakai lewat header Authorization: Bearer.
+func sendTokens(c *fiber.Ctx, message string, user *models.User, access, refresh string) error {
+	if strings.EqualFold(c.Get("X-Token-Delivery"), "body") {
+		return c.JSON(fiber.Map{
+			"message": message,
+			"user":    user,
+			"token": TokenResponse{
+				AccessToken:  access,
+				RefreshToken: refresh,
+				TokenType:    "Bearer",
+				ExpiresIn:    int(jwt.AccessTokenTTL.Seconds()),
+			},
+		})
 	}
 
 	c.Cookie(&fiber.Cookie{
@@ -144,7 +239,7 @@ func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 		HTTPOnly: true,
 		SameSite: "Strict",
 		Path:     "/",
-		MaxAge:   60 * 15,
+		MaxAge:   int(jwt.AccessTokenTTL.Seconds()),
 	})
 	c.Cookie(&fiber.Cookie{
 		Name:     "refresh_token",
@@ -152,49 +247,45 @@ func (h *AuthHandler) VerifyOTP(c *fiber.Ctx) error {
 		HTTPOnly: true,
 		SameSite: "Strict",
 		Path:     "/",
-		MaxAge:   60 * 60 * 24 * 7,
+		MaxAge:   int(jwt.RefreshTokenTTL.Seconds()),
 	})
 
 	return c.JSON(fiber.Map{
-		"message": "verifikasi berhasil",
-		"user":    resp.User,
+		"message": message,
+		"user":    user,
 	})
 }
 
-func (h *AuthHandler) ResendOTP(c *fiber.Ctx) error {
-	var req ResendOTPRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+// sessionMeta membaca info perangkat dari request. Nama perangkat boleh
+// dikirim klien lewat header X-Device-Name.
+func sessionMeta(c *fiber.Ctx) SessionMeta {
+	device := strings.TrimSpace(c.Get("X-Device-Name"))
+	if device == "" {
+		device = "Unknown device"
 	}
-
-	if err := h.service.ResendOTP(req); err != nil {
-		return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+	if len(device) > 255 {
+		device = device[:255]
 	}
 
-	return c.JSON(fiber.Map{
-		"message": "OTP baru telah dikirim ke email",
-	})
-}
-
-func (h *AuthHandler) Logout(c *fiber.Ctx) error {
-	c.Cookie(&fiber.Cookie{Name: "access_token", Value: "", MaxAge: -1, Path: "/"})
-	c.Cookie(&fiber.Cookie{Name: "refresh_token", Value: "", MaxAge: -1, Path: "/"})
-
-	return c.JSON(fiber.Map{"message": "logged out"})
+	return SessionMeta{
+		Device:    device,
+		IPAddress: c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
 }
 
 func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
     var req ChangePasswordRequest
 
-    if err := c.BodyParser(&req); err != nil {
-        return c.Status(400).JSON(fiber.Map{"error": "invalid request"})
+    if err := validation.ParseBody(c, &req); err != nil {
+        return err
     }
 
     claims := c.Locals("user").(*jwt.JWTClaims)
     userID := claims.UserID
 
     if err := h.service.ChangePassword(userID, req); err != nil {
-        return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+        return err
     }
 
     c.Cookie(&fiber.Cookie{Name: "access_token", Value: "", MaxAge: -1, Path: "/"})
@@ -208,10 +299,8 @@ func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
 func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 	var req UpdateProfileRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	claims := c.Locals("user").(*jwt.JWTClaims)
@@ -219,9 +308,7 @@ func (h *AuthHandler) UpdateProfile(c *fiber.Ctx) error {
 
 	updatedUser, err := h.service.UpdateProfile(userID, req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
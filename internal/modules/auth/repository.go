@@ -3,8 +3,10 @@ package auth
 import (
 	"errors"
 	"ezytix-be/internal/models"
+	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AuthRepository interface {
@@ -19,6 +21,22 @@ type AuthRepository interface {
 	CreateOrUpdateOTP(otp *models.UserOTP) error
 	FindOTPByUserID(userID uint) (*models.UserOTP, error)
 	DeleteOTP(userID uint) error
+	RecordOTPFailure(otpID uint, maxAttempts int, lockout time.Duration) (*models.UserOTP, error)
+	CreateOTPSendLog(entry *models.OTPSendLog) error
+	CountOTPSendsByEmail(email string, since time.Time) (int64, error)
+	CountOTPSendsByIP(ip string, since time.Time) (int64, error)
+	FindLastOTPSend(email string) (*models.OTPSendLog, error)
+	RecordLoginFailure(userID uint, lockoutFor func(attempts int) time.Duration) (*models.User, error)
+	ResetLoginFailures(userID uint) error
+	CreatePasswordReset(reset *models.PasswordReset) error
+	FindPasswordResetByHash(tokenHash string) (*models.PasswordReset, error)
+	ResetPassword(resetID uint, userID uint, hashedPassword string) error
+	CreateSession(session *models.UserSession) error
+	FindSessionByID(id uint) (*models.UserSession, error)
+	RotateSession(session *models.UserSession, oldHash string) (bool, error)
+	ListActiveSessions(userID uint) ([]models.UserSession, error)
+	RevokeSession(userID uint, sessionID uint, reason string) (bool, error)
+	RevokeAllSessions(userID uint, reason string) error
 }
 
 type authRepository struct {
@@ -38,7 +56,7 @@ func (r *authRepository) FindByEmailOrPhone(identifier string) (*models.User, er
 		First(&user).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user tidak ditemukan")
+		return nil, ErrUserNotFound
 	}
 
 	return &user, err
@@ -51,7 +69,7 @@ func (r *authRepository) FindByID(id uint) (*models.User, error) {
 	err := r.db.First(&user, id).Error
 
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("user tidak ditemukan")
+		return nil, ErrUserNotFound
 	}
 
 	return &user, err
@@ -92,9 +110,26 @@ func (r *authRepository) FindByUsername(username string) (*models.User, error) {
 }
 
 func (r *authRepository) UpdatePassword(userID uint, hashedPassword string) error {
-    return r.db.Model(&models.User{}).
-        Where("id = ?", userID).
-        Update("password", hashedPassword).Error
+    return r.db.Transaction(func(tx *gorm.DB) error {
+        return updatePassword(tx, userID, hashedPassword)
+    })
+}
+
+// updatePassword juga mencatat password_changed_at, membuka kunci login, dan
+// mencabut semua sesi sehingga refresh token lama tidak bisa dipakai lagi.
+func updatePassword(tx *gorm.DB, userID uint, hashedPassword string) error {
+	if err := tx.Model(&models.User{}).
+		Where("id = ?", userID).
+		Updates(map[string]interface{}{
+			"password":              hashedPassword,
+			"password_changed_at":   time.Now(),
+			"failed_login_attempts": 0,
+			"login_locked_until":    nil,
+		}).Error; err != nil {
+		return err
+	}
+
+	return revokeSessions(tx, userID, models.SessionRevokedPasswordChanged)
 }
 
 func (r *authRepository) UpdateUser(user *models.User) error {
@@ -105,8 +140,11 @@ func (r *authRepository) CreateOrUpdateOTP(otp *models.UserOTP) error {
 	var existing models.UserOTP
 	err := r.db.Where("user_id = ?", otp.UserID).First(&existing).Error
 	if err == nil {
-		existing.OTPCode = otp.OTPCode
+		// Kode baru membuka kembali kesempatan menebak dari nol
+		existing.OTPHash = otp.OTPHash
 		existing.ExpiredAt = otp.ExpiredAt
+		existing.FailedAttempts = 0
+		existing.LockedUntil = nil
 		return r.db.Save(&existing).Error
 	}
 	return r.db.Create(otp).Error
@@ -116,11 +154,208 @@ func (r *authRepository) FindOTPByUserID(userID uint) (*models.UserOTP, error) {
 	var otp models.UserOTP
 	err := r.db.Where("user_id = ?", userID).First(&otp).Error
 	if errors.Is(err, gorm.ErrRecordNotFound) {
-		return nil, errors.New("kode OTP tidak ditemukan")
+		return nil, ErrOTPNotFound
 	}
 	return &otp, err
 }
 
 func (r *authRepository) DeleteOTP(userID uint) error {
 	return r.db.Where("user_id = ?", userID).Delete(&models.UserOTP{}).Error
-}
\ No newline at end of file
+}
+
+// CreatePasswordReset menghapus token reset lama milik user yang belum dipakai
+// agar hanya tautan terbaru yang berlaku.
+func (r *authRepository) CreatePasswordReset(reset *models.PasswordReset) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", reset.UserID).
+			Delete(&models.PasswordReset{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(reset).Error
+	})
+}
+
+func (r *authRepository) FindPasswordResetByHash(tokenHash string) (*models.PasswordReset, error) {
+	var reset models.PasswordReset
+	err := r.db.Where("token_hash = ?", tokenHash).First(&reset).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInvalidResetToken
+	}
+	return &reset, err
+}
+
+// ResetPassword menandai token terpakai dan mengganti password dalam satu
+// transaksi. Update bersyarat used_at IS NULL mencegah token dipakai dua kali
+// oleh request yang berjalan bersamaan.
+func (r *authRepository) ResetPassword(resetID uint, userID uint, hashedPassword string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.PasswordReset{}).
+			Where("id = ? AND used_at IS NULL", resetID).
+			Update("used_at", time.Now())
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInvalidResetToken
+		}
+
+		return updatePassword(tx, userID, hashedPassword)
+	})
+}
+
+func (r *authRepository) CreateSession(session *models.UserSession) error {
+	return r.db.Create(session).Error
+}
+
+func (r *authRepository) FindSessionByID(id uint) (*models.UserSession, error) {
+	var session models.UserSession
+	err := r.db.First(&session, id).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrSessionNotFound
+	}
+	return &session, err
+}
+
+// RotateSession mengganti hash refresh token hanya jika hash lama masih sama.
+// false berarti token lama sudah dirotasi oleh request lain atau sesi dicabut.
+func (r *authRepository) RotateSession(session *models.UserSession, oldHash string) (bool, error) {
+	result := r.db.Model(&models.UserSession{}).
+		Where("id = ? AND refresh_token_hash = ? AND revoked_at IS NULL", session.ID, oldHash).
+		Updates(map[string]interface{}{
+			"refresh_token_hash": session.RefreshTokenHash,
+			"ip_address":         session.IPAddress,
+			"user_agent":         session.UserAgent,
+			"last_used_at":       session.LastUsedAt,
+			"expires_at":         session.ExpiresAt,
+			"updated_at":         time.Now(),
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *authRepository) ListActiveSessions(userID uint) ([]models.UserSession, error) {
+	var sessions []models.UserSession
+	err := r.db.
+		Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userID, time.Now()).
+		Order("last_used_at DESC").
+		Find(&sessions).Error
+	return sessions, err
+}
+
+func (r *authRepository) RevokeSession(userID uint, sessionID uint, reason string) (bool, error) {
+	now := time.Now()
+	result := r.db.Model(&models.UserSession{}).
+		Where("id = ? AND user_id = ? AND revoked_at IS NULL", sessionID, userID).
+		Updates(map[string]interface{}{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+			"updated_at":     now,
+		})
+	if result.Error != nil {
+		return false, result.Error
+	}
+	return result.RowsAffected == 1, nil
+}
+
+func (r *authRepository) RevokeAllSessions(userID uint, reason string) error {
+	return revokeSessions(r.db, userID, reason)
+}
+
+func revokeSessions(tx *gorm.DB, userID uint, reason string) error {
+	now := time.Now()
+	return tx.Model(&models.UserSession{}).
+		Where("user_id = ? AND revoked_at IS NULL", userID).
+		Updates(map[string]interface{}{
+			"revoked_at":     now,
+			"revoked_reason": reason,
+			"updated_at":     now,
+		}).Error
+}
+
+// RecordOTPFailure menambah hitungan percobaan salah dengan mengunci baris OTP
+// agar tebakan paralel tetap terhitung semua. Saat batas tercapai, hash OTP
+// dikosongkan sehingga user wajib meminta kode baru setelah masa lockout.
+func (r *authRepository) RecordOTPFailure(otpID uint, maxAttempts int, lockout time.Duration) (*models.UserOTP, error) {
+	var otp models.UserOTP
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&otp, otpID).Error; err != nil {
+			return err
+		}
+
+		otp.FailedAttempts++
+		if otp.FailedAttempts >= maxAttempts {
+			lockedUntil := time.Now().Add(lockout)
+			otp.LockedUntil = &lockedUntil
+			otp.OTPHash = ""
+		}
+
+		return tx.Model(&otp).Select("failed_attempts", "locked_until", "otp_hash").Updates(&otp).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &otp, nil
+}
+
+func (r *authRepository) CreateOTPSendLog(entry *models.OTPSendLog) error {
+	return r.db.Create(entry).Error
+}
+
+func (r *authRepository) CountOTPSendsByEmail(email string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OTPSendLog{}).
+		Where("email = ? AND created_at >= ?", email, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *authRepository) CountOTPSendsByIP(ip string, since time.Time) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.OTPSendLog{}).
+		Where("ip_address = ? AND created_at >= ?", ip, since).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *authRepository) FindLastOTPSend(email string) (*models.OTPSendLog, error) {
+	var entry models.OTPSendLog
+	err := r.db.Where("email = ?", email).Order("created_at DESC").First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+	return &entry, err
+}
+
+// RecordLoginFailure menambah hitungan password salah di bawah row lock dan
+// memasang login_locked_until sesuai durasi dari lockoutFor.
+func (r *authRepository) RecordLoginFailure(userID uint, lockoutFor func(attempts int) time.Duration) (*models.User, error) {
+	var user models.User
+	err := r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userID).Error; err != nil {
+			return err
+		}
+
+		user.FailedLoginAttempts++
+		if lockout := lockoutFor(user.FailedLoginAttempts); lockout > 0 {
+			lockedUntil := time.Now().Add(lockout)
+			user.LoginLockedUntil = &lockedUntil
+		}
+
+		return tx.Model(&user).Select("failed_login_attempts", "login_locked_until").Updates(&user).Error
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &user, nil
+}
+
+func (r *authRepository) ResetLoginFailures(userID uint) error {
+	return r.db.Model(&models.User{}).
+		Where("id = ? AND (failed_login_attempts > 0 OR login_locked_until IS NOT NULL)", userID).
+		Updates(map[string]interface{}{
+			"failed_login_attempts": 0,
+			"login_locked_until":    nil,
+		}).Error
+}
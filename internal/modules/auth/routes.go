@@ -1,6 +1,8 @@
 package auth
 
 import (
+	"time"
+
 	"ezytix-be/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
@@ -11,17 +13,40 @@ func AuthRegisterRoutes(app *fiber.App, db *gorm.DB) {
 	h := NewAuthHandler(db)
 
 	auth := app.Group("/api/v1/auth")
+	auth.Use(middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "auth",
+		Max:    60,
+		Window: time.Minute,
+	}))
+
+	// Endpoint yang bisa dipakai menebak password atau mengirim email diberi
+	// batas per IP yang lebih ketat
+	credentialLimit := middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "auth-credentials",
+		Max:    10,
+		Window: time.Minute,
+	})
+	emailLimit := middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "auth-email",
+		Max:    5,
+		Window: 15 * time.Minute,
+	})
 
-	auth.Post("/register", h.Register)
-	auth.Post("/login", h.Login)
+	auth.Post("/register", emailLimit, h.Register)
+	auth.Post("/login", credentialLimit, h.Login)
 	auth.Post("/refresh", h.Refresh)
-	auth.Post("/verify-otp", h.VerifyOTP)
-	auth.Post("/resend-otp", h.ResendOTP)
+	auth.Post("/verify-otp", credentialLimit, h.VerifyOTP)
+	auth.Post("/resend-otp", emailLimit, h.ResendOTP)
+	auth.Post("/forgot-password", emailLimit, h.ForgotPassword)
+	auth.Post("/reset-password", credentialLimit, h.ResetPassword)
 
 	authProtected := auth.Group("/")
 	authProtected.Use(middleware.JWTMiddleware)
 	authProtected.Get("/me", h.Me)
 	authProtected.Post("/logout", h.Logout)
+	authProtected.Post("/logout-all", h.LogoutAll)
+	authProtected.Get("/sessions", h.ListSessions)
+	authProtected.Delete("/sessions/:id", h.RevokeSession)
 	authProtected.Post("/change-password", h.ChangePassword)
 	authProtected.Put("/profile", h.UpdateProfile)
 }
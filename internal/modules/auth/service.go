@@ -1,30 +1,92 @@
 package auth
 
 import (
+	"crypto/hmac"
 	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
 	"errors"
-	"fmt"
+	"log"
 	"math/big"
+	"os"
 	"regexp"
+	"strings"
 	"time"
 
+	"ezytix-be/internal/config"
 	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
 	"ezytix-be/pkg/hash"
 	"ezytix-be/pkg/jwt"
 	"ezytix-be/pkg/mail" // [BARU] Import mail service
 )
 
 type AuthService interface {
-	Register(req RegisterRequest) (*models.User, error)
-	Login(req LoginRequest) (*LoginResponse, string, string, error)
-	Refresh(refreshToken string) (*LoginResponse, string, string, error)
+	Register(req RegisterRequest, clientIP string) (*models.User, error)
+	Login(req LoginRequest, meta SessionMeta) (*LoginResponse, string, string, error)
+	Refresh(refreshToken string, meta SessionMeta) (*LoginResponse, string, string, error)
 	GetUserByID(id uint) (*models.User, error)
     ChangePassword(userID uint, req ChangePasswordRequest) error
     UpdateProfile(userID uint, req UpdateProfileRequest) (*models.User, error)
-	VerifyOTP(req VerifyOTPRequest) (*LoginResponse, string, string, error)
-	ResendOTP(req ResendOTPRequest) error
+	VerifyOTP(req VerifyOTPRequest, meta SessionMeta) (*LoginResponse, string, string, error)
+	ResendOTP(req ResendOTPRequest, clientIP string) error
+	ForgotPassword(req ForgotPasswordRequest) error
+	ResetPassword(req ResetPasswordRequest) error
+	ListSessions(userID uint, currentSessionID uint) ([]SessionResponse, error)
+	RevokeSession(userID uint, sessionID uint) error
+	Logout(userID uint, sessionID uint) error
+	LogoutAll(userID uint) error
 }
 
+var (
+	ErrUserNotFound           = apperror.NotFound("USER_NOT_FOUND", "user not found")
+	ErrMissingFields          = apperror.Validation("MISSING_FIELDS", "all fields are required")
+	ErrInvalidUsername        = apperror.Validation("INVALID_USERNAME", "username may only contain letters and digits (4-16 characters)")
+	ErrUsernameTaken          = apperror.Conflict("USERNAME_TAKEN", "username is already taken")
+	ErrEmailTaken             = apperror.Conflict("EMAIL_TAKEN", "email is already taken")
+	ErrPhoneTaken             = apperror.Conflict("PHONE_TAKEN", "phone number is already taken")
+	ErrInvalidCredentials     = apperror.Unauthorized("INVALID_CREDENTIALS", "invalid email, phone or password")
+	ErrAccountNotVerified     = apperror.Forbidden("ACCOUNT_NOT_VERIFIED", "account is not verified, please enter the OTP sent to your email")
+	ErrAccountAlreadyVerified = apperror.Conflict("ACCOUNT_ALREADY_VERIFIED", "account is already verified")
+	ErrOTPNotFound            = apperror.NotFound("OTP_NOT_FOUND", "OTP code not found")
+	ErrInvalidOTP             = apperror.BadRequest("INVALID_OTP", "OTP code is incorrect")
+	ErrOTPExpired             = apperror.Expired("OTP_EXPIRED", "OTP code has expired, please request a new one")
+	ErrOTPLocked              = apperror.TooManyRequests("OTP_LOCKED", "too many incorrect OTP attempts, please try again later")
+	ErrOTPResendCooldown      = apperror.TooManyRequests("OTP_RESEND_COOLDOWN", "please wait before requesting another OTP")
+	ErrOTPDailyLimit          = apperror.TooManyRequests("OTP_DAILY_LIMIT", "daily OTP limit reached, please try again tomorrow")
+	ErrAccountLocked          = apperror.TooManyRequests("ACCOUNT_LOCKED", "too many failed login attempts, please try again later")
+	ErrInvalidRefreshToken    = apperror.Unauthorized("INVALID_REFRESH_TOKEN", "invalid refresh token")
+	ErrWrongPassword          = apperror.BadRequest("WRONG_PASSWORD", "old password is incorrect")
+	ErrWeakPassword           = apperror.Validation("WEAK_PASSWORD", "new password must be at least 8 characters and contain letters and digits")
+	ErrInvalidResetToken      = apperror.BadRequest("INVALID_RESET_TOKEN", "reset token is invalid or has already been used")
+	ErrResetTokenExpired      = apperror.Expired("RESET_TOKEN_EXPIRED", "reset token has expired, please request a new one")
+	ErrSessionNotFound        = apperror.NotFound("SESSION_NOT_FOUND", "session not found")
+	ErrSessionRevoked         = apperror.Unauthorized("SESSION_REVOKED", "session has been revoked or expired, please login again")
+	ErrRefreshTokenReused     = apperror.Unauthorized("REFRESH_TOKEN_REUSED", "refresh token was already used, all tokens of this session have been revoked")
+)
+
+// Masa berlaku tautan reset password yang dikirim lewat email
+const passwordResetTTL = 30 * time.Minute
+
+// Batasan OTP verifikasi akun. Batas harian dihitung dalam 24 jam terakhir.
+const (
+	otpTTL                = 5 * time.Minute
+	otpMaxAttempts        = 5
+	otpLockoutDuration    = 15 * time.Minute
+	otpResendCooldown     = 60 * time.Second
+	otpDailyLimitPerEmail = 5
+	otpDailyLimitPerIP    = 20
+)
+
+// Penguncian login bertahap: mulai percobaan ke-5, durasi kunci berlipat dua
+// setiap kali password salah lagi (1, 2, 4, ... menit) hingga maksimal 1 jam.
+const (
+	loginFreeAttempts = 5
+	loginBaseLockout  = time.Minute
+	loginMaxLockout   = time.Hour
+)
+
 type authService struct {
 	repo AuthRepository
 	mail mail.MailService
@@ -47,34 +109,39 @@ func generateOTPCode() string {
 	return string(b)
 }
 
-func (s *authService) Register(req RegisterRequest) (*models.User, error) {
+func (s *authService) Register(req RegisterRequest, clientIP string) (*models.User, error) {
 	if req.FullName == "" || req.Username == "" || req.Email == "" || req.Phone == "" || req.Password == "" {
-		return nil, errors.New("semua field harus diisi")
+		return nil, ErrMissingFields
 	}
 
 	usernameRegex := regexp.MustCompile(`^[A-Za-z0-9]{4,16}$`)
 	if !usernameRegex.MatchString(req.Username) {
-		return nil, errors.New("username hanya boleh huruf dan angka (4–16 karakter)")
+		return nil, ErrInvalidUsername
 	}
 
 	existingUsername, _ := s.repo.FindByUsername(req.Username)
 	if existingUsername != nil {
-		return nil, errors.New("username sudah digunakan")
+		return nil, ErrUsernameTaken
 	}
 
 	existingEmail, _ := s.repo.FindByEmail(req.Email)
 	if existingEmail != nil {
-		return nil, errors.New("email sudah digunakan")
+		return nil, ErrEmailTaken
 	}
 
 	existingPhone, _ := s.repo.FindByPhone(req.Phone)
 	if existingPhone != nil {
-		return nil, errors.New("phone sudah digunakan")
+		return nil, ErrPhoneTaken
+	}
+
+	// Dicek sebelum user dibuat agar akun tidak tertinggal tanpa OTP
+	if err := s.checkOTPThrottle(req.Email, clientIP); err != nil {
+		return nil, err
 	}
 
 	hashed, err := hash.HashPassword(req.Password)
 	if err != nil {
-		return nil, errors.New("gagal menghash password")
+		return nil, apperror.Internal(err)
 	}
 
 	user := &models.User{
@@ -88,27 +155,18 @@ func (s *authService) Register(req RegisterRequest) (*models.User, error) {
 	}
 
 	if err := s.repo.CreateUser(user); err != nil {
-		return nil, err
-	}
-
-	otpCode := generateOTPCode()
-	otpData := &models.UserOTP{
-		UserID:    user.ID,
-		OTPCode:   otpCode,
-		ExpiredAt: time.Now().Add(5 * time.Minute),
+		return nil, apperror.Internal(err)
 	}
 
-	if err := s.repo.CreateOrUpdateOTP(otpData); err != nil {
-		return nil, fmt.Errorf("berhasil register tapi gagal membuat OTP: %v", err)
+	if err := s.sendOTP(user, clientIP); err != nil {
+		return nil, err
 	}
 
-	go s.mail.SendOTPEmail(user.Email, user.FullName, otpCode)
-
 	return user, nil
 }
 
 
-func (s *authService) Login(req LoginRequest) (*LoginResponse, string, string, error) {
+func (s *authService) Login(req LoginRequest, meta SessionMeta) (*LoginResponse, string, string, error) {
     var identifier string
 
     if req.Email != "" {
@@ -116,30 +174,47 @@ func (s *authService) Login(req LoginRequest) (*LoginResponse, string, string, e
     } else if req.Phone != "" {
         identifier = req.Phone
     } else {
-        return nil, "", "", errors.New("email atau phone harus diisi")
+        return nil, "", "", ErrInvalidCredentials
     }
 
+    // Email tidak terdaftar dan password salah sengaja memakai error yang sama
     user, err := s.repo.FindByEmailOrPhone(identifier)
     if err != nil {
+        if errors.Is(err, ErrUserNotFound) {
+            return nil, "", "", ErrInvalidCredentials
+        }
         return nil, "", "", err
     }
 
+    // Selama terkunci password tidak dicek sama sekali agar tebakan tidak berguna
+    now := time.Now()
+    if user.LoginLockedUntil != nil && now.Before(*user.LoginLockedUntil) {
+        return nil, "", "", accountLockedError(*user.LoginLockedUntil, now)
+    }
+
     if !hash.CheckPassword(req.Password, user.Password) {
-        return nil, "", "", errors.New("password salah")
+        updated, err := s.repo.RecordLoginFailure(user.ID, loginLockoutFor)
+        if err != nil {
+            return nil, "", "", apperror.Internal(err)
+        }
+        if updated.LoginLockedUntil != nil && now.Before(*updated.LoginLockedUntil) {
+            log.Printf("[AUTH] user %d locked after %d failed login attempts\n", user.ID, updated.FailedLoginAttempts)
+            return nil, "", "", accountLockedError(*updated.LoginLockedUntil, now)
+        }
+        return nil, "", "", ErrInvalidCredentials
     }
 
 	if !user.IsVerified {
-		return nil, "", "", errors.New("akun belum diverifikasi. silakan cek email Anda untuk memasukkan kode OTP")
+		return nil, "", "", ErrAccountNotVerified
 	}
 
-    access, err := jwt.CreateAccessToken(user.ID, string(user.Role), user.Email, user.Phone)
-    if err != nil {
-        return nil, "", "", errors.New("gagal membuat access token")
+    if err := s.repo.ResetLoginFailures(user.ID); err != nil {
+        return nil, "", "", apperror.Internal(err)
     }
 
-    refresh, err := jwt.CreateRefreshToken(user.ID)
+    access, refresh, err := s.startSession(user, meta)
     if err != nil {
-        return nil, "", "", errors.New("gagal membuat refresh token")
+        return nil, "", "", err
     }
 
     return &LoginResponse{
@@ -147,85 +222,230 @@ func (s *authService) Login(req LoginRequest) (*LoginResponse, string, string, e
     }, access, refresh, nil
 }
 
-func (s *authService) VerifyOTP(req VerifyOTPRequest) (*LoginResponse, string, string, error) {
+func (s *authService) VerifyOTP(req VerifyOTPRequest, meta SessionMeta) (*LoginResponse, string, string, error) {
 	user, err := s.repo.FindByEmail(req.Email)
 	if err != nil || user == nil {
-		return nil, "", "", errors.New("user tidak ditemukan")
+		return nil, "", "", ErrUserNotFound
 	}
 
 	if user.IsVerified {
-		return nil, "", "", errors.New("akun ini sudah terverifikasi")
+		return nil, "", "", ErrAccountAlreadyVerified
 	}
 
 	otp, err := s.repo.FindOTPByUserID(user.ID)
 	if err != nil {
-		return nil, "", "", errors.New("kode OTP tidak ditemukan atau sudah dihapus")
+		return nil, "", "", ErrOTPNotFound
+	}
+
+	now := time.Now()
+	if otp.LockedUntil != nil && now.Before(*otp.LockedUntil) {
+		return nil, "", "", otpLockedError(*otp.LockedUntil, now)
 	}
 
-	if otp.OTPCode != req.OTPCode {
-		return nil, "", "", errors.New("kode OTP salah")
+	// Hash kosong berarti kode sudah hangus karena lockout sebelumnya
+	if otp.OTPHash == "" || now.After(otp.ExpiredAt) {
+		return nil, "", "", ErrOTPExpired
 	}
 
-	if time.Now().After(otp.ExpiredAt) {
-		return nil, "", "", errors.New("kode OTP sudah kadaluarsa. silakan minta kirim ulang")
+	if !otpMatches(otp.OTPHash, req.OTPCode) {
+		updated, err := s.repo.RecordOTPFailure(otp.ID, otpMaxAttempts, otpLockoutDuration)
+		if err != nil {
+			return nil, "", "", apperror.Internal(err)
+		}
+		if updated.LockedUntil != nil {
+			return nil, "", "", otpLockedError(*updated.LockedUntil, now)
+		}
+		return nil, "", "", ErrInvalidOTP.Withf("OTP code is incorrect, %d attempts left", otpMaxAttempts-updated.FailedAttempts)
 	}
 
 	user.IsVerified = true
 	if err := s.repo.UpdateUser(user); err != nil {
-		return nil, "", "", errors.New("gagal memverifikasi akun")
+		return nil, "", "", apperror.Internal(err)
 	}
 	s.repo.DeleteOTP(user.ID)
-	access, _ := jwt.CreateAccessToken(user.ID, string(user.Role), user.Email, user.Phone)
-	refresh, _ := jwt.CreateRefreshToken(user.ID)
+
+	access, refresh, err := s.startSession(user, meta)
+	if err != nil {
+		return nil, "", "", err
+	}
 
 	return &LoginResponse{User: user}, access, refresh, nil
 }
 
-func (s *authService) ResendOTP(req ResendOTPRequest) error {
+func (s *authService) ResendOTP(req ResendOTPRequest, clientIP string) error {
 	user, err := s.repo.FindByEmail(req.Email)
 	if err != nil || user == nil {
-		return errors.New("user tidak ditemukan")
+		return ErrUserNotFound
 	}
 
 	if user.IsVerified {
-		return errors.New("akun ini sudah terverifikasi")
+		return ErrAccountAlreadyVerified
+	}
+
+	// Kode baru tidak boleh dipakai untuk memotong masa lockout
+	now := time.Now()
+	if otp, err := s.repo.FindOTPByUserID(user.ID); err == nil && otp.LockedUntil != nil && now.Before(*otp.LockedUntil) {
+		return otpLockedError(*otp.LockedUntil, now)
+	}
+
+	if err := s.checkOTPThrottle(user.Email, clientIP); err != nil {
+		return err
+	}
+
+	return s.sendOTP(user, clientIP)
+}
+
+// checkOTPThrottle menerapkan cooldown kirim ulang per email serta batas
+// harian per email dan per IP.
+func (s *authService) checkOTPThrottle(email, clientIP string) error {
+	email = normalizeEmail(email)
+	now := time.Now()
+
+	last, err := s.repo.FindLastOTPSend(email)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if last != nil && now.Sub(last.CreatedAt) < otpResendCooldown {
+		wait := otpResendCooldown - now.Sub(last.CreatedAt)
+		return ErrOTPResendCooldown.Withf("please wait %d seconds before requesting another OTP", int(wait.Seconds())+1).WithRetryAfter(wait)
+	}
+
+	since := now.Add(-24 * time.Hour)
+	sentToEmail, err := s.repo.CountOTPSendsByEmail(email, since)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if sentToEmail >= otpDailyLimitPerEmail {
+		return ErrOTPDailyLimit
 	}
 
-	newOTP := generateOTPCode()
+	if clientIP != "" {
+		sentFromIP, err := s.repo.CountOTPSendsByIP(clientIP, since)
+		if err != nil {
+			return apperror.Internal(err)
+		}
+		if sentFromIP >= otpDailyLimitPerIP {
+			return ErrOTPDailyLimit
+		}
+	}
+	return nil
+}
+
+// sendOTP membuat kode baru, menyimpan hash-nya, mencatat pengiriman, lalu
+// mengirim kode ke email user.
+func (s *authService) sendOTP(user *models.User, clientIP string) error {
+	otpCode := generateOTPCode()
 	otpData := &models.UserOTP{
 		UserID:    user.ID,
-		OTPCode:   newOTP,
-		ExpiredAt: time.Now().Add(5 * time.Minute),
+		OTPHash:   hashOTP(otpCode),
+		ExpiredAt: time.Now().Add(otpTTL),
 	}
 
 	if err := s.repo.CreateOrUpdateOTP(otpData); err != nil {
-		return errors.New("gagal membuat OTP baru")
+		return apperror.Internal(err)
+	}
+
+	if err := s.repo.CreateOTPSendLog(&models.OTPSendLog{
+		Email:     normalizeEmail(user.Email),
+		IPAddress: clientIP,
+	}); err != nil {
+		return apperror.Internal(err)
 	}
 
-	go s.mail.SendOTPEmail(user.Email, user.FullName, newOTP)
+	go s.mail.SendOTPEmail(user.Email, user.FullName, otpCode)
 
 	return nil
 }
 
-func (s *authService) Refresh(refreshToken string) (*LoginResponse, string, string, error) {
-    userID, err := jwt.ValidateRefreshToken(refreshToken)
+func otpLockedError(lockedUntil, now time.Time) error {
+	wait := lockedUntil.Sub(now)
+	minutes := int(wait.Minutes()) + 1
+	return ErrOTPLocked.Withf("too many incorrect OTP attempts, please try again in %d minutes", minutes).WithRetryAfter(wait)
+}
+
+func accountLockedError(lockedUntil, now time.Time) error {
+	wait := lockedUntil.Sub(now)
+	minutes := int(wait.Minutes()) + 1
+	return ErrAccountLocked.Withf("too many failed login attempts, please try again in %d minutes", minutes).WithRetryAfter(wait)
+}
+
+func loginLockoutFor(attempts int) time.Duration {
+	if attempts < loginFreeAttempts {
+		return 0
+	}
+
+	lockout := loginBaseLockout
+	for i := loginFreeAttempts; i < attempts && lockout < loginMaxLockout; i++ {
+		lockout *= 2
+	}
+	if lockout > loginMaxLockout {
+		lockout = loginMaxLockout
+	}
+	return lockout
+}
+
+// hashOTP memakai HMAC agar 10^6 kemungkinan kode tidak bisa ditebak offline
+// dari isi database tanpa mengetahui secret server.
+func hashOTP(code string) string {
+	secret := os.Getenv("OTP_SECRET")
+	if secret == "" {
+		secret = os.Getenv("JWT_SECRET")
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(code))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+func otpMatches(storedHash, code string) bool {
+	return subtle.ConstantTimeCompare([]byte(storedHash), []byte(hashOTP(code))) == 1
+}
+
+func normalizeEmail(email string) string {
+	return strings.ToLower(strings.TrimSpace(email))
+}
+
+// Refresh merotasi refresh token. Token lama dari sesi yang sama yang dipakai
+// lagi dianggap bocor, sehingga seluruh sesi tersebut dicabut.
+func (s *authService) Refresh(refreshToken string, meta SessionMeta) (*LoginResponse, string, string, error) {
+    claims, err := jwt.ValidateRefreshToken(refreshToken)
     if err != nil {
-        return nil, "", "", errors.New("invalid refresh token")
+        return nil, "", "", ErrInvalidRefreshToken
     }
 
-    user, err := s.repo.FindByID(userID)
-    if err != nil {
-        return nil, "", "", errors.New("user tidak ditemukan")
+    session, err := s.repo.FindSessionByID(claims.SessionID)
+    if err != nil || session.UserID != claims.UserID {
+        return nil, "", "", ErrInvalidRefreshToken
+    }
+    if !session.IsActive(time.Now()) {
+        return nil, "", "", ErrSessionRevoked
     }
 
-    access, err := jwt.CreateAccessToken(user.ID, string(user.Role), user.Email, user.Phone)
+    tokenHash := hashToken(refreshToken)
+    if session.RefreshTokenHash != tokenHash {
+        return nil, "", "", s.revokeReusedSession(session)
+    }
+
+    user, err := s.repo.FindByID(claims.UserID)
     if err != nil {
-        return nil, "", "", errors.New("gagal membuat access token")
+        return nil, "", "", ErrUserNotFound
+    }
+
+    // iat hanya presisi detik, jadi pembandingnya juga dibulatkan ke detik
+    if user.PasswordChangedAt != nil && claims.IssuedAt.Time.Before(user.PasswordChangedAt.Truncate(time.Second)) {
+        return nil, "", "", ErrInvalidRefreshToken
+    }
+
+    if meta.IPAddress != "" {
+        session.IPAddress = meta.IPAddress
+    }
+    if meta.UserAgent != "" {
+        session.UserAgent = meta.UserAgent
     }
 
-    refresh, err := jwt.CreateRefreshToken(user.ID)
+    access, refresh, err := s.issueTokens(user, session, tokenHash)
     if err != nil {
-        return nil, "", "", errors.New("gagal membuat refresh token")
+        return nil, "", "", err
     }
 
     return &LoginResponse{
@@ -236,7 +456,7 @@ func (s *authService) Refresh(refreshToken string) (*LoginResponse, string, stri
 func (s *authService) GetUserByID(id uint) (*models.User, error) {
     user, err := s.repo.FindByID(id)
     if err != nil {
-        return nil, errors.New("user tidak ditemukan")
+        return nil, ErrUserNotFound
     }
     return user, nil
 }
@@ -244,31 +464,24 @@ func (s *authService) GetUserByID(id uint) (*models.User, error) {
 func (s *authService) ChangePassword(userID uint, req ChangePasswordRequest) error {
     user, err := s.repo.FindByID(userID)
     if err != nil {
-        return errors.New("user tidak ditemukan")
+        return ErrUserNotFound
     }
 
     if !hash.CheckPassword(req.OldPassword, user.Password) {
-        return errors.New("password lama salah")
+        return ErrWrongPassword
     }
 
-    if len(req.NewPassword) < 8 {
-        return errors.New("password baru minimal 8 karakter")
-    }
-
-    hasLetter := regexp.MustCompile(`[A-Za-z]`).MatchString(req.NewPassword)
-    hasDigit := regexp.MustCompile(`\d`).MatchString(req.NewPassword)
-
-    if !hasLetter || !hasDigit {
-        return errors.New("password baru harus mengandung huruf dan angka")
+    if err := validatePasswordStrength(req.NewPassword); err != nil {
+        return err
     }
 
     hashed, err := hash.HashPassword(req.NewPassword)
     if err != nil {
-        return errors.New("gagal menghash password baru")
+        return apperror.Internal(err)
     }
 
     if err := s.repo.UpdatePassword(user.ID, hashed); err != nil {
-        return errors.New("gagal update password")
+        return apperror.Internal(err)
     }
 
     return nil
@@ -277,27 +490,27 @@ func (s *authService) ChangePassword(userID uint, req ChangePasswordRequest) err
 func (s *authService) UpdateProfile(userID uint, req UpdateProfileRequest) (*models.User, error) {
 	user, err := s.repo.FindByID(userID)
 	if err != nil {
-		return nil, errors.New("user tidak ditemukan")
+		return nil, ErrUserNotFound
 	}
 
 	if req.Username != user.Username {
 		existingUser, _ := s.repo.FindByUsername(req.Username)
 		if existingUser != nil {
-			return nil, errors.New("username sudah digunakan oleh orang lain")
+			return nil, ErrUsernameTaken
 		}
 	}
 
 	if req.Email != user.Email {
 		existingEmail, _ := s.repo.FindByEmail(req.Email)
 		if existingEmail != nil {
-			return nil, errors.New("email sudah digunakan oleh orang lain")
+			return nil, ErrEmailTaken
 		}
 	}
 
 	if req.Phone != user.Phone {
 		existingPhone, _ := s.repo.FindByPhone(req.Phone)
 		if existingPhone != nil {
-			return nil, errors.New("nomor telepon sudah digunakan oleh orang lain")
+			return nil, ErrPhoneTaken
 		}
 	}
 
@@ -307,8 +520,201 @@ func (s *authService) UpdateProfile(userID uint, req UpdateProfileRequest) (*mod
 	user.Phone = req.Phone
 
 	if err := s.repo.UpdateUser(user); err != nil {
-		return nil, errors.New("gagal memperbarui profil")
+		return nil, apperror.Internal(err)
 	}
 
 	return user, nil
-}
\ No newline at end of file
+}
+
+func validatePasswordStrength(password string) error {
+	if len(password) < 8 {
+		return ErrWeakPassword
+	}
+
+	hasLetter := regexp.MustCompile(`[A-Za-z]`).MatchString(password)
+	hasDigit := regexp.MustCompile(`\d`).MatchString(password)
+	if !hasLetter || !hasDigit {
+		return ErrWeakPassword
+	}
+	return nil
+}
+
+func generateResetToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// ForgotPassword selalu berhasil dari sisi klien, baik email terdaftar maupun
+// tidak, agar endpoint ini tidak bisa dipakai untuk menebak email user.
+func (s *authService) ForgotPassword(req ForgotPasswordRequest) error {
+	user, err := s.repo.FindByEmail(req.Email)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if user == nil || !user.IsVerified {
+		return nil
+	}
+
+	token, err := generateResetToken()
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	reset := &models.PasswordReset{
+		UserID:    user.ID,
+		TokenHash: hashToken(token),
+		ExpiredAt: time.Now().Add(passwordResetTTL),
+	}
+	if err := s.repo.CreatePasswordReset(reset); err != nil {
+		return apperror.Internal(err)
+	}
+
+	resetLink := config.AppConfig.FrontendURL + "/reset-password?token=" + token
+	go func() {
+		if err := s.mail.SendPasswordResetEmail(user.Email, user.FullName, resetLink); err != nil {
+			log.Printf("[AUTH] failed to send password reset email to user %d: %v\n", user.ID, err)
+		}
+	}()
+
+	return nil
+}
+
+func (s *authService) ResetPassword(req ResetPasswordRequest) error {
+	reset, err := s.repo.FindPasswordResetByHash(hashToken(req.Token))
+	if err != nil {
+		return err
+	}
+	if reset.UsedAt != nil {
+		return ErrInvalidResetToken
+	}
+	if time.Now().After(reset.ExpiredAt) {
+		return ErrResetTokenExpired
+	}
+
+	if err := validatePasswordStrength(req.NewPassword); err != nil {
+		return err
+	}
+
+	hashed, err := hash.HashPassword(req.NewPassword)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+
+	return s.repo.ResetPassword(reset.ID, reset.UserID, hashed)
+}
+
+// startSession membuat baris user_sessions baru lalu menerbitkan pasangan token pertamanya.
+func (s *authService) startSession(user *models.User, meta SessionMeta) (string, string, error) {
+	session := &models.UserSession{
+		UserID:     user.ID,
+		Device:     meta.Device,
+		IPAddress:  meta.IPAddress,
+		UserAgent:  meta.UserAgent,
+		LastUsedAt: time.Now(),
+		ExpiresAt:  time.Now().Add(jwt.RefreshTokenTTL),
+	}
+	if err := s.repo.CreateSession(session); err != nil {
+		return "", "", apperror.Internal(err)
+	}
+
+	return s.issueTokens(user, session, "")
+}
+
+// issueTokens menerbitkan access dan refresh token untuk session, lalu
+// menyimpan hash refresh token baru jika hash lama masih berlaku.
+func (s *authService) issueTokens(user *models.User, session *models.UserSession, oldHash string) (string, string, error) {
+	access, err := jwt.CreateAccessToken(user.ID, string(user.Role), user.Email, user.Phone, session.ID)
+	if err != nil {
+		return "", "", apperror.Internal(err)
+	}
+
+	refresh, err := jwt.CreateRefreshToken(user.ID, session.ID)
+	if err != nil {
+		return "", "", apperror.Internal(err)
+	}
+
+	now := time.Now()
+	session.RefreshTokenHash = hashToken(refresh)
+	session.LastUsedAt = now
+	session.ExpiresAt = now.Add(jwt.RefreshTokenTTL)
+
+	rotated, err := s.repo.RotateSession(session, oldHash)
+	if err != nil {
+		return "", "", apperror.Internal(err)
+	}
+	if !rotated {
+		// Request lain sudah merotasi token yang sama lebih dulu
+		return "", "", s.revokeReusedSession(session)
+	}
+
+	return access, refresh, nil
+}
+
+func (s *authService) revokeReusedSession(session *models.UserSession) error {
+	log.Printf("[AUTH] refresh token reuse detected for session %d (user %d), revoking session\n", session.ID, session.UserID)
+
+	if _, err := s.repo.RevokeSession(session.UserID, session.ID, models.SessionRevokedTokenReuse); err != nil {
+		return apperror.Internal(err)
+	}
+	return ErrRefreshTokenReused
+}
+
+func (s *authService) ListSessions(userID uint, currentSessionID uint) ([]SessionResponse, error) {
+	sessions, err := s.repo.ListActiveSessions(userID)
+	if err != nil {
+		return nil, apperror.Internal(err)
+	}
+
+	responses := []SessionResponse{}
+	for _, session := range sessions {
+		responses = append(responses, SessionResponse{
+			ID:         session.ID,
+			Device:     session.Device,
+			IPAddress:  session.IPAddress,
+			UserAgent:  session.UserAgent,
+			LastUsedAt: session.LastUsedAt,
+			CreatedAt:  session.CreatedAt,
+			ExpiresAt:  session.ExpiresAt,
+			Current:    session.ID == currentSessionID,
+		})
+	}
+	return responses, nil
+}
+
+func (s *authService) RevokeSession(userID uint, sessionID uint) error {
+	revoked, err := s.repo.RevokeSession(userID, sessionID, models.SessionRevokedByUser)
+	if err != nil {
+		return apperror.Internal(err)
+	}
+	if !revoked {
+		return ErrSessionNotFound
+	}
+	return nil
+}
+
+// Logout mencabut sesi milik access token yang dipakai. Token lama tanpa
+// session id cukup dihapus cookie-nya oleh handler.
+func (s *authService) Logout(userID uint, sessionID uint) error {
+	if sessionID == 0 {
+		return nil
+	}
+	if _, err := s.repo.RevokeSession(userID, sessionID, models.SessionRevokedLogout); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
+
+func (s *authService) LogoutAll(userID uint) error {
+	if err := s.repo.RevokeAllSessions(userID, models.SessionRevokedLogoutAll); err != nil {
+		return apperror.Internal(err)
+	}
+	return nil
+}
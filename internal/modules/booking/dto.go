@@ -6,14 +6,23 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// PassengerRequest bisa berisi data lengkap atau cukup traveler_id milik
+// traveler yang sudah disimpan user.
 type PassengerRequest struct {
-	Title          string `json:"title" validate:"required,oneof=tuan nyonya nona mr ms mrs"`
-	FullName       string `json:"full_name" validate:"required,min=2"`
-	DOB            string `json:"dob" validate:"required,datetime=2006-01-02"`
-	Nationality    string `json:"nationality" validate:"required"`
+	TravelerID     *uint  `json:"traveler_id"`
+	Title          string `json:"title" validate:"required_without=TravelerID,omitempty,oneof=tuan nyonya nona mr ms mrs"`
+	FullName       string `json:"full_name" validate:"required_without=TravelerID,omitempty,min=2"`
+	DOB            string `json:"dob" validate:"required_without=TravelerID,omitempty,datetime=2006-01-02"`
+	Nationality    string `json:"nationality" validate:"required_without=TravelerID"`
 	PassportNumber string `json:"passport_number"`
 	IssuingCountry string `json:"issuing_country"`
 	ValidUntil     string `json:"valid_until" validate:"omitempty,datetime=2006-01-02"`
+	Seats          []PassengerSeatRequest `json:"seats" validate:"omitempty,dive"`
+}
+
+type PassengerSeatRequest struct {
+	FlightLegID uint   `json:"flight_leg_id" validate:"required"`
+	SeatNumber  string `json:"seat_number" validate:"required"`
 }
 
 type BookingItemRequest struct {
@@ -23,22 +32,49 @@ type BookingItemRequest struct {
 }
 
 type CreateOrderRequest struct {
-	Items []BookingItemRequest `json:"items" validate:"required,min=1,dive"`
+	TripType  string               `json:"trip_type" validate:"omitempty,oneof=one_way round_trip multi_city"`
+	PromoCode string               `json:"promo_code"`
+	Items    []BookingItemRequest `json:"items" validate:"required,min=1,max=5,dive"`
 }
 
 type BookingDetailResponse struct {
-	BookingCode     string          `json:"booking_code"`
-	FlightCode      string          `json:"flight_code"`
-	Origin          string          `json:"origin"`
-	Destination     string          `json:"destination"`
-	DepartureTime   time.Time       `json:"departure_time"`
-	TotalPassengers int             `json:"total_passengers"`
-	TotalPrice      decimal.Decimal `json:"total_price"`
+	BookingCode     string                   `json:"booking_code"`
+	FlightCode      string                   `json:"flight_code"`
+	Origin          string                   `json:"origin"`
+	Destination     string                   `json:"destination"`
+	DepartureTime   time.Time                `json:"departure_time"`
+	TotalPassengers int                      `json:"total_passengers"`
+	TotalPrice      decimal.Decimal          `json:"total_price"`
+	PriceBreakdown  PriceBreakdownResponse   `json:"price_breakdown"`
+	Passengers      []PassengerPriceResponse `json:"passengers"`
+}
+
+type PriceBreakdownResponse struct {
+	BaseFare       decimal.Decimal `json:"base_fare"`
+	AirportTax     decimal.Decimal `json:"airport_tax"`
+	VAT            decimal.Decimal `json:"vat"`
+	Discount       decimal.Decimal `json:"discount"`
+	ConvenienceFee decimal.Decimal `json:"convenience_fee"`
+	ChangeFee      decimal.Decimal `json:"change_fee"`
+	RetainedFare   decimal.Decimal `json:"retained_fare_difference"`
+	Total          decimal.Decimal `json:"total"`
+}
+
+type PassengerPriceResponse struct {
+	FullName   string          `json:"full_name"`
+	Type       string          `json:"type"`
+	BaseFare   decimal.Decimal `json:"base_fare"`
+	AirportTax decimal.Decimal `json:"airport_tax"`
+	VAT        decimal.Decimal `json:"vat"`
+	Total      decimal.Decimal `json:"total"`
 }
 
 type BookingResponse struct {
 	OrderID         string                  `json:"order_id"`
 	TotalAmount     decimal.Decimal         `json:"total_amount"`
+	PromoCode       string                  `json:"promo_code,omitempty"`
+	DiscountAmount  decimal.Decimal         `json:"discount_amount"`
+	PriceBreakdown  PriceBreakdownResponse  `json:"price_breakdown"`
 	Status          string                  `json:"status"`
 	TransactionTime time.Time               `json:"transaction_time"`
 	ExpiryTime      *time.Time              `json:"expiry_time,omitempty"`
@@ -49,15 +85,24 @@ type PassengerDetailResponse struct {
 	Type          string `json:"type"`           
 	TicketNumber  string `json:"ticket_number"` 
 	SeatClass     string `json:"seat_class"`
+	Seats         []PassengerSeatResponse `json:"seats"`
+	CheckInStatus string     `json:"check_in_status"`
+	CheckedInAt   *time.Time `json:"checked_in_at,omitempty"`
+}
+
+type PassengerSeatResponse struct {
+	FlightLegID uint   `json:"flight_leg_id"`
+	SeatNumber  string `json:"seat_number"`
 }
 
 type MyBookingResponse struct {
-	OrderID     string          `json:"order_id"`
-	BookingCode string          `json:"booking_code"`
-	Status      string          `json:"status"`
-	TotalAmount decimal.Decimal `json:"total_amount"`
-	CreatedAt   time.Time       `json:"created_at"`
-	ExpiryTime  *time.Time      `json:"expiry_time,omitempty"`
+	OrderID        string                 `json:"order_id"`
+	BookingCode    string                 `json:"booking_code"`
+	Status         string                 `json:"status"`
+	TotalAmount    decimal.Decimal        `json:"total_amount"`
+	PriceBreakdown PriceBreakdownResponse `json:"price_breakdown"`
+	CreatedAt      time.Time              `json:"created_at"`
+	ExpiryTime     *time.Time             `json:"expiry_time,omitempty"`
 	Flight     BookingFlightDetail       `json:"flight"`
 	Passengers []PassengerDetailResponse `json:"passengers"` 
 }
@@ -75,4 +120,102 @@ type BookingFlightDetail struct {
 	TransitInfo       string  `json:"transit_info"`
 	SeatClass       string    `json:"seat_class"`
 	ClassCode       string    `json:"class_code"`
-}
\ No newline at end of file
+}
+
+type CancelOrderRequest struct {
+	Reason string `json:"reason"`
+}
+
+type CancelOrderResponse struct {
+	OrderID         string          `json:"order_id"`
+	Status          string          `json:"status"`
+	PaidAmount      decimal.Decimal `json:"paid_amount"`
+	CancellationFee decimal.Decimal `json:"cancellation_fee"`
+	RefundAmount    decimal.Decimal `json:"refund_amount"`
+	RefundStatus    string          `json:"refund_status,omitempty"`
+	CancelledAt     time.Time       `json:"cancelled_at"`
+}
+type SeatSelectionRequest struct {
+	TicketNumber string `json:"ticket_number" validate:"required"`
+	FlightLegID  uint   `json:"flight_leg_id" validate:"required"`
+	SeatNumber   string `json:"seat_number" validate:"required"`
+}
+
+type SelectSeatsRequest struct {
+	Seats []SeatSelectionRequest `json:"seats" validate:"required,min=1,dive"`
+}
+
+type CheckInRequest struct {
+	// Kosong berarti check-in semua penumpang dalam booking
+	TicketNumbers []string `json:"ticket_numbers"`
+}
+
+type RescheduleRequest struct {
+	NewFlightID uint   `json:"new_flight_id" validate:"required"`
+	SeatClass   string `json:"seat_class" validate:"omitempty,oneof=economy business first_class"`
+}
+
+type ReissuedTicketResponse struct {
+	PassengerName   string          `json:"passenger_name,omitempty"`
+	OldTicketNumber string          `json:"old_ticket_number"`
+	NewTicketNumber string          `json:"new_ticket_number,omitempty"`
+	Price           decimal.Decimal `json:"price"`
+}
+
+type BookingChangeResponse struct {
+	ChangeCode       string                   `json:"change_code,omitempty"`
+	BookingCode      string                   `json:"booking_code"`
+	Status           string                   `json:"status,omitempty"`
+	OldFlightCode    string                   `json:"old_flight_code"`
+	NewFlightCode    string                   `json:"new_flight_code"`
+	OldDepartureTime time.Time                `json:"old_departure_time"`
+	NewDepartureTime time.Time                `json:"new_departure_time"`
+	OldSeatClass     string                   `json:"old_seat_class"`
+	NewSeatClass     string                   `json:"new_seat_class"`
+	OldFareTotal     decimal.Decimal          `json:"old_fare_total"`
+	NewFareTotal     decimal.Decimal          `json:"new_fare_total"`
+	FareDifference   decimal.Decimal          `json:"fare_difference"`
+	ChangeFee        decimal.Decimal          `json:"change_fee"`
+	AmountDue        decimal.Decimal          `json:"amount_due"`
+	PaymentOrderID   string                   `json:"payment_order_id,omitempty"`
+	ExpiryTime       *time.Time               `json:"expiry_time,omitempty"`
+	CompletedAt      *time.Time               `json:"completed_at,omitempty"`
+	CreatedAt        *time.Time               `json:"created_at,omitempty"`
+	Tickets          []ReissuedTicketResponse `json:"tickets"`
+}
+
+type GuestBookingRequest struct {
+	BookingCode string `json:"booking_code" validate:"required"`
+	LastName    string `json:"last_name" validate:"required"`
+}
+
+type ManifestPassengerResponse struct {
+	No                 int     `json:"no"`
+	BookingCode        string  `json:"booking_code"`
+	BookingStatus      string  `json:"booking_status"`
+	Title              string  `json:"title"`
+	FullName           string  `json:"full_name"`
+	Type               string  `json:"type"`
+	DateOfBirth        string  `json:"date_of_birth"`
+	Nationality        string  `json:"nationality"`
+	PassportNumber     *string `json:"passport_number"`
+	IssuingCountry     *string `json:"issuing_country"`
+	PassportValidUntil *string `json:"passport_valid_until"`
+	TicketNumber       string  `json:"ticket_number"`
+	SeatClass          string  `json:"seat_class"`
+	Seat               string  `json:"seat"`
+	CheckInStatus      string  `json:"check_in_status"`
+}
+
+type ManifestResponse struct {
+	FlightID        uint                        `json:"flight_id"`
+	FlightCode      string                      `json:"flight_code"`
+	LegID           *uint                       `json:"leg_id,omitempty"`
+	FlightNumber    string                      `json:"flight_number"`
+	Route           string                      `json:"route"`
+	DepartureTime   time.Time                   `json:"departure_time"`
+	ArrivalTime     time.Time                   `json:"arrival_time"`
+	GeneratedAt     time.Time                   `json:"generated_at"`
+	TotalPassengers int                         `json:"total_passengers"`
+	Passengers      []ManifestPassengerResponse `json:"passengers"`
+}
@@ -1,12 +1,25 @@
 package booking
 
 import (
+	"ezytix-be/internal/middleware"
+	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
 	"ezytix-be/pkg/jwt"
+	"ezytix-be/pkg/validation"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+var (
+	ErrMissingOrderID       = apperror.BadRequest("MISSING_ORDER_ID", "order ID is required")
+	ErrMissingBookingCode   = apperror.BadRequest("MISSING_BOOKING_CODE", "booking code is required")
+	ErrInvalidManifestQuery = apperror.BadRequest("INVALID_MANIFEST_QUERY", "invalid manifest query")
+	ErrTooManyGuestLookups  = apperror.TooManyRequests("TOO_MANY_REQUESTS", "too many attempts, please try again later")
+)
+
 type BookingHandler struct {
 	service BookingService
 }
@@ -18,30 +31,19 @@ func NewBookingHandler(service BookingService) *BookingHandler {
 func (h *BookingHandler) CreateOrder(c *fiber.Ctx) error {
 	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
 	if !ok || userClaims == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Unauthorized: Invalid token claims",
-		})
+		return middleware.ErrUnauthorized
 	}
 
 	userID := userClaims.UserID
 
 	var req CreateOrderRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "invalid request body",
-			"error":   err.Error(),
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	resp, err := h.service.CreateOrder(userID, req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "failed to create order",
-			"error":   err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -54,21 +56,14 @@ func (h *BookingHandler) CreateOrder(c *fiber.Ctx) error {
 func (h *BookingHandler) GetMyBookings(c *fiber.Ctx) error {
 	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
 	if !ok || userClaims == nil {
-		return c.Status(fiber.StatusUnauthorized).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Unauthorized: Invalid token claims",
-		})
+		return middleware.ErrUnauthorized
 	}
 
 	userID := userClaims.UserID
 
 	bookings, err := h.service.GetUserBookings(userID)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "failed to fetch booking history",
-			"error":   err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -79,21 +74,19 @@ func (h *BookingHandler) GetMyBookings(c *fiber.Ctx) error {
 }
 
 func (h *BookingHandler) DownloadInvoice(c *fiber.Ctx) error {
+    userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+    if !ok || userClaims == nil {
+        return middleware.ErrUnauthorized
+    }
+
     orderID := c.Params("order_id")
     if orderID == "" {
-        return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-            "status":  "error",
-            "message": "Order ID is required",
-        })
+        return ErrMissingOrderID
     }
 
-    pdfBytes, err := h.service.DownloadInvoice(c.Context(), orderID)
+    pdfBytes, err := h.service.DownloadInvoice(c.Context(), userClaims, orderID)
     if err != nil {
-        return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-            "status":  "error",
-            "message": "Failed to generate invoice",
-            "error":   err.Error(),
-        })
+        return err
     }
 
     c.Set("Content-Type", "application/pdf")
@@ -103,25 +96,307 @@ func (h *BookingHandler) DownloadInvoice(c *fiber.Ctx) error {
 }
 
 func (h *BookingHandler) DownloadEticket(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
 	bookingCode := c.Params("booking_code")
 	if bookingCode == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Booking code is required",
-		})
+		return ErrMissingBookingCode
 	}
 
-	pdfBytes, err := h.service.DownloadEticket(c.Context(), bookingCode)
+	pdfBytes, err := h.service.DownloadEticket(c.Context(), userClaims, bookingCode)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Failed to generate e-ticket",
-			"error":   err.Error(),
-		})
+		return err
 	}
 
 	c.Set("Content-Type", "application/pdf")
 	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=Eticket-%s.pdf", bookingCode))
 
 	return c.Send(pdfBytes)
-}
\ No newline at end of file
+}
+
+func (h *BookingHandler) CancelOrder(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	orderID := c.Params("order_id")
+	if orderID == "" {
+		return ErrMissingOrderID
+	}
+
+	var req CancelOrderRequest
+	if len(c.Body()) > 0 {
+		if err := validation.ParseBody(c, &req); err != nil {
+			return err
+		}
+	}
+
+	resp, err := h.service.CancelOrder(userClaims.UserID, orderID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "order cancelled successfully",
+		"data":    resp,
+	})
+}
+func (h *BookingHandler) SelectSeats(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	bookingCode := c.Params("booking_code")
+	if bookingCode == "" {
+		return ErrMissingBookingCode
+	}
+
+	var req SelectSeatsRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.service.SelectSeats(userClaims.UserID, bookingCode, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "seats selected successfully",
+		"data":    resp,
+	})
+}
+
+func (h *BookingHandler) QuoteReschedule(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	bookingCode := c.Params("booking_code")
+	if bookingCode == "" {
+		return ErrMissingBookingCode
+	}
+
+	var req RescheduleRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.service.QuoteReschedule(userClaims.UserID, bookingCode, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "reschedule quoted successfully",
+		"data":    resp,
+	})
+}
+
+func (h *BookingHandler) RescheduleBooking(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	bookingCode := c.Params("booking_code")
+	if bookingCode == "" {
+		return ErrMissingBookingCode
+	}
+
+	var req RescheduleRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.service.RescheduleBooking(userClaims.UserID, bookingCode, req)
+	if err != nil {
+		return err
+	}
+
+	message := "booking rescheduled successfully"
+	if resp.Status == models.BookingChangeStatusPendingPayment {
+		message = "reschedule created, waiting for payment"
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"status":  "success",
+		"message": message,
+		"data":    resp,
+	})
+}
+
+func (h *BookingHandler) GetOrderChanges(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	orderID := c.Params("order_id")
+	if orderID == "" {
+		return ErrMissingOrderID
+	}
+
+	changes, err := h.service.GetOrderChanges(userClaims.UserID, orderID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "successfully fetched booking changes",
+		"data":    changes,
+	})
+}
+
+func (h *BookingHandler) LookupGuestBooking(c *fiber.Ctx) error {
+	var req GuestBookingRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.service.LookupGuestBooking(req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "successfully fetched booking",
+		"data":    resp,
+	})
+}
+
+func (h *BookingHandler) DownloadGuestEticket(c *fiber.Ctx) error {
+	var req GuestBookingRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	pdfBytes, err := h.service.DownloadGuestEticket(c.Context(), req)
+	if err != nil {
+		return err
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=Eticket-%s.pdf", strings.ToUpper(req.BookingCode)))
+
+	return c.Send(pdfBytes)
+}
+
+func (h *BookingHandler) CheckIn(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	bookingCode := c.Params("booking_code")
+	if bookingCode == "" {
+		return ErrMissingBookingCode
+	}
+
+	var req CheckInRequest
+	if len(c.Body()) > 0 {
+		if err := validation.ParseBody(c, &req); err != nil {
+			return err
+		}
+	}
+
+	resp, err := h.service.CheckIn(userClaims.UserID, bookingCode, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "check-in successful",
+		"data":    resp,
+	})
+}
+
+func (h *BookingHandler) DownloadBoardingPass(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	bookingCode := c.Params("booking_code")
+	if bookingCode == "" {
+		return ErrMissingBookingCode
+	}
+
+	ticketNumber := c.Query("ticket_number")
+
+	pdfBytes, err := h.service.DownloadBoardingPass(c.Context(), userClaims, bookingCode, ticketNumber)
+	if err != nil {
+		return err
+	}
+
+	c.Set("Content-Type", "application/pdf")
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=BoardingPass-%s.pdf", bookingCode))
+
+	return c.Send(pdfBytes)
+}
+
+func (h *BookingHandler) ExportManifest(c *fiber.Ctx) error {
+	flightID, err := strconv.ParseUint(c.Params("flight_id"), 10, 32)
+	if err != nil {
+		return ErrInvalidManifestQuery.Withf("invalid flight id")
+	}
+
+	var legID uint64
+	if raw := c.Query("leg_id"); raw != "" {
+		legID, err = strconv.ParseUint(raw, 10, 32)
+		if err != nil {
+			return ErrInvalidManifestQuery.Withf("invalid leg id")
+		}
+	}
+
+	format := strings.ToLower(c.Query("format", "json"))
+	fileName := fmt.Sprintf("Manifest-%d", flightID)
+	if legID != 0 {
+		fileName = fmt.Sprintf("%s-leg-%d", fileName, legID)
+	}
+
+	var (
+		content     []byte
+		contentType string
+	)
+	switch format {
+	case "json":
+		manifest, err := h.service.GetFlightManifest(uint(flightID), uint(legID))
+		if err != nil {
+			return err
+		}
+		return c.Status(fiber.StatusOK).JSON(fiber.Map{
+			"status":  "success",
+			"message": "manifest retrieved successfully",
+			"data":    manifest,
+		})
+	case "csv":
+		content, err = h.service.ExportManifestCSV(uint(flightID), uint(legID))
+		contentType = "text/csv"
+	case "pdf":
+		content, err = h.service.ExportManifestPDF(c.Context(), uint(flightID), uint(legID))
+		contentType = "application/pdf"
+	default:
+		return ErrInvalidManifestQuery.Withf("format must be one of json, csv or pdf")
+	}
+	if err != nil {
+		return err
+	}
+
+	c.Set("Content-Type", contentType)
+	c.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%s.%s", fileName, format))
+
+	return c.Send(content)
+}
@@ -2,19 +2,45 @@ package booking
 
 import (
 	"errors"
-	"fmt"
 	"time"
 
 	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
 
 	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
-var ErrBookingAlreadyCancelled = errors.New("booking already cancelled by scheduler")
+var (
+	ErrBookingNotFound         = apperror.NotFound("BOOKING_NOT_FOUND", "booking not found")
+	ErrBookingAlreadyCancelled = apperror.Conflict("BOOKING_ALREADY_CANCELLED", "booking already cancelled by scheduler")
+	ErrBookingStatusChanged    = apperror.Conflict("BOOKING_STATUS_CHANGED", "booking status changed, please try again")
+	ErrInvalidBookingStatus    = apperror.Conflict("INVALID_BOOKING_STATUS", "booking status does not allow this action")
+	ErrSeatUnavailable         = apperror.Conflict("SEAT_UNAVAILABLE", "seat is not available")
+	ErrInsufficientStock       = apperror.InsufficientStock("INSUFFICIENT_STOCK", "not enough seats left on this flight")
+	ErrPromoUnavailable        = apperror.Conflict("PROMO_UNAVAILABLE", "promo code is no longer available")
+	ErrBookingChangePending    = apperror.Conflict("RESCHEDULE_PENDING", "booking already has a reschedule waiting for payment")
+	ErrBookingChangeClosed     = apperror.Conflict("RESCHEDULE_CLOSED", "reschedule is no longer waiting for payment")
+	ErrGuestBookingNotFound    = apperror.NotFound("GUEST_BOOKING_NOT_FOUND", "no booking matches this booking code and last name")
+	ErrCheckInClosed           = apperror.Conflict("CHECK_IN_CLOSED", "check-in is not open for this flight")
+	ErrNotCheckedIn            = apperror.Conflict("NOT_CHECKED_IN", "passenger has not checked in")
+	ErrManifestNotFound        = apperror.NotFound("MANIFEST_NOT_FOUND", "flight or leg not found")
+)
+
+// SeatAssignment menghubungkan satu penumpang dengan kursi pada satu leg.
+// Saat CreateOrder, BookingIndex/DetailIndex dipakai karena ID detail belum ada.
+type SeatAssignment struct {
+	BookingIndex    int
+	DetailIndex     int
+	BookingDetailID uint
+	FlightLegID     uint
+	SeatNumber      string
+	SeatClass       string
+}
 
 type BookingRepository interface {
-	CreateOrder(bookings []models.Booking) error
+	CreateOrder(bookings []models.Booking, seats []SeatAssignment, redemption *models.PromoRedemption) error
 	GetBookingByOrderID(orderID string) (*models.Booking, error)
 	FindBookingsByOrderID(orderID string) ([]models.Booking, error)
 	UpdateBookingStatus(orderID string, status string) error
@@ -26,7 +52,20 @@ type BookingRepository interface {
 	GetBookingForInvoice(bookingCode string) (*models.Booking, error)
 	GetPaymentByOrderID(orderID string) (*models.Payment, error)
 	GetBookingForTicket(bookingCode string) (*models.Booking, error)
+	GetBookingByCode(bookingCode string) (*models.Booking, error)
 	GetBookingsForInvoiceByOrderID(orderID string) ([]models.Booking, error)
+	CancelOrderAtomic(bookings []models.Booking, refund *models.Refund) error
+	UpdateRefundStatus(refundID uint, status string, failureReason string) error
+	AssignSeats(seats []SeatAssignment) error
+	SetConvenienceFee(orderID string, fee decimal.Decimal) error
+	CreateBookingChange(change *models.BookingChange, passengerCount int) error
+	CompleteBookingChange(changeCode string) error
+	ExpireBookingChange(change *models.BookingChange) error
+	GetBookingChangeByCode(changeCode string) (*models.BookingChange, error)
+	GetBookingChangesByOrderID(orderID string) ([]models.BookingChange, error)
+	GetExpiredBookingChanges(currentTime time.Time) ([]models.BookingChange, error)
+	CheckInPassengers(flightID uint, detailIDs []uint) error
+	GetBookingsForManifest(flightID uint, legID uint) ([]models.Booking, error)
 }
 
 type bookingRepository struct {
@@ -59,13 +98,47 @@ func (r *bookingRepository) GetBookingByOrderID(orderID string) (*models.Booking
 	return &booking, nil
 }
 
+// SetConvenienceFee menyimpan biaya layanan metode pembayaran pada booking
+// pertama dalam order, menggantikan biaya sebelumnya (jika user ganti metode).
+func (r *bookingRepository) SetConvenienceFee(orderID string, fee decimal.Decimal) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var bookings []models.Booking
+		if err := tx.Where("order_id = ?", orderID).Order("id ASC").Find(&bookings).Error; err != nil {
+			return err
+		}
+		if len(bookings) == 0 {
+			return gorm.ErrRecordNotFound
+		}
+
+		for i, booking := range bookings {
+			newFee := decimal.Zero
+			if i == 0 {
+				newFee = fee
+			}
+			if booking.ConvenienceFee.Equal(newFee) {
+				continue
+			}
+
+			newTotal := booking.TotalPrice.Sub(booking.ConvenienceFee).Add(newFee)
+			if err := tx.Model(&models.Booking{}).Where("id = ?", booking.ID).
+				Updates(map[string]interface{}{
+					"convenience_fee": newFee,
+					"total_price":     newTotal,
+				}).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
 func (r *bookingRepository) UpdateBookingExpiry(orderID string, newExpiry time.Time) error {
 	return r.db.Model(&models.Booking{}).
 		Where("order_id = ?", orderID).
 		Update("expired_at", newExpiry).Error
 }
 
-func (r *bookingRepository) CreateOrder(bookings []models.Booking) error {
+func (r *bookingRepository) CreateOrder(bookings []models.Booking, seats []SeatAssignment, redemption *models.PromoRedemption) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		for i := range bookings {
 			booking := &bookings[i]
@@ -75,7 +148,7 @@ func (r *bookingRepository) CreateOrder(bookings []models.Booking) error {
 			}
 
 			seatClass := booking.Details[0].SeatClass
-			passengerCount := len(booking.Details)
+			passengerCount := seatedPassengerCount(booking.Details)
 
 			result := tx.Model(&models.FlightClass{}).
 				Where("flight_id = ? AND seat_class = ? AND total_seats >= ?",
@@ -87,21 +160,179 @@ func (r *bookingRepository) CreateOrder(bookings []models.Booking) error {
 			}
 
 			if result.RowsAffected == 0 {
-				return fmt.Errorf("insufficient stock for flight ID %d class %s", booking.FlightID, seatClass)
+				return ErrInsufficientStock.Withf("not enough %s seats left on flight ID %d", seatClass, booking.FlightID)
+			}
+
+			if err := adjustLegInventory(tx, booking.FlightID, seatClass, -passengerCount); err != nil {
+				return err
 			}
 
 			if err := tx.Create(booking).Error; err != nil {
 				return err
 			}
 		}
+
+		for _, seat := range seats {
+			seat.BookingDetailID = bookings[seat.BookingIndex].Details[seat.DetailIndex].ID
+			if err := assignSeat(tx, seat); err != nil {
+				return err
+			}
+		}
+
+		if redemption != nil {
+			if err := redeemPromo(tx, redemption); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func (r *bookingRepository) AssignSeats(seats []SeatAssignment) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for _, seat := range seats {
+			if err := assignSeat(tx, seat); err != nil {
+				return err
+			}
+		}
 		return nil
 	})
 }
 
+func assignSeat(tx *gorm.DB, seat SeatAssignment) error {
+	// Lepas kursi lama penumpang di leg yang sama sebelum mengambil yang baru
+	if err := tx.Model(&models.FlightSeat{}).
+		Where(models.FlightSeatOnLeg+" AND booking_detail_id = ?", seat.FlightLegID, seat.BookingDetailID).
+		Update("booking_detail_id", nil).Error; err != nil {
+		return err
+	}
+
+	result := tx.Model(&models.FlightSeat{}).
+		Where(models.FlightSeatOnLeg+" AND seat_number = ? AND seat_class = ? AND is_blocked = ? AND booking_detail_id IS NULL",
+			seat.FlightLegID, seat.SeatNumber, seat.SeatClass, false).
+		Update("booking_detail_id", seat.BookingDetailID)
+
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrSeatUnavailable.Withf("seat %s is not available", seat.SeatNumber)
+	}
+	return nil
+}
+
+// adjustLegInventory mengubah stok semua leg milik flight sebesar delta. Untuk
+// pengurangan, setiap leg wajib punya stok cukup; jika tidak, transaksi batal.
+func adjustLegInventory(tx *gorm.DB, flightID uint, seatClass string, delta int) error {
+	var legCount int64
+	if err := tx.Model(&models.FlightLeg{}).Where("flight_id = ?", flightID).Count(&legCount).Error; err != nil {
+		return err
+	}
+	if legCount == 0 {
+		return nil
+	}
+
+	query := `
+		UPDATE leg_inventories li
+		SET available_seats = li.available_seats + ?, updated_at = ?
+		FROM flight_legs fl
+		WHERE fl.flight_id = ? AND li.seat_class = ? AND ` + models.LegInventoryJoin
+	args := []interface{}{delta, time.Now(), flightID, seatClass}
+
+	if delta < 0 {
+		query += " AND li.available_seats >= ?"
+		args = append(args, -delta)
+	}
+
+	result := tx.Exec(query, args...)
+	if result.Error != nil {
+		return result.Error
+	}
+
+	if delta < 0 && result.RowsAffected != legCount {
+		return ErrInsufficientStock.Withf("not enough %s seats left on one of the legs of flight ID %d", seatClass, flightID)
+	}
+	return nil
+}
+
+// redeemPromo mengambil kuota promo dan mencatat pemakaiannya. Update bersyarat
+// pada baris promotions sekaligus mengunci baris tersebut, sehingga pengecekan
+// batas per user di bawahnya aman dari race condition.
+func redeemPromo(tx *gorm.DB, redemption *models.PromoRedemption) error {
+	result := tx.Model(&models.Promotion{}).
+		Where("id = ? AND is_active = ? AND (usage_limit = 0 OR used_count < usage_limit)", redemption.PromotionID, true).
+		Update("used_count", gorm.Expr("used_count + 1"))
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return ErrPromoUnavailable
+	}
+
+	var promotion models.Promotion
+	if err := tx.First(&promotion, redemption.PromotionID).Error; err != nil {
+		return err
+	}
+
+	if promotion.PerUserLimit > 0 {
+		var used int64
+		if err := tx.Model(&models.PromoRedemption{}).
+			Where("promotion_id = ? AND user_id = ? AND status = ?", promotion.ID, redemption.UserID, models.PromoRedemptionStatusActive).
+			Count(&used).Error; err != nil {
+			return err
+		}
+		if used >= int64(promotion.PerUserLimit) {
+			return ErrPromoUnavailable
+		}
+	}
+
+	return tx.Create(redemption).Error
+}
+
+// releasePromoRedemption mengembalikan kuota promo milik order yang batal/expired.
+func releasePromoRedemption(tx *gorm.DB, orderID string) error {
+	now := time.Now()
+	result := tx.Model(&models.PromoRedemption{}).
+		Where("order_id = ? AND status = ?", orderID, models.PromoRedemptionStatusActive).
+		Updates(map[string]interface{}{
+			"status":      models.PromoRedemptionStatusReleased,
+			"released_at": &now,
+		})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return nil
+	}
+
+	return tx.Model(&models.Promotion{}).
+		Where("id = (?) AND used_count > 0", tx.Model(&models.PromoRedemption{}).Select("promotion_id").Where("order_id = ?", orderID)).
+		Update("used_count", gorm.Expr("used_count - 1")).Error
+}
+
+// seatedPassengerCount menghitung penumpang yang memakai kursi. Bayi dipangku
+// sehingga tidak mengurangi stok.
+func seatedPassengerCount(details []models.BookingDetail) int {
+	count := 0
+	for _, detail := range details {
+		if detail.PassengerType != models.PassengerTypeInfant {
+			count++
+		}
+	}
+	return count
+}
+
+func releaseSeats(tx *gorm.DB, bookingID uint) error {
+	return tx.Model(&models.FlightSeat{}).
+		Where("booking_detail_id IN (?)", tx.Model(&models.BookingDetail{}).Select("id").Where("booking_id = ?", bookingID)).
+		Update("booking_detail_id", nil).Error
+}
+
 func (r *bookingRepository) FindBookingsByOrderID(orderID string) ([]models.Booking, error) {
 	var bookings []models.Booking
 	err := r.db.Preload("Details").Preload("Flight").
 		Where("order_id = ?", orderID).
+		Order("id ASC").
 		Find(&bookings).Error
 	return bookings, err
 }
@@ -150,13 +381,25 @@ func (r *bookingRepository) CancelBookingAtomic(booking *models.Booking) error {
 
 		if len(booking.Details) > 0 {
 			seatClass := booking.Details[0].SeatClass
-			passengerCount := len(booking.Details)
+			passengerCount := seatedPassengerCount(booking.Details)
 
 			if err := tx.Model(&models.FlightClass{}).
 				Where("flight_id = ? AND seat_class = ?", booking.FlightID, seatClass).
 				Update("total_seats", gorm.Expr("total_seats + ?", passengerCount)).Error; err != nil {
 				return err
 			}
+
+			if err := adjustLegInventory(tx, booking.FlightID, seatClass, passengerCount); err != nil {
+				return err
+			}
+		}
+
+		if err := releaseSeats(tx, booking.ID); err != nil {
+			return err
+		}
+
+		if err := releasePromoRedemption(tx, booking.OrderID); err != nil {
+			return err
 		}
 		return nil
 	})
@@ -170,7 +413,9 @@ func (r *bookingRepository) GetByUserID(userID uint) ([]models.Booking, error) {
 		Preload("Flight.OriginAirport").
 		Preload("Flight.DestinationAirport").
 		Preload("Flight.FlightClasses").
+		Preload("Flight.FlightLegs").
 		Preload("Details").
+		Preload("Details.Seats").
 		Where("user_id = ?", userID).
 		Order("created_at DESC").
 		Find(&bookings).Error
@@ -232,6 +477,8 @@ func (r *bookingRepository) GetBookingForTicket(bookingCode string) (*models.Boo
 	err := r.db.
 		Preload("User").
 		Preload("Details").
+		Preload("Details.Seats").
+		Preload("Details.BoardingSequences").
 		Preload("Flight").
 		Preload("Flight.FlightLegs").
 		Preload("Flight.FlightLegs.Airline").
@@ -246,6 +493,25 @@ func (r *bookingRepository) GetBookingForTicket(bookingCode string) (*models.Boo
 	return &booking, nil
 }
 
+func (r *bookingRepository) GetBookingByCode(bookingCode string) (*models.Booking, error) {
+	var booking models.Booking
+	err := r.db.
+		Preload("Flight").
+		Preload("Flight.Airline").
+		Preload("Flight.OriginAirport").
+		Preload("Flight.DestinationAirport").
+		Preload("Flight.FlightClasses").
+		Preload("Details").
+		Preload("Details.Seats").
+		Where("booking_code = ?", bookingCode).
+		First(&booking).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return &booking, nil
+}
+
 func (r *bookingRepository) GetBookingsForInvoiceByOrderID(orderID string) ([]models.Booking, error) {
     var bookings []models.Booking
 
@@ -264,4 +530,405 @@ func (r *bookingRepository) GetBookingsForInvoiceByOrderID(orderID string) ([]mo
         return nil, err
     }
     return bookings, nil
-}
\ No newline at end of file
+}
+
+// GetBookingsForManifest memakai preload yang sama dengan invoice, ditambah
+// kursi penumpang. Booking yang dibatalkan tidak masuk manifest. Jika legID
+// diisi, booking diambil dari semua flight yang memakai leg fisik yang sama,
+// karena penumpangnya naik pesawat yang sama.
+func (r *bookingRepository) GetBookingsForManifest(flightID uint, legID uint) ([]models.Booking, error) {
+	var bookings []models.Booking
+
+	query := r.db.Where("flight_id = ?", flightID)
+	if legID != 0 {
+		query = r.db.Where("flight_id IN (?)", r.db.Table("flight_legs fl").
+			Select("fl.flight_id").
+			Joins("JOIN flight_legs selected ON selected.flight_number = fl.flight_number AND selected.origin_airport_id = fl.origin_airport_id AND selected.departure_time = fl.departure_time").
+			Where("selected.id = ?", legID))
+	}
+
+	err := query.
+		Preload("User").
+		Preload("Details", func(db *gorm.DB) *gorm.DB {
+			return db.Order("booking_details.passenger_name ASC")
+		}).
+		Preload("Details.Seats").
+		Preload("Flight").
+		Preload("Flight.FlightLegs").
+		Preload("Flight.FlightLegs.Airline").
+		Preload("Flight.FlightLegs.OriginAirport").
+		Preload("Flight.FlightLegs.DestinationAirport").
+		Where("status <> ?", models.BookingStatusCancelled).
+		Order("booking_code ASC").
+		Find(&bookings).Error
+
+	if err != nil {
+		return nil, err
+	}
+	return bookings, nil
+}
+
+func (r *bookingRepository) CancelOrderAtomic(bookings []models.Booking, refund *models.Refund) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		for i := range bookings {
+			booking := &bookings[i]
+
+			result := tx.Model(&models.Booking{}).
+				Where("id = ? AND status = ?", booking.ID, booking.Status).
+				Update("status", models.BookingStatusCancelled)
+
+			if result.Error != nil {
+				return result.Error
+			}
+
+			if result.RowsAffected == 0 {
+				return ErrBookingStatusChanged
+			}
+
+			// Reschedule yang menunggu pembayaran harus dibayar atau kedaluwarsa
+			// dulu, jika tidak pembayarannya tidak bisa diterapkan lagi
+			var pendingChanges int64
+			if err := tx.Model(&models.BookingChange{}).
+				Where("booking_id = ? AND status = ?", booking.ID, models.BookingChangeStatusPendingPayment).
+				Count(&pendingChanges).Error; err != nil {
+				return err
+			}
+			if pendingChanges > 0 {
+				return ErrBookingChangePending.Withf("booking %s has a reschedule waiting for payment and cannot be cancelled yet", booking.BookingCode)
+			}
+
+			if len(booking.Details) > 0 {
+				seatClass := booking.Details[0].SeatClass
+				passengerCount := seatedPassengerCount(booking.Details)
+
+				if err := tx.Model(&models.FlightClass{}).
+					Where("flight_id = ? AND seat_class = ?", booking.FlightID, seatClass).
+					Update("total_seats", gorm.Expr("total_seats + ?", passengerCount)).Error; err != nil {
+					return err
+				}
+
+				if err := adjustLegInventory(tx, booking.FlightID, seatClass, passengerCount); err != nil {
+					return err
+				}
+			}
+
+			if err := releaseSeats(tx, booking.ID); err != nil {
+				return err
+			}
+
+			booking.Status = models.BookingStatusCancelled
+		}
+
+		if len(bookings) > 0 {
+			if err := releasePromoRedemption(tx, bookings[0].OrderID); err != nil {
+				return err
+			}
+		}
+
+		if refund != nil {
+			if err := tx.Create(refund).Error; err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func (r *bookingRepository) UpdateRefundStatus(refundID uint, status string, failureReason string) error {
+	now := time.Now()
+
+	return r.db.Model(&models.Refund{}).
+		Where("id = ?", refundID).
+		Updates(map[string]interface{}{
+			"status":         status,
+			"failure_reason": failureReason,
+			"processed_at":   &now,
+			"updated_at":     now,
+		}).Error
+}
+
+// CreateBookingChange menahan kursi di penerbangan baru lalu menyimpan
+// perubahan. Kursi penerbangan lama baru dilepas saat perubahan selesai.
+func (r *bookingRepository) CreateBookingChange(change *models.BookingChange, passengerCount int) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		// Mengunci booking agar tidak bisa dibatalkan bersamaan
+		var booking models.Booking
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&booking, change.BookingID).Error; err != nil {
+			return err
+		}
+		if booking.Status != models.BookingStatusPaid {
+			return ErrInvalidBookingStatus.Withf("booking %s is %s and cannot be rescheduled", booking.BookingCode, booking.Status)
+		}
+
+		var pending int64
+		if err := tx.Model(&models.BookingChange{}).
+			Where("booking_id = ? AND status = ?", change.BookingID, models.BookingChangeStatusPendingPayment).
+			Count(&pending).Error; err != nil {
+			return err
+		}
+		if pending > 0 {
+			return ErrBookingChangePending
+		}
+
+		result := tx.Model(&models.FlightClass{}).
+			Where("flight_id = ? AND seat_class = ? AND total_seats >= ?",
+				change.NewFlightID, change.NewSeatClass, passengerCount).
+			Update("total_seats", gorm.Expr("total_seats - ?", passengerCount))
+
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrInsufficientStock.Withf("not enough %s seats left on flight ID %d", change.NewSeatClass, change.NewFlightID)
+		}
+
+		if err := adjustLegInventory(tx, change.NewFlightID, change.NewSeatClass, -passengerCount); err != nil {
+			return err
+		}
+
+		return tx.Omit("OldFlight", "NewFlight").Create(change).Error
+	})
+}
+
+// CompleteBookingChange memindahkan booking ke penerbangan baru: stok lama
+// dikembalikan, kursi lama dilepas, dan tiket penumpang diterbitkan ulang.
+// Aman dipanggil ulang (misalnya webhook duplikat) setelah selesai.
+func (r *bookingRepository) CompleteBookingChange(changeCode string) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var change models.BookingChange
+		if err := tx.Preload("Passengers").Where("change_code = ?", changeCode).First(&change).Error; err != nil {
+			return err
+		}
+
+		if change.Status == models.BookingChangeStatusCompleted {
+			return nil
+		}
+		if change.Status != models.BookingChangeStatusPendingPayment {
+			return ErrBookingChangeClosed
+		}
+
+		now := time.Now()
+		result := tx.Model(&models.BookingChange{}).
+			Where("id = ? AND status = ?", change.ID, models.BookingChangeStatusPendingPayment).
+			Updates(map[string]interface{}{
+				"status":       models.BookingChangeStatusCompleted,
+				"completed_at": &now,
+				"updated_at":   now,
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrBookingStatusChanged
+		}
+
+		var booking models.Booking
+		if err := tx.Preload("Details").First(&booking, change.BookingID).Error; err != nil {
+			return err
+		}
+		if booking.Status != models.BookingStatusPaid {
+			return ErrInvalidBookingStatus.Withf("booking %s is %s and cannot be rescheduled", booking.BookingCode, booking.Status)
+		}
+
+		passengerCount := seatedPassengerCount(booking.Details)
+		if err := tx.Model(&models.FlightClass{}).
+			Where("flight_id = ? AND seat_class = ?", change.OldFlightID, change.OldSeatClass).
+			Update("total_seats", gorm.Expr("total_seats + ?", passengerCount)).Error; err != nil {
+			return err
+		}
+		if err := adjustLegInventory(tx, change.OldFlightID, change.OldSeatClass, passengerCount); err != nil {
+			return err
+		}
+		if err := releaseSeats(tx, booking.ID); err != nil {
+			return err
+		}
+
+		baseFare, airportTax, vat := decimal.Zero, decimal.Zero, decimal.Zero
+		for _, passenger := range change.Passengers {
+			if err := tx.Model(&models.BookingDetail{}).
+				Where("id = ? AND booking_id = ?", passenger.BookingDetailID, booking.ID).
+				Updates(map[string]interface{}{
+					"ticket_number": passenger.NewTicketNumber,
+					"seat_class":    change.NewSeatClass,
+					"base_fare":     passenger.BaseFare,
+					"airport_tax":   passenger.AirportTax,
+					"vat_amount":    passenger.VATAmount,
+					"price":         passenger.Price,
+					"updated_at":    now,
+				}).Error; err != nil {
+				return err
+			}
+			baseFare = baseFare.Add(passenger.BaseFare)
+			airportTax = airportTax.Add(passenger.AirportTax)
+			vat = vat.Add(passenger.VATAmount)
+		}
+
+		// Check-in di penerbangan lama tidak berlaku lagi
+		if err := tx.Model(&models.BookingDetail{}).
+			Where("booking_id = ?", booking.ID).
+			Updates(map[string]interface{}{
+				"check_in_status": models.CheckInStatusNotCheckedIn,
+				"checked_in_at":   nil,
+			}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("booking_detail_id IN (?)", tx.Model(&models.BookingDetail{}).Select("id").Where("booking_id = ?", booking.ID)).
+			Delete(&models.LegBoardingSequence{}).Error; err != nil {
+			return err
+		}
+
+		// Tarif baru menggantikan rincian lama. Biaya reschedule dan selisih
+		// tarif yang tidak di-refund dicatat terpisah sehingga rincian tetap
+		// berjumlah total_price.
+		retained := booking.RetainedFareDifference
+		if change.FareDifference.IsNegative() {
+			retained = retained.Add(change.FareDifference.Neg())
+		}
+
+		return tx.Model(&models.Booking{}).
+			Where("id = ?", booking.ID).
+			Updates(map[string]interface{}{
+				"flight_id":                change.NewFlightID,
+				"base_fare":                baseFare,
+				"airport_tax":              airportTax,
+				"vat_amount":               vat,
+				"change_fee":               booking.ChangeFee.Add(change.ChangeFee),
+				"retained_fare_difference": retained,
+				"total_price":              booking.TotalPrice.Add(change.AmountDue),
+				"updated_at":               now,
+			}).Error
+	})
+}
+
+// ExpireBookingChange menutup reschedule yang tidak dibayar dan mengembalikan
+// kursi yang ditahan di penerbangan baru.
+func (r *bookingRepository) ExpireBookingChange(change *models.BookingChange) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Model(&models.BookingChange{}).
+			Where("id = ? AND status = ?", change.ID, models.BookingChangeStatusPendingPayment).
+			Updates(map[string]interface{}{
+				"status":     models.BookingChangeStatusExpired,
+				"updated_at": time.Now(),
+			})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return ErrBookingChangeClosed
+		}
+
+		var details []models.BookingDetail
+		if err := tx.Where("booking_id = ?", change.BookingID).Find(&details).Error; err != nil {
+			return err
+		}
+		passengerCount := seatedPassengerCount(details)
+
+		if err := tx.Model(&models.FlightClass{}).
+			Where("flight_id = ? AND seat_class = ?", change.NewFlightID, change.NewSeatClass).
+			Update("total_seats", gorm.Expr("total_seats + ?", passengerCount)).Error; err != nil {
+			return err
+		}
+
+		change.Status = models.BookingChangeStatusExpired
+		return adjustLegInventory(tx, change.NewFlightID, change.NewSeatClass, passengerCount)
+	})
+}
+
+func (r *bookingRepository) GetBookingChangeByCode(changeCode string) (*models.BookingChange, error) {
+	var change models.BookingChange
+	if err := r.db.Preload("Passengers").Where("change_code = ?", changeCode).First(&change).Error; err != nil {
+		return nil, err
+	}
+	return &change, nil
+}
+
+func (r *bookingRepository) GetBookingChangesByOrderID(orderID string) ([]models.BookingChange, error) {
+	var changes []models.BookingChange
+	err := r.db.
+		Preload("Passengers").
+		Preload("OldFlight").
+		Preload("NewFlight").
+		Where("order_id = ?", orderID).
+		Order("created_at ASC").
+		Find(&changes).Error
+	return changes, err
+}
+
+func (r *bookingRepository) GetExpiredBookingChanges(currentTime time.Time) ([]models.BookingChange, error) {
+	var changes []models.BookingChange
+	err := r.db.
+		Where("status = ? AND expired_at < ?", models.BookingChangeStatusPendingPayment, currentTime).
+		Find(&changes).Error
+	return changes, err
+}
+
+// CheckInPassengers menandai penumpang sudah check-in dan memberi nomor urut
+// boarding. Baris flight dikunci agar nomor urut tidak dobel antar transaksi.
+func (r *bookingRepository) CheckInPassengers(flightID uint, detailIDs []uint) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		var legs []models.FlightLeg
+		if err := tx.Where("flight_id = ?", flightID).Order("leg_order ASC").Find(&legs).Error; err != nil {
+			return err
+		}
+
+		// Nomor urut dihitung per leg fisik. Stok leg dikunci agar check-in dari
+		// flight lain yang berbagi leg menunggu giliran.
+		lastSequences := make([]int, len(legs))
+		if len(legs) > 0 {
+			keys := make([][]interface{}, 0, len(legs))
+			for _, leg := range legs {
+				keys = append(keys, []interface{}{leg.FlightNumber, leg.OriginAirportID, leg.DepartureTime})
+			}
+			var inventories []models.LegInventory
+			if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+				Where("(flight_number, origin_airport_id, departure_time) IN ?", keys).
+				Order("id ASC").
+				Find(&inventories).Error; err != nil {
+				return err
+			}
+
+			for i, leg := range legs {
+				if err := tx.Model(&models.LegBoardingSequence{}).
+					Where("flight_number = ? AND origin_airport_id = ? AND departure_time = ?", leg.FlightNumber, leg.OriginAirportID, leg.DepartureTime).
+					Select("COALESCE(MAX(sequence), 0)").
+					Scan(&lastSequences[i]).Error; err != nil {
+					return err
+				}
+			}
+		}
+
+		now := time.Now()
+		for _, detailID := range detailIDs {
+			result := tx.Model(&models.BookingDetail{}).
+				Where("id = ? AND check_in_status = ?", detailID, models.CheckInStatusNotCheckedIn).
+				Updates(map[string]interface{}{
+					"check_in_status": models.CheckInStatusCheckedIn,
+					"checked_in_at":   &now,
+					"updated_at":      now,
+				})
+			if result.Error != nil {
+				return result.Error
+			}
+			// Penumpang yang sudah check-in dilewati tanpa menghabiskan nomor urut
+			if result.RowsAffected == 0 {
+				continue
+			}
+
+			for i, leg := range legs {
+				lastSequences[i]++
+				sequence := models.LegBoardingSequence{
+					BookingDetailID: detailID,
+					FlightNumber:    leg.FlightNumber,
+					OriginAirportID: leg.OriginAirportID,
+					DepartureTime:   leg.DepartureTime,
+					Sequence:        lastSequences[i],
+				}
+				if err := tx.Create(&sequence).Error; err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
@@ -1,9 +1,15 @@
 package booking
 
 import (
+	"time"
+
 	"ezytix-be/internal/middleware"
+	"ezytix-be/internal/models"
 	"ezytix-be/internal/modules/auth"
 	"ezytix-be/internal/modules/flight"
+	"ezytix-be/internal/modules/pricing"
+	"ezytix-be/internal/modules/promo"
+	"ezytix-be/internal/modules/traveler"
 	"ezytix-be/internal/scheduler"
 	"ezytix-be/pkg/mail"
 
@@ -11,30 +17,69 @@ import (
 	"gorm.io/gorm"
 )
 
-func BookingRegisterRoutes(app *fiber.App, db *gorm.DB) {
+func BookingRegisterRoutes(app *fiber.App, db *gorm.DB, paymentService PaymentServiceContract) {
 	bookingRepo := NewBookingRepository(db)
 	flightRepo := flight.NewFlightRepository(db)
 	authRepo := auth.NewAuthRepository(db)
 
 	flightService := flight.NewFlightService(flightRepo)
+	promoService := promo.NewPromoService(promo.NewPromoRepository(db), flightService)
+	pricingService := pricing.NewPricingService(pricing.NewPricingRepository(db))
+	travelerService := traveler.NewTravelerService(traveler.NewTravelerRepository(db))
 	authService := auth.NewAuthService(authRepo, mail.NewMailService()) // [BARU] Tambahkan mail service
 	bookingService := NewBookingService(
 		bookingRepo, 
 		flightService,
 		authService,   
+		paymentService,
+		promoService,
+		pricingService,
+		travelerService,
 	)
 
 	bookingHandler := NewBookingHandler(bookingService)
 
 	api := app.Group("/api/v1")
 
+	// Manage booking tanpa login, dibatasi per IP agar PNR tidak bisa ditebak
+	guest := api.Group("/manage-booking")
+	guest.Use(middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "manage-booking",
+		Max:    10,
+		Window: 15 * time.Minute,
+		Error:  ErrTooManyGuestLookups,
+	}))
+	guest.Post("/", bookingHandler.LookupGuestBooking)
+	guest.Post("/eticket", bookingHandler.DownloadGuestEticket)
+
+	// Terbuka juga untuk API key partner; scope dicek per route
+	read := middleware.RequireScope(models.ScopeBookingsRead)
+	write := middleware.RequireScope(models.ScopeBookingsWrite)
 
 	bookings := api.Group("/bookings")
-	bookings.Use(middleware.JWTMiddleware)
-	bookings.Post("/", bookingHandler.CreateOrder)
-	bookings.Get("/history", bookingHandler.GetMyBookings)
-	bookings.Get("/:order_id/invoice", bookingHandler.DownloadInvoice)
-	bookings.Get("/:booking_code/eticket", bookingHandler.DownloadEticket)
+	bookings.Use(middleware.RequireAuth)
+	bookings.Use(middleware.RateLimit(middleware.RateLimitConfig{
+		Name:    "bookings",
+		Max:     60,
+		Window:  time.Minute,
+		KeyFunc: middleware.KeyByUser,
+	}))
+	bookings.Post("/", write, bookingHandler.CreateOrder)
+	bookings.Get("/history", read, bookingHandler.GetMyBookings)
+	bookings.Get("/:order_id/invoice", read, bookingHandler.DownloadInvoice)
+	bookings.Get("/:booking_code/eticket", read, bookingHandler.DownloadEticket)
+	bookings.Post("/:order_id/cancel", write, bookingHandler.CancelOrder)
+	bookings.Put("/:booking_code/seats", write, bookingHandler.SelectSeats)
+	bookings.Post("/:booking_code/reschedule/quote", read, bookingHandler.QuoteReschedule)
+	bookings.Post("/:booking_code/reschedule", write, bookingHandler.RescheduleBooking)
+	bookings.Get("/:order_id/changes", read, bookingHandler.GetOrderChanges)
+	bookings.Post("/:booking_code/check-in", write, bookingHandler.CheckIn)
+	bookings.Get("/:booking_code/boarding-pass", read, bookingHandler.DownloadBoardingPass)
+
+	admin := api.Group("/admin/bookings")
+	admin.Use(middleware.JWTMiddleware)
+	admin.Use(middleware.RequireRole("admin"))
+	admin.Get("/manifest/flights/:flight_id", bookingHandler.ExportManifest)
 	
 	scheduler.StartCronJob(bookingService)
 }
\ No newline at end of file
@@ -1,9 +1,10 @@
 package booking
 
 import (
+	"bytes"
 	"context"
 	"encoding/base64"
-	"errors"
+	"encoding/csv"
 	"fmt"
 	"io"
 	"log"
@@ -18,63 +19,197 @@ import (
 	"ezytix-be/internal/models"
 	"ezytix-be/internal/modules/auth"
 	"ezytix-be/internal/modules/flight"
+	"ezytix-be/internal/modules/pricing"
+	"ezytix-be/internal/modules/promo"
+	"ezytix-be/internal/modules/traveler"
 	"ezytix-be/internal/utils"
 	pdfprinter "ezytix-be/internal/utils/pdf_printer"
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/jwt"
 
 	"github.com/shopspring/decimal"
 )
 
+var (
+	ErrInvalidBookingRequest = apperror.BadRequest("INVALID_BOOKING_REQUEST", "invalid booking request")
+	ErrInvalidPassenger      = apperror.BadRequest("INVALID_PASSENGER", "invalid passenger data")
+	ErrSeatClassUnavailable  = apperror.BadRequest("SEAT_CLASS_UNAVAILABLE", "seat class not available for this flight")
+	ErrTicketNotInBooking    = apperror.BadRequest("TICKET_NOT_IN_BOOKING", "ticket is not part of this booking")
+	ErrTravelerNotFound      = apperror.NotFound("TRAVELER_NOT_FOUND", "saved traveler not found")
+	ErrFlightDeparted        = apperror.Expired("FLIGHT_DEPARTED", "flight has already departed")
+	ErrChangeWindowClosed    = apperror.Expired("CHANGE_WINDOW_CLOSED", "booking can no longer be changed this close to departure")
+)
+
 type BookingService interface {
 	CreateOrder(userID uint, req CreateOrderRequest) (*BookingResponse, error)
 	ProcessExpiredBookings() error
 	GetUserBookings(userID uint) ([]MyBookingResponse, error)
-	DownloadInvoice(ctx context.Context, bookingCode string) ([]byte, error)
-	DownloadEticket(ctx context.Context, bookingCode string) ([]byte, error)
+	DownloadInvoice(ctx context.Context, requester *jwt.JWTClaims, orderID string) ([]byte, error)
+	DownloadEticket(ctx context.Context, requester *jwt.JWTClaims, bookingCode string) ([]byte, error)
+	CancelOrder(userID uint, orderID string, req CancelOrderRequest) (*CancelOrderResponse, error)
+	SelectSeats(userID uint, bookingCode string, req SelectSeatsRequest) ([]PassengerDetailResponse, error)
+	QuoteReschedule(userID uint, bookingCode string, req RescheduleRequest) (*BookingChangeResponse, error)
+	RescheduleBooking(userID uint, bookingCode string, req RescheduleRequest) (*BookingChangeResponse, error)
+	GetOrderChanges(userID uint, orderID string) ([]BookingChangeResponse, error)
+	LookupGuestBooking(req GuestBookingRequest) (*MyBookingResponse, error)
+	DownloadGuestEticket(ctx context.Context, req GuestBookingRequest) ([]byte, error)
+	CheckIn(userID uint, bookingCode string, req CheckInRequest) ([]PassengerDetailResponse, error)
+	DownloadBoardingPass(ctx context.Context, requester *jwt.JWTClaims, bookingCode string, ticketNumber string) ([]byte, error)
+	GetFlightManifest(flightID uint, legID uint) (*ManifestResponse, error)
+	ExportManifestCSV(flightID uint, legID uint) ([]byte, error)
+	ExportManifestPDF(ctx context.Context, flightID uint, legID uint) ([]byte, error)
+}
+
+type PaymentServiceContract interface {
+	CancelPayment(orderID string) error
+	ReconcileOrder(orderID string) (bool, error)
+	RefundPayment(orderID string, refundKey string, amount decimal.Decimal, reason string) error
+}
+
+type cancellationRule struct {
+	MinHoursBeforeDeparture float64
+	FeePercent              int64
+}
+
+// Aturan pembatalan per kelas, diurutkan dari batas waktu terjauh.
+// Pembatalan di bawah batas terakhir tidak diizinkan.
+var cancellationRules = map[string][]cancellationRule{
+	"economy": {
+		{MinHoursBeforeDeparture: 72, FeePercent: 25},
+		{MinHoursBeforeDeparture: 24, FeePercent: 50},
+		{MinHoursBeforeDeparture: 4, FeePercent: 75},
+	},
+	"business": {
+		{MinHoursBeforeDeparture: 72, FeePercent: 10},
+		{MinHoursBeforeDeparture: 24, FeePercent: 25},
+		{MinHoursBeforeDeparture: 4, FeePercent: 50},
+	},
+	"first_class": {
+		{MinHoursBeforeDeparture: 24, FeePercent: 0},
+		{MinHoursBeforeDeparture: 4, FeePercent: 25},
+	},
+}
+
+type rescheduleRule struct {
+	MinHoursBeforeDeparture float64
+	FeePerPassenger         int64
+}
+
+// Biaya ubah jadwal per penumpang berkursi, mengikuti kelas booking lama.
+// Perubahan di bawah batas terakhir tidak diizinkan.
+var rescheduleRules = map[string][]rescheduleRule{
+	"economy": {
+		{MinHoursBeforeDeparture: 72, FeePerPassenger: 100000},
+		{MinHoursBeforeDeparture: 24, FeePerPassenger: 250000},
+		{MinHoursBeforeDeparture: 4, FeePerPassenger: 400000},
+	},
+	"business": {
+		{MinHoursBeforeDeparture: 72, FeePerPassenger: 0},
+		{MinHoursBeforeDeparture: 24, FeePerPassenger: 150000},
+		{MinHoursBeforeDeparture: 4, FeePerPassenger: 300000},
+	},
+	"first_class": {
+		{MinHoursBeforeDeparture: 24, FeePerPassenger: 0},
+		{MinHoursBeforeDeparture: 4, FeePerPassenger: 150000},
+	},
+}
+
+// Batas waktu pembayaran tambahan reschedule, sama dengan order baru.
+const rescheduleExpiry = 55 * time.Minute
+
+// Jendela online check-in relatif terhadap jam keberangkatan
+const (
+	checkInOpensBefore      = 48 * time.Hour
+	checkInClosesBefore     = 2 * time.Hour
+	boardingBeforeDeparture = 40 * time.Minute
+)
+
+// Urutan baris invoice per tipe penumpang
+var invoicePassengerTypeOrder = []string{
+	strings.ToUpper(models.PassengerTypeAdult),
+	strings.ToUpper(models.PassengerTypeChild),
+	strings.ToUpper(models.PassengerTypeInfant),
 }
 
 type bookingService struct {
-	repo          BookingRepository
-	flightService flight.FlightService
-	authService   auth.AuthService
+	repo            BookingRepository
+	flightService   flight.FlightService
+	authService     auth.AuthService
+	paymentService  PaymentServiceContract
+	promoService    promo.PromoService
+	pricingService  pricing.PricingService
+	travelerService traveler.TravelerService
 }
 
 func NewBookingService(
 	repo BookingRepository,
 	flightService flight.FlightService,
 	authService auth.AuthService,
+	paymentService PaymentServiceContract,
+	promoService promo.PromoService,
+	pricingService pricing.PricingService,
+	travelerService traveler.TravelerService,
 ) BookingService {
 	return &bookingService{
-		repo:          repo,
-		flightService: flightService,
-		authService:   authService,
+		repo:            repo,
+		flightService:   flightService,
+		authService:     authService,
+		paymentService:  paymentService,
+		promoService:    promoService,
+		pricingService:  pricingService,
+		travelerService: travelerService,
 	}
 }
 
 func (s *bookingService) CreateOrder(userID uint, req CreateOrderRequest) (*BookingResponse, error) {
 	_, err := s.authService.GetUserByID(userID)
 	if err != nil {
-		return nil, errors.New("user not found")
+		return nil, auth.ErrUserNotFound
 	}
 
 	orderID := fmt.Sprintf("ORD-%s-%s", time.Now().Format("20060102"), generateRandomString(4))
 
 	var bookingsToSave []models.Booking
+	var seatAssignments []SeatAssignment
 	grandTotal := decimal.Zero
 	var bookingResponses []BookingDetailResponse
 
-	tripType := models.TripTypeOneWay
-	if len(req.Items) > 1 {
-		tripType = models.TripTypeRoundTrip
+	if len(req.Items) == 0 {
+		return nil, ErrInvalidBookingRequest.Withf("order must contain at least one flight")
 	}
 
-	expiryDuration := 55 * time.Minute
-	expiryAt := time.Now().Add(expiryDuration)
-
-	for _, item := range req.Items {
+	flightsData := make([]*models.Flight, len(req.Items))
+	var itineraryFlights []models.Flight
+	for i, item := range req.Items {
 		flightData, err := s.flightService.GetFlightByID(item.FlightID)
 		if err != nil {
-			return nil, errors.New("flight not found")
+			return nil, flight.ErrFlightNotFound
+		}
+		if flightData.DepartureTime.Before(time.Now()) {
+			return nil, ErrFlightDeparted.Withf("flight %s has already departed", flightData.FlightCode)
+		}
+		flightsData[i] = flightData
+		itineraryFlights = append(itineraryFlights, *flightData)
+	}
+
+	tripType := req.TripType
+	if tripType == "" {
+		tripType = flight.InferTripType(itineraryFlights)
+	}
+	if err := flight.ValidateItinerary(tripType, itineraryFlights); err != nil {
+		return nil, err
+	}
+	for _, item := range req.Items[1:] {
+		if len(item.Passengers) != len(req.Items[0].Passengers) {
+			return nil, ErrInvalidBookingRequest.Withf("every flight in the itinerary must have the same passengers")
 		}
+	}
+
+	expiryDuration := 55 * time.Minute
+	expiryAt := time.Now().Add(expiryDuration)
+
+	for i, item := range req.Items {
+		flightData := flightsData[i]
 
 		var selectedClass *models.FlightClass
 		for _, fc := range flightData.FlightClasses {
@@ -84,14 +219,19 @@ func (s *bookingService) CreateOrder(userID uint, req CreateOrderRequest) (*Book
 			}
 		}
 		if selectedClass == nil {
-			return nil, errors.New("seat class not available for this flight")
+			return nil, ErrSeatClassUnavailable
 		}
 
-		passengerCountInt := int64(len(item.Passengers))
-		passengerCountDec := decimal.NewFromInt(passengerCountInt)
-		flightTotalPrice := selectedClass.Price.Mul(passengerCountDec)
-		grandTotal = grandTotal.Add(flightTotalPrice)
+		passengers, err := s.resolvePassengers(userID, item.Passengers, flightData.DepartureTime)
+		if err != nil {
+			return nil, err
+		}
+
+		if err := validateInfantRatio(passengers, flightData.DepartureTime); err != nil {
+			return nil, err
+		}
 
+		flightTotalPrice := decimal.Zero
 		bookingCode := generatePNR()
 		
 		booking := models.Booking{
@@ -101,17 +241,24 @@ func (s *bookingService) CreateOrder(userID uint, req CreateOrderRequest) (*Book
 			BookingCode:     bookingCode,
 			TripType:        tripType,
 			TotalPassengers: len(item.Passengers),
-			TotalPrice:      flightTotalPrice,
 			Status:          models.BookingStatusPending,
 			ExpiredAt:       &expiryAt, 
 			CreatedAt:       time.Now(),
 		}
 
 		var details []models.BookingDetail
-		for _, pReq := range item.Passengers {
+		for j, pReq := range passengers {
 			dobTime, _ := time.Parse("2006-01-02", pReq.DOB)
-			passengerType := calculatePassengerType(dobTime)
+			passengerType := calculatePassengerType(dobTime, flightData.DepartureTime)
 			ticketNum := fmt.Sprintf("%s-%s", bookingCode, generateRandomString(3))
+			charges, err := s.pricingService.QuotePassenger(*flightData, passengerType, flight.PassengerFare(*selectedClass, passengerType))
+			if err != nil {
+				return nil, fmt.Errorf("failed to calculate fare: %w", err)
+			}
+			flightTotalPrice = flightTotalPrice.Add(charges.Total)
+			booking.BaseFare = booking.BaseFare.Add(charges.BaseFare)
+			booking.AirportTax = booking.AirportTax.Add(charges.AirportTax)
+			booking.VATAmount = booking.VATAmount.Add(charges.VAT)
 
 			detail := models.BookingDetail{
 				PassengerName:  pReq.FullName,
@@ -124,11 +271,30 @@ func (s *bookingService) CreateOrder(userID uint, req CreateOrderRequest) (*Book
 				ValidUntil:     dateToPointer(pReq.ValidUntil),
 				TicketNumber:   ticketNum,
 				SeatClass:      item.SeatClass,
-				Price:          selectedClass.Price,
+				BaseFare:       charges.BaseFare,
+				AirportTax:     charges.AirportTax,
+				VATAmount:      charges.VAT,
+				Price:          charges.Total,
 			}
 			details = append(details, detail)
+
+			if passengerType == models.PassengerTypeInfant && len(pReq.Seats) > 0 {
+				return nil, ErrInvalidPassenger.Withf("infant %s sits on an adult's lap and cannot select a seat", pReq.FullName)
+			}
+
+			seats, err := buildSeatAssignments(flightData, item.SeatClass, pReq.Seats)
+			if err != nil {
+				return nil, err
+			}
+			for _, seat := range seats {
+				seat.BookingIndex = i
+				seat.DetailIndex = j
+				seatAssignments = append(seatAssignments, seat)
+			}
 		}
 		booking.Details = details
+		booking.TotalPrice = flightTotalPrice
+		grandTotal = grandTotal.Add(flightTotalPrice)
 		bookingsToSave = append(bookingsToSave, booking)
 
 		bookingResponses = append(bookingResponses, BookingDetailResponse{
@@ -142,13 +308,63 @@ func (s *bookingService) CreateOrder(userID uint, req CreateOrderRequest) (*Book
 		})
 	}
 
-	if err := s.repo.CreateOrder(bookingsToSave); err != nil {
+	var redemption *models.PromoRedemption
+	discountTotal := decimal.Zero
+	promoCode := ""
+	if strings.TrimSpace(req.PromoCode) != "" {
+		var promoItems []promo.PromoItem
+		for i, booking := range bookingsToSave {
+			promoItems = append(promoItems, promo.PromoItem{
+				FlightID:             flightsData[i].ID,
+				AirlineID:            flightsData[i].AirlineID,
+				OriginAirportID:      flightsData[i].OriginAirportID,
+				DestinationAirportID: flightsData[i].DestinationAirportID,
+				SeatClass:            req.Items[i].SeatClass,
+				Amount:               booking.TotalPrice,
+			})
+		}
+
+		quote, err := s.promoService.Quote(userID, req.PromoCode, promoItems)
+		if err != nil {
+			return nil, err
+		}
+
+		promoCode = quote.Code
+		discountTotal = quote.DiscountAmount
+		for i := range bookingsToSave {
+			bookingsToSave[i].PromoCode = &promoCode
+			bookingsToSave[i].DiscountAmount = quote.ItemDiscounts[i]
+			bookingsToSave[i].TotalPrice = bookingsToSave[i].TotalPrice.Sub(quote.ItemDiscounts[i])
+			bookingResponses[i].TotalPrice = bookingsToSave[i].TotalPrice
+		}
+		grandTotal = grandTotal.Sub(discountTotal)
+
+		redemption = &models.PromoRedemption{
+			PromotionID:    quote.PromotionID,
+			UserID:         userID,
+			OrderID:        orderID,
+			DiscountAmount: discountTotal,
+			Status:         models.PromoRedemptionStatusActive,
+		}
+	}
+
+	if err := s.repo.CreateOrder(bookingsToSave, seatAssignments, redemption); err != nil {
 		return nil, err
 	}
+
+	var orderBookings []PriceBreakdownResponse
+	for i, booking := range bookingsToSave {
+		bookingResponses[i].PriceBreakdown = toPriceBreakdown(booking)
+		bookingResponses[i].Passengers = toPassengerPrices(booking.Details)
+		orderBookings = append(orderBookings, bookingResponses[i].PriceBreakdown)
+	}
 	
 	return &BookingResponse{
 		OrderID:         orderID,
 		TotalAmount:     grandTotal,
+		PromoCode:       promoCode,
+		DiscountAmount:  discountTotal,
+		PriceBreakdown:  sumPriceBreakdowns(orderBookings),
 		Status:          models.BookingStatusPending,
 		TransactionTime: time.Now(),
 		ExpiryTime:      &expiryAt,
@@ -165,7 +381,21 @@ func (s *bookingService) ProcessExpiredBookings() error {
 	} else {
 		if len(expiredPendingBookings) > 0 {
 			log.Printf("[CRON] Found %d pending bookings to cancel.\n", len(expiredPendingBookings))
+			reconciled := make(map[string]bool)
 			for _, booking := range expiredPendingBookings {
+				paid, checked := reconciled[booking.OrderID]
+				if !checked {
+					paid, err = s.paymentService.ReconcileOrder(booking.OrderID)
+					if err != nil {
+						log.Printf("[CRON] Failed to reconcile order %s before expiry: %v\n", booking.OrderID, err)
+					}
+					reconciled[booking.OrderID] = paid
+				}
+				if paid {
+					log.Printf("[CRON] Skipped Booking ID %d, payment settled at gateway.\n", booking.ID)
+					continue
+				}
+
 				err := s.repo.CancelBookingAtomic(&booking)
 				if err != nil {
 					log.Printf("[CRON] Failed to cancel Pending Booking ID %d: %v\n", booking.ID, err)
@@ -176,6 +406,8 @@ func (s *bookingService) ProcessExpiredBookings() error {
 		}
 	}
 
+	s.expireBookingChanges()
+
 	err = s.repo.UpdatePastBookingsToExpired()
 	if err != nil {
 		log.Printf("[CRON] Error updating past flights: %v\n", err)
@@ -193,69 +425,124 @@ func (s *bookingService) GetUserBookings(userID uint) ([]MyBookingResponse, erro
 
 	var responses []MyBookingResponse
 	for _, b := range bookings {
-		var seatClass, classCode string
-		if len(b.Details) > 0 {
-			seatClass = b.Details[0].SeatClass
-		}
-		for _, fc := range b.Flight.FlightClasses {
-			if strings.EqualFold(fc.SeatClass, seatClass) {
-				classCode = fc.ClassCode
-				break
-			}
-		}
+		responses = append(responses, toMyBookingResponse(b))
+	}
 
-		flightDetail := BookingFlightDetail{
-			FlightCode:        b.Flight.FlightCode,
-			AirlineName:       b.Flight.Airline.Name,
-			AirlineLogo:       b.Flight.Airline.LogoURL,
-			Origin:            fmt.Sprintf("%s (%s)", b.Flight.OriginAirport.CityName, b.Flight.OriginAirport.Code),
-			Destination:       fmt.Sprintf("%s (%s)", b.Flight.DestinationAirport.CityName, b.Flight.DestinationAirport.Code),
-			DepartureTime:     b.Flight.DepartureTime,
-			ArrivalTime:       b.Flight.ArrivalTime,
-			DurationMinutes:   b.Flight.TotalDuration, 
-			DurationFormatted: utils.FormatDuration(b.Flight.TotalDuration),
-			TransitInfo:       b.Flight.TransitInfo,
-			SeatClass:         seatClass,
-			ClassCode:         classCode,
-		}
-
-		var passengerList []PassengerDetailResponse
-		for _, detail := range b.Details {
-			passengerList = append(passengerList, PassengerDetailResponse{
-				FullName:     detail.PassengerName,
-				Type:         detail.PassengerType,
-				TicketNumber: detail.TicketNumber,
-				SeatClass:    detail.SeatClass,
-			})
-		}
-		var expiryTime *time.Time
-		if b.Status == models.BookingStatusPending {
-			expiryTime = b.ExpiredAt
+	return responses, nil
+}
+
+// LookupGuestBooking membuka booking tanpa login dengan PNR dan nama belakang
+// salah satu penumpang. Pesan error sengaja sama untuk PNR maupun nama yang
+// salah agar tidak bisa dipakai menebak PNR.
+func (s *bookingService) LookupGuestBooking(req GuestBookingRequest) (*MyBookingResponse, error) {
+	booking, err := s.findGuestBooking(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toMyBookingResponse(*booking)
+	return &resp, nil
+}
+
+func (s *bookingService) DownloadGuestEticket(ctx context.Context, req GuestBookingRequest) ([]byte, error) {
+	booking, err := s.findGuestBooking(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status != models.BookingStatusPaid && booking.Status != models.BookingStatusExpired {
+		return nil, ErrInvalidBookingStatus.Withf("e-ticket is not available for booking with status %s", booking.Status)
+	}
+
+	ticketBooking, err := s.repo.GetBookingForTicket(booking.BookingCode)
+	if err != nil {
+		return nil, ErrGuestBookingNotFound
+	}
+
+	return s.renderEticket(ctx, ticketBooking)
+}
+
+func (s *bookingService) findGuestBooking(req GuestBookingRequest) (*models.Booking, error) {
+	bookingCode := strings.ToUpper(strings.TrimSpace(req.BookingCode))
+	lastName := strings.TrimSpace(req.LastName)
+	if bookingCode == "" || lastName == "" {
+		return nil, ErrInvalidBookingRequest.Withf("booking code and last name are required")
+	}
+
+	booking, err := s.repo.GetBookingByCode(bookingCode)
+	if err != nil {
+		return nil, ErrGuestBookingNotFound
+	}
+
+	for _, detail := range booking.Details {
+		if strings.EqualFold(passengerLastName(detail.PassengerName), lastName) {
+			return booking, nil
 		}
+	}
+	return nil, ErrGuestBookingNotFound
+}
+
+func passengerLastName(fullName string) string {
+	parts := strings.Fields(fullName)
+	if len(parts) == 0 {
+		return ""
+	}
+	return parts[len(parts)-1]
+}
 
-		resp := MyBookingResponse{
-			OrderID:     b.OrderID,
-			BookingCode: b.BookingCode,
-			Status:      b.Status,
-			TotalAmount: b.TotalPrice,
-			CreatedAt:   b.CreatedAt,
-			ExpiryTime:  expiryTime,
-			Flight:      flightDetail,
-			Passengers:  passengerList,
+func toMyBookingResponse(b models.Booking) MyBookingResponse {
+	var seatClass, classCode string
+	if len(b.Details) > 0 {
+		seatClass = b.Details[0].SeatClass
+	}
+	for _, fc := range b.Flight.FlightClasses {
+		if strings.EqualFold(fc.SeatClass, seatClass) {
+			classCode = fc.ClassCode
+			break
 		}
-		responses = append(responses, resp)
 	}
 
-	return responses, nil
+	flightDetail := BookingFlightDetail{
+		FlightCode:        b.Flight.FlightCode,
+		AirlineName:       b.Flight.Airline.Name,
+		AirlineLogo:       b.Flight.Airline.LogoURL,
+		Origin:            fmt.Sprintf("%s (%s)", b.Flight.OriginAirport.CityName, b.Flight.OriginAirport.Code),
+		Destination:       fmt.Sprintf("%s (%s)", b.Flight.DestinationAirport.CityName, b.Flight.DestinationAirport.Code),
+		DepartureTime:     b.Flight.DepartureTime,
+		ArrivalTime:       b.Flight.ArrivalTime,
+		DurationMinutes:   b.Flight.TotalDuration,
+		DurationFormatted: utils.FormatDuration(b.Flight.TotalDuration),
+		TransitInfo:       b.Flight.TransitInfo,
+		SeatClass:         seatClass,
+		ClassCode:         classCode,
+	}
+
+	var expiryTime *time.Time
+	if b.Status == models.BookingStatusPending {
+		expiryTime = b.ExpiredAt
+	}
+
+	return MyBookingResponse{
+		OrderID:        b.OrderID,
+		BookingCode:    b.BookingCode,
+		Status:         b.Status,
+		TotalAmount:    b.TotalPrice,
+		PriceBreakdown: toPriceBreakdown(b),
+		CreatedAt:      b.CreatedAt,
+		ExpiryTime:     expiryTime,
+		Flight:         flightDetail,
+		Passengers:     toPassengerDetailResponses(b.Details, b.Flight.FlightLegs),
+	}
 }
 
-func (s *bookingService) DownloadInvoice(ctx context.Context, orderID string) ([]byte, error) {
+func (s *bookingService) DownloadInvoice(ctx context.Context, requester *jwt.JWTClaims, orderID string) ([]byte, error) {
 	bookings, err := s.repo.GetBookingsForInvoiceByOrderID(orderID)
 	if err != nil {
-		return nil, fmt.Errorf("bookings not found: %w", err)
+		return nil, ErrBookingNotFound
 	}
-	if len(bookings) == 0 {
-		return nil, fmt.Errorf("no bookings found for order id: %s", orderID)
+	// Order milik user lain diperlakukan seperti tidak ada
+	if len(bookings) == 0 || !requester.CanAccess(bookings[0].UserID) {
+		return nil, ErrBookingNotFound
 	}
 
 	mainBooking := bookings[0]
@@ -265,23 +552,27 @@ func (s *bookingService) DownloadInvoice(ctx context.Context, orderID string) ([
 		return nil, fmt.Errorf("failed to fetch payment: %w", err)
 	}
 
-	cwd, _ := os.Getwd()
-	assetsPath := filepath.Join(cwd, "internal", "assets", "images")
-	getHeader := func(name string) string {
-		fullPath := filepath.Join(assetsPath, name)
-		b64, err := pdfprinter.ImageToBase64(fullPath)
-		if err != nil {
-			return ""
-		}
-		return b64
-	}
-
 	var invoiceItems []pdfprinter.InvoiceItem
 	var totalAmountDecimal decimal.Decimal
+	baseFareDecimal := decimal.Zero
+	airportTaxDecimal := decimal.Zero
+	vatDecimal := decimal.Zero
+	serviceFeeDecimal := decimal.Zero
+	discountDecimal := decimal.Zero
+	changeFeeDecimal := decimal.Zero
+	promoCode := ""
 	counter := 1
 
 	for _, booking := range bookings {
 		totalAmountDecimal = totalAmountDecimal.Add(booking.TotalPrice)
+		airportTaxDecimal = airportTaxDecimal.Add(booking.AirportTax)
+		vatDecimal = vatDecimal.Add(booking.VATAmount)
+		serviceFeeDecimal = serviceFeeDecimal.Add(booking.ConvenienceFee)
+		discountDecimal = discountDecimal.Add(booking.DiscountAmount)
+		changeFeeDecimal = changeFeeDecimal.Add(booking.ChangeFee).Add(booking.RetainedFareDifference)
+		if booking.PromoCode != nil {
+			promoCode = *booking.PromoCode
+		}
 
 		type groupItem struct {
 			Count int64
@@ -293,10 +584,11 @@ func (s *bookingService) DownloadInvoice(ctx context.Context, orderID string) ([
 		for _, detail := range booking.Details {
 			pType := strings.ToUpper(detail.PassengerType)
 			if _, exists := groupedItems[pType]; !exists {
-				groupedItems[pType] = &groupItem{Count: 0, Total: decimal.Zero, Price: detail.Price}
+				groupedItems[pType] = &groupItem{Count: 0, Total: decimal.Zero, Price: detail.BaseFare}
 			}
 			groupedItems[pType].Count++
-			groupedItems[pType].Total = groupedItems[pType].Total.Add(detail.Price)
+			groupedItems[pType].Total = groupedItems[pType].Total.Add(detail.BaseFare)
+			baseFareDecimal = baseFareDecimal.Add(detail.BaseFare)
 		}
 
 		flightDesc := "Penerbangan"
@@ -313,15 +605,22 @@ func (s *bookingService) DownloadInvoice(ctx context.Context, orderID string) ([
 			}
 		}
 
-		for pType, data := range groupedItems {
+		for _, pType := range invoicePassengerTypeOrder {
+			data, exists := groupedItems[pType]
+			if !exists {
+				continue
+			}
+
 			fullDesc := fmt.Sprintf("%s (%s) - %s", flightDesc, pType, flightDateStr)
 			totalFloat, _ := data.Total.Float64()
+			unitPriceFloat, _ := data.Price.Float64()
 
 			item := pdfprinter.InvoiceItem{
 				Number:      strconv.Itoa(counter),
 				Product:     "Tiket Pesawat",
 				Description: fullDesc,
 				Quantity:    int(data.Count),
+				UnitPrice:   utils.FormatRupiah(unitPriceFloat),
 				Total:       utils.FormatRupiah(totalFloat),
 			}
 			invoiceItems = append(invoiceItems, item)
@@ -374,10 +673,26 @@ func (s *bookingService) DownloadInvoice(ctx context.Context, orderID string) ([
 	}
 
 	finalTotalFloat, _ := totalAmountDecimal.Float64()
+	subTotalFloat, _ := baseFareDecimal.Float64()
+	airportTaxFloat, _ := airportTaxDecimal.Float64()
+	vatFloat, _ := vatDecimal.Float64()
+	serviceFeeFloat, _ := serviceFeeDecimal.Float64()
+
+	discountStr := ""
+	if discountDecimal.IsPositive() {
+		discountFloat, _ := discountDecimal.Float64()
+		discountStr = utils.FormatRupiah(discountFloat)
+	}
+
+	changeFeeStr := ""
+	if changeFeeDecimal.IsPositive() {
+		changeFeeFloat, _ := changeFeeDecimal.Float64()
+		changeFeeStr = utils.FormatRupiah(changeFeeFloat)
+	}
 
 	invoiceData := pdfprinter.InvoiceData{
-		HeaderImage:   getHeader("invoice_header.png"),
-		FooterImage:   getHeader("invoice_footer.png"),
+		HeaderImage:   loadAssetBase64("invoice_header.png"),
+		FooterImage:   loadAssetBase64("invoice_footer.png"),
 		InvoiceNumber: mainBooking.OrderID,
 		Date:          paymentDate,
 		CustomerName:  mainBooking.User.FullName,
@@ -387,54 +702,42 @@ func (s *bookingService) DownloadInvoice(ctx context.Context, orderID string) ([
 		PaymentStatus: paymentStatus,
 		Passengers:    passengers,
 		Items:         invoiceItems,
-		SubTotal:      utils.FormatRupiah(finalTotalFloat),
-		ServiceFee:    "Rp 0",
+		SubTotal:      utils.FormatRupiah(subTotalFloat),
+		Discount:      discountStr,
+		PromoCode:     promoCode,
+		AirportTax:    utils.FormatRupiah(airportTaxFloat),
+		VAT:           utils.FormatRupiah(vatFloat),
+		ServiceFee:    utils.FormatRupiah(serviceFeeFloat),
+		ChangeFee:     changeFeeStr,
 		GrandTotal:    utils.FormatRupiah(finalTotalFloat),
 	}
 
-	tmpFileName := fmt.Sprintf("invoice_%s_%d.pdf", orderID, time.Now().Unix())
-	tmpFilePath := filepath.Join(cwd, tmpFileName)
-
-	err = pdfprinter.GeneratePDF("invoice.html", invoiceData, tmpFilePath)
-	if err != nil {
-		return nil, fmt.Errorf("failed generate pdf: %w", err)
-	}
+	return renderPDF("invoice.html", invoiceData)
+}
 
-	pdfBytes, err := os.ReadFile(tmpFilePath)
+func (s *bookingService) DownloadEticket(ctx context.Context, requester *jwt.JWTClaims, bookingCode string) ([]byte, error) {
+	booking, err := s.findAccessibleBooking(requester, bookingCode)
 	if err != nil {
-		os.Remove(tmpFilePath)
-		return nil, fmt.Errorf("failed read pdf file: %w", err)
+		return nil, err
 	}
-	os.Remove(tmpFilePath)
 
-	return pdfBytes, nil
+	return s.renderEticket(ctx, booking)
 }
 
-func (s *bookingService) DownloadEticket(ctx context.Context, bookingCode string) ([]byte, error) {
+// findAccessibleBooking mengambil booking milik requester (atau booking apa
+// pun untuk admin). Booking milik user lain diperlakukan seperti tidak ada.
+func (s *bookingService) findAccessibleBooking(requester *jwt.JWTClaims, bookingCode string) (*models.Booking, error) {
 	booking, err := s.repo.GetBookingForTicket(bookingCode)
-	if err != nil {
-		return nil, fmt.Errorf("booking not found: %w", err)
-	}
-
-	cwd, err := os.Getwd()
-	if err != nil {
-		return nil, fmt.Errorf("gagal get working directory: %v", err)
-	}
-	assetsPath := filepath.Join(cwd, "internal", "assets", "images")
-
-	getHeader := func(name string) string {
-		fullPath := filepath.Join(assetsPath, name)
-		b64, err := pdfprinter.ImageToBase64(fullPath)
-		if err != nil {
-			fmt.Printf("⚠️ Warning: Gagal load asset %s: %v\n", name, err)
-			return ""
-		}
-		return b64
+	if err != nil || !requester.CanAccess(booking.UserID) {
+		return nil, ErrBookingNotFound
 	}
+	return booking, nil
+}
 
+func (s *bookingService) renderEticket(ctx context.Context, booking *models.Booking) ([]byte, error) {
 	qrCodeBase64, err := pdfprinter.GenerateQRCodeBase64(booking.BookingCode)
 	if err != nil {
-		fmt.Printf("Warning: Failed to generate QR: %v\n", err)
+		log.Printf("[PDF] Failed to generate QR for booking %s: %v\n", booking.BookingCode, err)
 		qrCodeBase64 = ""
 	}
 
@@ -445,15 +748,7 @@ func (s *bookingService) DownloadEticket(ctx context.Context, bookingCode string
 		totalLegs := len(legs)
 
 		for i, leg := range legs {
-			airlineLogo := getHeader("Lion.png")
-			if leg.Airline != nil && leg.Airline.LogoURL != "" {
-				remoteB64, err := downloadImageToBase64(leg.Airline.LogoURL)
-				if err == nil && remoteB64 != "" {
-					airlineLogo = remoteB64
-				} else {
-					fmt.Printf("⚠️ Gagal download logo maskapai (%s): %v. Menggunakan default.\n", leg.Airline.LogoURL, err)
-				}
-			}
+			airlineLogo := loadAirlineLogo(leg.Airline)
 
 			durationMinutes := int(leg.ArrivalTime.Sub(leg.DepartureTime).Minutes())
 			durationStr := utils.FormatDuration(durationMinutes)
@@ -503,18 +798,26 @@ func (s *bookingService) DownloadEticket(ctx context.Context, bookingCode string
 		}
 	}
 
+	legRoutes := make(map[uint]string)
+	for _, leg := range booking.Flight.FlightLegs {
+		if leg.OriginAirport != nil && leg.DestinationAirport != nil {
+			legRoutes[leg.ID] = fmt.Sprintf("%s-%s", leg.OriginAirport.Code, leg.DestinationAirport.Code)
+		}
+	}
+
 	var passengers []pdfprinter.TicketPassenger
 	for i, detail := range booking.Details {
 		passengers = append(passengers, pdfprinter.TicketPassenger{
 			Number:       i + 1,
 			Name:         fmt.Sprintf("%s. %s (%s)", strings.ToUpper(detail.PassengerTitle), detail.PassengerName, strings.ToUpper(detail.PassengerType)),
 			TicketNumber: detail.TicketNumber,
+			Seat:         formatSeatLabel(detail.Seats, booking.Flight.FlightLegs, legRoutes),
 		})
 	}
 
 	ticketData := pdfprinter.TicketData{
-		HeaderImage: getHeader("eticket_header.png"), 
-		FooterImage: getHeader("eticket_footer.png"),
+		HeaderImage: loadAssetBase64("eticket_header.png"),
+		FooterImage: loadAssetBase64("eticket_footer.png"),
 		BookingID:   booking.OrderID,
 		BookingCode: booking.BookingCode,
 		BookingDate: booking.CreatedAt.Format("02 Jan 2006, 15:04"),
@@ -524,77 +827,1167 @@ func (s *bookingService) DownloadEticket(ctx context.Context, bookingCode string
 		Passengers:  passengers,
 	}
 
-	tmpFileName := fmt.Sprintf("temp_ticket_%s_%d.pdf", bookingCode, time.Now().Unix())
-	tmpFilePath := filepath.Join(cwd, tmpFileName)
+	return renderPDF("ticket.html", ticketData)
+}
 
-	err = pdfprinter.GeneratePDF("ticket.html", ticketData, tmpFilePath)
+func (s *bookingService) CancelOrder(userID uint, orderID string, req CancelOrderRequest) (*CancelOrderResponse, error) {
+	bookings, err := s.repo.FindBookingsByOrderID(orderID)
 	if err != nil {
-		return nil, fmt.Errorf("failed generate pdf: %w", err)
+		return nil, err
+	}
+	if len(bookings) == 0 || bookings[0].UserID != userID {
+		return nil, ErrBookingNotFound
 	}
 
-	pdfBytes, err := os.ReadFile(tmpFilePath)
+	orderStatus := bookings[0].Status
+	if orderStatus != models.BookingStatusPending && orderStatus != models.BookingStatusPaid {
+		return nil, ErrInvalidBookingStatus.Withf("booking with status %s cannot be cancelled", orderStatus)
+	}
+
+	now := time.Now()
+	paidAmount := decimal.Zero
+	cancellationFee := decimal.Zero
+
+	for _, booking := range bookings {
+		if booking.Status != orderStatus {
+			return nil, ErrInvalidBookingStatus.Withf("booking status is inconsistent, please contact support")
+		}
+
+		paidAmount = paidAmount.Add(booking.TotalPrice)
+
+		if orderStatus == models.BookingStatusPaid {
+			fee, err := calculateCancellationFee(booking, now)
+			if err != nil {
+				return nil, err
+			}
+			cancellationFee = cancellationFee.Add(fee)
+		}
+	}
+
+	resp := &CancelOrderResponse{
+		OrderID:         orderID,
+		Status:          models.BookingStatusCancelled,
+		PaidAmount:      decimal.Zero,
+		CancellationFee: decimal.Zero,
+		RefundAmount:    decimal.Zero,
+		CancelledAt:     now,
+	}
+
+	if orderStatus == models.BookingStatusPending {
+		if err := s.repo.CancelOrderAtomic(bookings, nil); err != nil {
+			return nil, err
+		}
+
+		if err := s.paymentService.CancelPayment(orderID); err != nil {
+			log.Printf("[CANCEL] Failed to cancel pending payment for order %s: %v\n", orderID, err)
+		}
+
+		return resp, nil
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		reason = "Customer cancellation"
+	}
+
+	refund := &models.Refund{
+		OrderID:         orderID,
+		UserID:          userID,
+		RefundKey:       fmt.Sprintf("RFD-%s-%s", orderID, generateRandomString(4)),
+		PaidAmount:      paidAmount,
+		CancellationFee: cancellationFee,
+		RefundAmount:    paidAmount.Sub(cancellationFee),
+		Reason:          reason,
+		Status:          models.RefundStatusPending,
+	}
+
+	// Biaya reschedule dibayar lewat pembayaran CHG- tersendiri, jadi refund
+	// dibagi ke setiap pembayaran yang membentuk total harga booking
+	changes, err := s.repo.GetBookingChangesByOrderID(orderID)
 	if err != nil {
-		os.Remove(tmpFilePath)
-		return nil, fmt.Errorf("failed read pdf file: %w", err)
+		return nil, err
 	}
 
-	os.Remove(tmpFilePath)
+	if err := s.repo.CancelOrderAtomic(bookings, refund); err != nil {
+		return nil, err
+	}
 
-	return pdfBytes, nil
-}
+	refundStatus := models.RefundStatusSuccess
+	var failures []string
+	for _, part := range splitRefund(orderID, refund.RefundKey, refund.RefundAmount, changes) {
+		if err := s.paymentService.RefundPayment(part.OrderID, part.RefundKey, part.Amount, reason); err != nil {
+			log.Printf("[CANCEL] Refund %s for order %s failed: %v\n", part.RefundKey, part.OrderID, err)
+			refundStatus = models.RefundStatusFailed
+			failures = append(failures, fmt.Sprintf("%s: %v", part.OrderID, err))
+		}
+	}
+	failureReason := strings.Join(failures, "; ")
 
-func generateRandomString(n int) string {
-	const letterBytes = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
-	b := make([]byte, n)
-	for i := range b {
-		b[i] = letterBytes[rand.Intn(len(letterBytes))]
+	if err := s.repo.UpdateRefundStatus(refund.ID, refundStatus, failureReason); err != nil {
+		log.Printf("[CANCEL] Failed to update refund %s status: %v\n", refund.RefundKey, err)
 	}
-	return string(b)
+
+	resp.PaidAmount = refund.PaidAmount
+	resp.CancellationFee = refund.CancellationFee
+	resp.RefundAmount = refund.RefundAmount
+	resp.RefundStatus = refundStatus
+
+	return resp, nil
 }
 
-func generatePNR() string {
-	return fmt.Sprintf("%s", generateRandomString(6))
+func (s *bookingService) SelectSeats(userID uint, bookingCode string, req SelectSeatsRequest) ([]PassengerDetailResponse, error) {
+	if len(req.Seats) == 0 {
+		return nil, ErrInvalidBookingRequest.Withf("at least one seat selection is required")
+	}
+
+	booking, err := s.repo.GetBookingForTicket(bookingCode)
+	if err != nil || booking.UserID != userID {
+		return nil, ErrBookingNotFound
+	}
+
+	if booking.Status != models.BookingStatusPending && booking.Status != models.BookingStatusPaid {
+		return nil, ErrInvalidBookingStatus.Withf("seats cannot be selected for booking with status %s", booking.Status)
+	}
+	if booking.Flight.DepartureTime.Before(time.Now()) {
+		return nil, ErrFlightDeparted
+	}
+
+	detailsByTicket := make(map[string]models.BookingDetail)
+	for _, detail := range booking.Details {
+		detailsByTicket[detail.TicketNumber] = detail
+	}
+
+	requested := make(map[string]bool)
+	var assignments []SeatAssignment
+	for _, selection := range req.Seats {
+		detail, ok := detailsByTicket[selection.TicketNumber]
+		if !ok {
+			return nil, ErrTicketNotInBooking.Withf("ticket %s is not part of this booking", selection.TicketNumber)
+		}
+		if detail.PassengerType == models.PassengerTypeInfant {
+			return nil, ErrInvalidPassenger.Withf("ticket %s belongs to an infant on lap and cannot have a seat", selection.TicketNumber)
+		}
+
+		key := fmt.Sprintf("%s:%d", selection.TicketNumber, selection.FlightLegID)
+		if requested[key] {
+			return nil, ErrInvalidBookingRequest.Withf("ticket %s has more than one seat on the same leg", selection.TicketNumber)
+		}
+		requested[key] = true
+
+		seats, err := buildSeatAssignments(&booking.Flight, detail.SeatClass, []PassengerSeatRequest{{
+			FlightLegID: selection.FlightLegID,
+			SeatNumber:  selection.SeatNumber,
+		}})
+		if err != nil {
+			return nil, err
+		}
+		for _, seat := range seats {
+			seat.BookingDetailID = detail.ID
+			assignments = append(assignments, seat)
+		}
+	}
+
+	if err := s.repo.AssignSeats(assignments); err != nil {
+		return nil, err
+	}
+
+	updated, err := s.repo.GetBookingForTicket(bookingCode)
+	if err != nil {
+		return nil, err
+	}
+
+	return toPassengerDetailResponses(updated.Details, updated.Flight.FlightLegs), nil
 }
 
-func calculatePassengerType(dob time.Time) string {
+func (s *bookingService) CheckIn(userID uint, bookingCode string, req CheckInRequest) ([]PassengerDetailResponse, error) {
+	booking, err := s.repo.GetBookingForTicket(bookingCode)
+	if err != nil || booking.UserID != userID {
+		return nil, ErrBookingNotFound
+	}
+
+	if booking.Status != models.BookingStatusPaid {
+		return nil, ErrInvalidBookingStatus.Withf("check-in is only available for paid bookings, current status is %s", booking.Status)
+	}
+
 	now := time.Now()
-	age := now.Year() - dob.Year()
-	if now.YearDay() < dob.YearDay() {
-		age--
+	departure := booking.Flight.DepartureTime
+	if now.Before(departure.Add(-checkInOpensBefore)) {
+		return nil, ErrCheckInClosed.Withf("check-in is not open yet, it opens at %s", departure.Add(-checkInOpensBefore).Format("02 Jan 2006 15:04"))
 	}
-	if age >= 12 {
-		return models.PassengerTypeAdult
-	} else if age >= 2 {
-		return models.PassengerTypeChild
+	if now.After(departure.Add(-checkInClosesBefore)) {
+		return nil, ErrCheckInClosed.Withf("check-in closed %d hours before departure", int(checkInClosesBefore.Hours()))
 	}
-	return models.PassengerTypeInfant
-}
 
-func stringToPointer(s string) *string {
-	if s == "" {
-		return nil
+	var detailIDs []uint
+	if len(req.TicketNumbers) == 0 {
+		for _, detail := range booking.Details {
+			detailIDs = append(detailIDs, detail.ID)
+		}
+	} else {
+		detailsByTicket := make(map[string]models.BookingDetail)
+		for _, detail := range booking.Details {
+			detailsByTicket[detail.TicketNumber] = detail
+		}
+		for _, ticketNumber := range req.TicketNumbers {
+			detail, ok := detailsByTicket[ticketNumber]
+			if !ok {
+				return nil, ErrTicketNotInBooking.Withf("ticket %s is not part of this booking", ticketNumber)
+			}
+			detailIDs = append(detailIDs, detail.ID)
+		}
 	}
-	return &s
-}
 
-func dateToPointer(dateStr string) *time.Time {
-	if dateStr == "" {
-		return nil
+	if err := s.repo.CheckInPassengers(booking.FlightID, detailIDs); err != nil {
+		return nil, err
 	}
-	t, err := time.Parse("2006-01-02", dateStr)
+
+	updated, err := s.repo.GetBookingForTicket(bookingCode)
 	if err != nil {
-		return nil
+		return nil, err
 	}
-	return &t
+
+	return toPassengerDetailResponses(updated.Details, updated.Flight.FlightLegs), nil
 }
 
-func formatPaymentMethodName(code string) string {
-	code = strings.ToLower(code)
-	switch code {
-	case "va_bca":
-		return "BCA Virtual Account"
-	case "va_mandiri":
-		return "Mandiri Virtual Account"
+// DownloadBoardingPass mencetak satu boarding pass per penumpang per leg.
+// Jika ticketNumber kosong, semua penumpang yang sudah check-in ikut dicetak.
+func (s *bookingService) DownloadBoardingPass(ctx context.Context, requester *jwt.JWTClaims, bookingCode string, ticketNumber string) ([]byte, error) {
+	booking, err := s.findAccessibleBooking(requester, bookingCode)
+	if err != nil {
+		return nil, err
+	}
+
+	if booking.Status != models.BookingStatusPaid {
+		return nil, ErrInvalidBookingStatus.Withf("boarding pass is not available for booking with status %s", booking.Status)
+	}
+
+	var details []models.BookingDetail
+	for _, detail := range booking.Details {
+		if ticketNumber != "" && detail.TicketNumber != ticketNumber {
+			continue
+		}
+		if detail.CheckInStatus != models.CheckInStatusCheckedIn {
+			if ticketNumber != "" {
+				return nil, ErrNotCheckedIn
+			}
+			continue
+		}
+		details = append(details, detail)
+	}
+	if len(details) == 0 {
+		if ticketNumber != "" {
+			return nil, ErrTicketNotInBooking.Withf("ticket %s is not part of this booking", ticketNumber)
+		}
+		return nil, ErrNotCheckedIn
+	}
+
+	// Logo diunduh sekali per leg, bukan per penumpang
+	legLogos := make(map[uint]string)
+	for _, leg := range booking.Flight.FlightLegs {
+		legLogos[leg.ID] = loadAirlineLogo(leg.Airline)
+	}
+
+	var passes []pdfprinter.BoardingPass
+	for _, detail := range details {
+		for _, leg := range booking.Flight.FlightLegs {
+			seatNumber := ""
+			for _, seat := range detail.Seats {
+				if seat.IsOnLeg(leg) {
+					seatNumber = seat.SeatNumber
+				}
+			}
+
+			flightNumber := leg.FlightNumber
+			if flightNumber == "" {
+				flightNumber = booking.Flight.FlightCode
+			}
+			sequence := detail.BoardingSequenceOnLeg(leg)
+
+			airlineName, carrierCode := "", ""
+			if leg.Airline != nil {
+				airlineName = leg.Airline.Name
+				carrierCode = leg.Airline.IATA
+			}
+
+			var origin, destination models.Airport
+			if leg.OriginAirport != nil {
+				origin = *leg.OriginAirport
+			}
+			if leg.DestinationAirport != nil {
+				destination = *leg.DestinationAirport
+			}
+
+			payload := utils.BuildBCBP(utils.BCBPData{
+				PassengerName:  detail.PassengerName,
+				BookingCode:    booking.BookingCode,
+				FromAirport:    origin.Code,
+				ToAirport:      destination.Code,
+				CarrierCode:    carrierCode,
+				FlightNumber:   flightNumber,
+				FlightDate:     leg.DepartureTime,
+				SeatClass:      detail.SeatClass,
+				SeatNumber:     seatNumber,
+				SequenceNumber: sequence,
+				IsInfant:       detail.PassengerType == models.PassengerTypeInfant,
+			})
+			barcode, err := pdfprinter.GenerateQRCodeBase64(payload)
+			if err != nil {
+				return nil, fmt.Errorf("failed generate boarding pass barcode: %w", err)
+			}
+
+			seatLabel := seatNumber
+			if detail.PassengerType == models.PassengerTypeInfant {
+				seatLabel = "INF"
+			} else if seatLabel == "" {
+				seatLabel = "-"
+			}
+
+			passes = append(passes, pdfprinter.BoardingPass{
+				PassengerName: fmt.Sprintf("%s. %s", strings.ToUpper(detail.PassengerTitle), detail.PassengerName),
+				TicketNumber:  detail.TicketNumber,
+				BookingCode:   booking.BookingCode,
+				AirlineName:   airlineName,
+				AirlineLogo:   legLogos[leg.ID],
+				FlightNumber:  flightNumber,
+				FlightClass:   strings.ToUpper(detail.SeatClass),
+				Departure: pdfprinter.FlightPoint{
+					Date:        leg.DepartureTime.Format("02 Jan 2006"),
+					Time:        leg.DepartureTime.Format("15:04"),
+					CityName:    origin.CityName,
+					CityCode:    origin.Code,
+					AirportName: origin.AirportName,
+				},
+				Arrival: pdfprinter.FlightPoint{
+					Date:        leg.ArrivalTime.Format("02 Jan 2006"),
+					Time:        leg.ArrivalTime.Format("15:04"),
+					CityName:    destination.CityName,
+					CityCode:    destination.Code,
+					AirportName: destination.AirportName,
+				},
+				BoardingTime: leg.DepartureTime.Add(-boardingBeforeDeparture).Format("15:04"),
+				Seat:         seatLabel,
+				Sequence:     fmt.Sprintf("%03d", sequence),
+				Barcode:      barcode,
+			})
+		}
+	}
+
+	passData := pdfprinter.BoardingPassData{
+		HeaderImage: loadAssetBase64("eticket_header.png"),
+		Passes:      passes,
+	}
+
+	return renderPDF("boarding_pass.html", passData)
+}
+
+// GetFlightManifest menyusun daftar penumpang satu flight. Jika legID diisi,
+// rute, jadwal dan kursi yang ditampilkan hanya untuk leg tersebut.
+func (s *bookingService) GetFlightManifest(flightID uint, legID uint) (*ManifestResponse, error) {
+	flightData, err := s.flightService.GetFlightByID(flightID)
+	if err != nil {
+		return nil, ErrManifestNotFound
+	}
+
+	manifest := &ManifestResponse{
+		FlightID:      flightData.ID,
+		FlightCode:    flightData.FlightCode,
+		FlightNumber:  flightData.FlightCode,
+		DepartureTime: flightData.DepartureTime,
+		ArrivalTime:   flightData.ArrivalTime,
+		GeneratedAt:   time.Now(),
+		Passengers:    []ManifestPassengerResponse{},
+	}
+	if flightData.OriginAirport != nil && flightData.DestinationAirport != nil {
+		manifest.Route = fmt.Sprintf("%s-%s", flightData.OriginAirport.Code, flightData.DestinationAirport.Code)
+	}
+
+	legRoutes := make(map[uint]string)
+	for _, leg := range flightData.FlightLegs {
+		if leg.OriginAirport != nil && leg.DestinationAirport != nil {
+			legRoutes[leg.ID] = fmt.Sprintf("%s-%s", leg.OriginAirport.Code, leg.DestinationAirport.Code)
+		}
+	}
+
+	var selectedLeg *models.FlightLeg
+	if legID != 0 {
+		for i := range flightData.FlightLegs {
+			if flightData.FlightLegs[i].ID == legID {
+				selectedLeg = &flightData.FlightLegs[i]
+				break
+			}
+		}
+		if selectedLeg == nil {
+			return nil, ErrManifestNotFound
+		}
+
+		manifest.LegID = &selectedLeg.ID
+		if selectedLeg.FlightNumber != "" {
+			manifest.FlightNumber = selectedLeg.FlightNumber
+		}
+		manifest.Route = legRoutes[selectedLeg.ID]
+		manifest.DepartureTime = selectedLeg.DepartureTime
+		manifest.ArrivalTime = selectedLeg.ArrivalTime
+	}
+
+	bookings, err := s.repo.GetBookingsForManifest(flightID, legID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, booking := range bookings {
+		for _, detail := range booking.Details {
+			seat := formatSeatLabel(detail.Seats, booking.Flight.FlightLegs, legRoutes)
+			if selectedLeg != nil {
+				seat = "-"
+				for _, flightSeat := range detail.Seats {
+					if flightSeat.IsOnLeg(*selectedLeg) {
+						seat = flightSeat.SeatNumber
+					}
+				}
+			}
+
+			var validUntil *string
+			if detail.ValidUntil != nil {
+				validUntil = stringToPointer(detail.ValidUntil.Format("2006-01-02"))
+			}
+
+			manifest.Passengers = append(manifest.Passengers, ManifestPassengerResponse{
+				No:                 len(manifest.Passengers) + 1,
+				BookingCode:        booking.BookingCode,
+				BookingStatus:      booking.Status,
+				Title:              detail.PassengerTitle,
+				FullName:           detail.PassengerName,
+				Type:               detail.PassengerType,
+				DateOfBirth:        detail.PassengerDOB.Format("2006-01-02"),
+				Nationality:        detail.Nationality,
+				PassportNumber:     detail.PassportNumber,
+				IssuingCountry:     detail.IssuingCountry,
+				PassportValidUntil: validUntil,
+				TicketNumber:       detail.TicketNumber,
+				SeatClass:          detail.SeatClass,
+				Seat:               seat,
+				CheckInStatus:      detail.CheckInStatus,
+			})
+		}
+	}
+	manifest.TotalPassengers = len(manifest.Passengers)
+
+	return manifest, nil
+}
+
+func (s *bookingService) ExportManifestCSV(flightID uint, legID uint) ([]byte, error) {
+	manifest, err := s.GetFlightManifest(flightID, legID)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{
+		"No", "Booking Code", "Booking Status", "Title", "Full Name", "Type", "Date of Birth",
+		"Nationality", "Passport Number", "Issuing Country", "Passport Valid Until",
+		"Ticket Number", "Seat Class", "Seat", "Check-in Status",
+	}
+	if err := writer.Write(header); err != nil {
+		return nil, err
+	}
+
+	for _, p := range manifest.Passengers {
+		row := []string{
+			strconv.Itoa(p.No), p.BookingCode, p.BookingStatus, p.Title, p.FullName, p.Type, p.DateOfBirth,
+			p.Nationality, derefString(p.PassportNumber), derefString(p.IssuingCountry), derefString(p.PassportValidUntil),
+			p.TicketNumber, p.SeatClass, p.Seat, p.CheckInStatus,
+		}
+		for i := range row {
+			row[i] = escapeCSVFormula(row[i])
+		}
+		if err := writer.Write(row); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (s *bookingService) ExportManifestPDF(ctx context.Context, flightID uint, legID uint) ([]byte, error) {
+	manifest, err := s.GetFlightManifest(flightID, legID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []pdfprinter.ManifestRow
+	for _, p := range manifest.Passengers {
+		passport := "-"
+		if p.PassportNumber != nil && *p.PassportNumber != "" {
+			passport = fmt.Sprintf("%s / %s / %s", *p.PassportNumber, derefString(p.IssuingCountry), derefString(p.PassportValidUntil))
+		}
+
+		rows = append(rows, pdfprinter.ManifestRow{
+			Number:        p.No,
+			Name:          fmt.Sprintf("%s. %s", strings.ToUpper(p.Title), p.FullName),
+			Type:          p.Type,
+			DateOfBirth:   p.DateOfBirth,
+			Nationality:   p.Nationality,
+			Passport:      passport,
+			TicketNumber:  p.TicketNumber,
+			BookingCode:   p.BookingCode,
+			BookingStatus: strings.ToUpper(p.BookingStatus),
+			SeatClass:     p.SeatClass,
+			Seat:          p.Seat,
+			CheckInStatus: p.CheckInStatus,
+		})
+	}
+
+	manifestData := pdfprinter.ManifestData{
+		FlightCode:      manifest.FlightCode,
+		FlightNumber:    manifest.FlightNumber,
+		Route:           manifest.Route,
+		DepartureTime:   manifest.DepartureTime.Format("02 Jan 2006, 15:04"),
+		ArrivalTime:     manifest.ArrivalTime.Format("02 Jan 2006, 15:04"),
+		GeneratedAt:     manifest.GeneratedAt.Format("02 Jan 2006, 15:04"),
+		TotalPassengers: manifest.TotalPassengers,
+		Passengers:      rows,
+	}
+
+	return renderPDF("manifest.html", manifestData)
+}
+
+func (s *bookingService) QuoteReschedule(userID uint, bookingCode string, req RescheduleRequest) (*BookingChangeResponse, error) {
+	change, booking, err := s.prepareBookingChange(userID, bookingCode, req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := toBookingChangeResponse(*change, booking.BookingCode, passengerNames(booking.Details))
+	return &resp, nil
+}
+
+func (s *bookingService) RescheduleBooking(userID uint, bookingCode string, req RescheduleRequest) (*BookingChangeResponse, error) {
+	change, booking, err := s.prepareBookingChange(userID, bookingCode, req)
+	if err != nil {
+		return nil, err
+	}
+
+	expiryAt := time.Now().Add(rescheduleExpiry)
+	change.ExpiredAt = &expiryAt
+
+	if err := s.repo.CreateBookingChange(change, seatedPassengerCount(booking.Details)); err != nil {
+		return nil, err
+	}
+
+	// Tanpa tagihan tambahan perubahan langsung diterapkan; kursi yang sudah
+	// ditahan akan dilepas job expiry jika langkah ini gagal.
+	if !change.AmountDue.IsPositive() {
+		if err := s.repo.CompleteBookingChange(change.ChangeCode); err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		change.Status = models.BookingChangeStatusCompleted
+		change.CompletedAt = &now
+	}
+
+	resp := toBookingChangeResponse(*change, booking.BookingCode, passengerNames(booking.Details))
+	return &resp, nil
+}
+
+func (s *bookingService) GetOrderChanges(userID uint, orderID string) ([]BookingChangeResponse, error) {
+	bookings, err := s.repo.FindBookingsByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+	if len(bookings) == 0 || bookings[0].UserID != userID {
+		return nil, ErrBookingNotFound
+	}
+
+	bookingCodes := make(map[uint]string)
+	var details []models.BookingDetail
+	for _, booking := range bookings {
+		bookingCodes[booking.ID] = booking.BookingCode
+		details = append(details, booking.Details...)
+	}
+	names := passengerNames(details)
+
+	changes, err := s.repo.GetBookingChangesByOrderID(orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []BookingChangeResponse{}
+	for _, change := range changes {
+		responses = append(responses, toBookingChangeResponse(change, bookingCodes[change.BookingID], names))
+	}
+	return responses, nil
+}
+
+// prepareBookingChange menghitung tarif baru setiap penumpang, selisih tarif,
+// dan biaya perubahan tanpa menyimpan apa pun.
+func (s *bookingService) prepareBookingChange(userID uint, bookingCode string, req RescheduleRequest) (*models.BookingChange, *models.Booking, error) {
+	booking, err := s.repo.GetBookingForTicket(bookingCode)
+	if err != nil || booking.UserID != userID {
+		return nil, nil, ErrBookingNotFound
+	}
+	if booking.Status != models.BookingStatusPaid {
+		return nil, nil, ErrInvalidBookingStatus.Withf("booking with status %s cannot be rescheduled", booking.Status)
+	}
+	if len(booking.Details) == 0 {
+		return nil, nil, fmt.Errorf("booking %s has no passengers", booking.BookingCode)
+	}
+
+	newFlight, err := s.flightService.GetFlightByID(req.NewFlightID)
+	if err != nil {
+		return nil, nil, flight.ErrFlightNotFound.Withf("new flight not found")
+	}
+
+	now := time.Now()
+	if newFlight.DepartureTime.Before(now) {
+		return nil, nil, ErrFlightDeparted.Withf("flight %s has already departed", newFlight.FlightCode)
+	}
+	if newFlight.OriginAirportID != booking.Flight.OriginAirportID || newFlight.DestinationAirportID != booking.Flight.DestinationAirportID {
+		return nil, nil, ErrInvalidBookingRequest.Withf("new flight must have the same origin and destination")
+	}
+	if err := s.validateChangedItinerary(booking, *newFlight); err != nil {
+		return nil, nil, err
+	}
+
+	oldSeatClass := booking.Details[0].SeatClass
+	newSeatClass := req.SeatClass
+	if strings.TrimSpace(newSeatClass) == "" {
+		newSeatClass = oldSeatClass
+	}
+
+	var selectedClass *models.FlightClass
+	for _, fc := range newFlight.FlightClasses {
+		if strings.EqualFold(fc.SeatClass, newSeatClass) {
+			selectedClass = &fc
+			break
+		}
+	}
+	if selectedClass == nil {
+		return nil, nil, ErrSeatClassUnavailable
+	}
+	if newFlight.ID == booking.FlightID && strings.EqualFold(selectedClass.SeatClass, oldSeatClass) {
+		return nil, nil, ErrInvalidBookingRequest.Withf("booking is already on this flight and seat class")
+	}
+
+	changeFee, err := calculateRescheduleFee(*booking, now)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	change := &models.BookingChange{
+		ChangeCode:   fmt.Sprintf("%s%s-%s", models.BookingChangeCodePrefix, now.Format("20060102"), generateRandomString(4)),
+		OrderID:      booking.OrderID,
+		BookingID:    booking.ID,
+		UserID:       userID,
+		OldFlightID:  booking.FlightID,
+		OldFlight:    booking.Flight,
+		NewFlightID:  newFlight.ID,
+		NewFlight:    *newFlight,
+		OldSeatClass: oldSeatClass,
+		NewSeatClass: selectedClass.SeatClass,
+		ChangeFee:    changeFee,
+		Status:       models.BookingChangeStatusPendingPayment,
+	}
+
+	for _, detail := range booking.Details {
+		// Tipe penumpang menentukan tarif dan kursi (bayi dipangku), jadi
+		// reschedule yang mengubah tipe harus dipesan ulang.
+		if passengerType := calculatePassengerType(detail.PassengerDOB, newFlight.DepartureTime); passengerType != detail.PassengerType {
+			return nil, nil, ErrInvalidBookingRequest.Withf("passenger %s would travel as %s on the new flight, please make a new booking", detail.PassengerName, passengerType)
+		}
+
+		charges, err := s.pricingService.QuotePassenger(*newFlight, detail.PassengerType, flight.PassengerFare(*selectedClass, detail.PassengerType))
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to calculate fare: %w", err)
+		}
+
+		change.OldFareTotal = change.OldFareTotal.Add(detail.Price)
+		change.NewFareTotal = change.NewFareTotal.Add(charges.Total)
+		change.Passengers = append(change.Passengers, models.BookingChangePassenger{
+			BookingDetailID: detail.ID,
+			OldTicketNumber: detail.TicketNumber,
+			NewTicketNumber: fmt.Sprintf("%s-%s", booking.BookingCode, generateRandomString(3)),
+			BaseFare:        charges.BaseFare,
+			AirportTax:      charges.AirportTax,
+			VATAmount:       charges.VAT,
+			Price:           charges.Total,
+		})
+	}
+
+	// Pindah ke tarif lebih murah tidak menghasilkan refund selisih
+	change.FareDifference = change.NewFareTotal.Sub(change.OldFareTotal)
+	change.AmountDue = changeFee
+	if change.FareDifference.IsPositive() {
+		change.AmountDue = change.AmountDue.Add(change.FareDifference)
+	}
+
+	return change, booking, nil
+}
+
+// validateChangedItinerary menukar penerbangan booking dengan penerbangan baru
+// di antara booking lain dalam order yang sama, lalu memeriksa ulang urutan
+// perjalanannya. Tanpa ini penerbangan pergi bisa dipindah ke setelah
+// penerbangan pulang, atau jeda transit menjadi kurang dari MinConnectionTime.
+func (s *bookingService) validateChangedItinerary(booking *models.Booking, newFlight models.Flight) error {
+	bookings, err := s.repo.FindBookingsByOrderID(booking.OrderID)
+	if err != nil {
+		return fmt.Errorf("failed to load order %s: %w", booking.OrderID, err)
+	}
+
+	var itineraryFlights []models.Flight
+	for _, b := range bookings {
+		if b.Status == models.BookingStatusCancelled {
+			continue
+		}
+		if b.ID == booking.ID {
+			itineraryFlights = append(itineraryFlights, newFlight)
+			continue
+		}
+		itineraryFlights = append(itineraryFlights, b.Flight)
+	}
+
+	// Jika sebagian booking sudah dibatalkan, bentuk perjalanan awal tidak
+	// berlaku lagi sehingga jenisnya ditebak dari penerbangan yang tersisa.
+	tripType := booking.TripType
+	if tripType == "" || len(itineraryFlights) != len(bookings) {
+		tripType = flight.InferTripType(itineraryFlights)
+	}
+
+	return flight.ValidateItinerary(tripType, itineraryFlights)
+}
+
+func (s *bookingService) expireBookingChanges() {
+	changes, err := s.repo.GetExpiredBookingChanges(time.Now())
+	if err != nil {
+		log.Printf("[CRON] Error fetching expired reschedules: %v\n", err)
+		return
+	}
+
+	for i := range changes {
+		change := &changes[i]
+
+		paid, err := s.paymentService.ReconcileOrder(change.ChangeCode)
+		if err != nil {
+			log.Printf("[CRON] Failed to reconcile reschedule %s before expiry: %v\n", change.ChangeCode, err)
+		}
+		if paid {
+			log.Printf("[CRON] Skipped reschedule %s, payment settled at gateway.\n", change.ChangeCode)
+			continue
+		}
+
+		if err := s.repo.ExpireBookingChange(change); err != nil {
+			log.Printf("[CRON] Failed to expire reschedule %s: %v\n", change.ChangeCode, err)
+			continue
+		}
+
+		if err := s.paymentService.CancelPayment(change.ChangeCode); err != nil {
+			log.Printf("[CRON] No pending payment cancelled for reschedule %s: %v\n", change.ChangeCode, err)
+		}
+		log.Printf("[CRON] Expired reschedule %s (held seats released).\n", change.ChangeCode)
+	}
+}
+
+// buildSeatAssignments memastikan setiap kursi yang diminta berada di leg milik
+// penerbangan tersebut dan maksimal satu kursi per leg untuk satu penumpang.
+func buildSeatAssignments(flightData *models.Flight, seatClass string, seats []PassengerSeatRequest) ([]SeatAssignment, error) {
+	legIDs := make(map[uint]bool)
+	for _, leg := range flightData.FlightLegs {
+		legIDs[leg.ID] = true
+	}
+
+	usedLegs := make(map[uint]bool)
+	var assignments []SeatAssignment
+	for _, seat := range seats {
+		if !legIDs[seat.FlightLegID] {
+			return nil, ErrInvalidBookingRequest.Withf("flight leg %d does not belong to flight %s", seat.FlightLegID, flightData.FlightCode)
+		}
+		if usedLegs[seat.FlightLegID] {
+			return nil, ErrInvalidBookingRequest.Withf("a passenger can only have one seat per flight leg")
+		}
+		usedLegs[seat.FlightLegID] = true
+
+		seatNumber := strings.ToUpper(strings.TrimSpace(seat.SeatNumber))
+		if seatNumber == "" {
+			return nil, ErrInvalidBookingRequest.Withf("seat number is required")
+		}
+
+		assignments = append(assignments, SeatAssignment{
+			FlightLegID: seat.FlightLegID,
+			SeatNumber:  seatNumber,
+			SeatClass:   strings.ToLower(seatClass),
+		})
+	}
+
+	return assignments, nil
+}
+
+func toPassengerDetailResponses(details []models.BookingDetail, legs []models.FlightLeg) []PassengerDetailResponse {
+	var passengerList []PassengerDetailResponse
+	for _, detail := range details {
+		seats := []PassengerSeatResponse{}
+		for _, seat := range detail.Seats {
+			seats = append(seats, PassengerSeatResponse{
+				FlightLegID: seatLegID(seat, legs),
+				SeatNumber:  seat.SeatNumber,
+			})
+		}
+
+		passengerList = append(passengerList, PassengerDetailResponse{
+			FullName:      detail.PassengerName,
+			Type:          detail.PassengerType,
+			TicketNumber:  detail.TicketNumber,
+			SeatClass:     detail.SeatClass,
+			Seats:         seats,
+			CheckInStatus: detail.CheckInStatus,
+			CheckedInAt:   detail.CheckedInAt,
+		})
+	}
+	return passengerList
+}
+
+// seatLegID mencari leg flight booking tempat kursi berada. Kursi disimpan per
+// leg fisik, jadi id leg-nya bergantung pada flight yang sedang dilihat.
+func seatLegID(seat models.FlightSeat, legs []models.FlightLeg) uint {
+	for _, leg := range legs {
+		if seat.IsOnLeg(leg) {
+			return leg.ID
+		}
+	}
+	return 0
+}
+
+func formatSeatLabel(seats []models.FlightSeat, legs []models.FlightLeg, legRoutes map[uint]string) string {
+	if len(seats) == 0 {
+		return "-"
+	}
+
+	var labels []string
+	for _, seat := range seats {
+		if route, ok := legRoutes[seatLegID(seat, legs)]; ok && len(legRoutes) > 1 {
+			labels = append(labels, fmt.Sprintf("%s %s", route, seat.SeatNumber))
+			continue
+		}
+		labels = append(labels, seat.SeatNumber)
+	}
+	return strings.Join(labels, ", ")
+}
+
+// refundPart adalah bagian refund yang ditarik dari satu pembayaran.
+type refundPart struct {
+	OrderID   string
+	RefundKey string
+	Amount    decimal.Decimal
+}
+
+// splitRefund membagi refund ke pembayaran order awal dan pembayaran
+// reschedule yang sudah diterapkan. Tagihan reschedule dikembalikan lebih
+// dulu, sisanya (setelah dipotong biaya pembatalan) dari pembayaran awal.
+func splitRefund(orderID string, refundKey string, amount decimal.Decimal, changes []models.BookingChange) []refundPart {
+	var parts []refundPart
+	remaining := amount
+
+	for _, change := range changes {
+		if !remaining.IsPositive() {
+			break
+		}
+		// Reschedule tanpa tagihan diterapkan langsung tanpa pembayaran
+		if change.Status != models.BookingChangeStatusCompleted || !change.AmountDue.IsPositive() {
+			continue
+		}
+
+		partAmount := decimal.Min(change.AmountDue, remaining)
+		parts = append(parts, refundPart{
+			OrderID:   change.ChangeCode,
+			RefundKey: fmt.Sprintf("%s-%d", refundKey, len(parts)+1),
+			Amount:    partAmount,
+		})
+		remaining = remaining.Sub(partAmount)
+	}
+
+	if remaining.IsPositive() {
+		parts = append(parts, refundPart{OrderID: orderID, RefundKey: refundKey, Amount: remaining})
+	}
+	return parts
+}
+
+func calculateCancellationFee(booking models.Booking, now time.Time) (decimal.Decimal, error) {
+	if booking.Flight.ID == 0 {
+		return decimal.Zero, fmt.Errorf("flight data not found for booking %s", booking.BookingCode)
+	}
+
+	seatClass := "economy"
+	if len(booking.Details) > 0 {
+		seatClass = strings.ToLower(booking.Details[0].SeatClass)
+	}
+
+	rules, ok := cancellationRules[seatClass]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("no cancellation rule for seat class %s", seatClass)
+	}
+
+	hoursLeft := booking.Flight.DepartureTime.Sub(now).Hours()
+	for _, rule := range rules {
+		if hoursLeft >= rule.MinHoursBeforeDeparture {
+			return booking.TotalPrice.Mul(decimal.NewFromInt(rule.FeePercent)).Div(decimal.NewFromInt(100)).Round(2), nil
+		}
+	}
+
+	return decimal.Zero, ErrChangeWindowClosed.Withf("booking %s can no longer be cancelled this close to departure", booking.BookingCode)
+}
+
+func calculateRescheduleFee(booking models.Booking, now time.Time) (decimal.Decimal, error) {
+	seatClass := strings.ToLower(booking.Details[0].SeatClass)
+	rules, ok := rescheduleRules[seatClass]
+	if !ok {
+		return decimal.Zero, fmt.Errorf("no reschedule rule for seat class %s", seatClass)
+	}
+
+	passengerCount := decimal.NewFromInt(int64(seatedPassengerCount(booking.Details)))
+	hoursLeft := booking.Flight.DepartureTime.Sub(now).Hours()
+	for _, rule := range rules {
+		if hoursLeft >= rule.MinHoursBeforeDeparture {
+			return decimal.NewFromInt(rule.FeePerPassenger).Mul(passengerCount), nil
+		}
+	}
+
+	return decimal.Zero, ErrChangeWindowClosed.Withf("booking %s can no longer be rescheduled this close to departure", booking.BookingCode)
+}
+
+func toBookingChangeResponse(change models.BookingChange, bookingCode string, names map[uint]string) BookingChangeResponse {
+	resp := BookingChangeResponse{
+		ChangeCode:       change.ChangeCode,
+		BookingCode:      bookingCode,
+		Status:           change.Status,
+		OldFlightCode:    change.OldFlight.FlightCode,
+		NewFlightCode:    change.NewFlight.FlightCode,
+		OldDepartureTime: change.OldFlight.DepartureTime,
+		NewDepartureTime: change.NewFlight.DepartureTime,
+		OldSeatClass:     change.OldSeatClass,
+		NewSeatClass:     change.NewSeatClass,
+		OldFareTotal:     change.OldFareTotal,
+		NewFareTotal:     change.NewFareTotal,
+		FareDifference:   change.FareDifference,
+		ChangeFee:        change.ChangeFee,
+		AmountDue:        change.AmountDue,
+		CompletedAt:      change.CompletedAt,
+		Tickets:          []ReissuedTicketResponse{},
+	}
+
+	if change.ID != 0 {
+		createdAt := change.CreatedAt
+		resp.CreatedAt = &createdAt
+	} else {
+		// Quote belum disimpan sehingga belum punya kode maupun status
+		resp.ChangeCode = ""
+		resp.Status = ""
+	}
+
+	if change.Status == models.BookingChangeStatusPendingPayment && change.ID != 0 {
+		resp.PaymentOrderID = change.ChangeCode
+		resp.ExpiryTime = change.ExpiredAt
+	}
+
+	for _, passenger := range change.Passengers {
+		newTicketNumber := passenger.NewTicketNumber
+		if change.ID == 0 {
+			newTicketNumber = ""
+		}
+		resp.Tickets = append(resp.Tickets, ReissuedTicketResponse{
+			PassengerName:   names[passenger.BookingDetailID],
+			OldTicketNumber: passenger.OldTicketNumber,
+			NewTicketNumber: newTicketNumber,
+			Price:           passenger.Price,
+		})
+	}
+
+	return resp
+}
+
+func passengerNames(details []models.BookingDetail) map[uint]string {
+	names := make(map[uint]string)
+	for _, detail := range details {
+		names[detail.ID] = detail.PassengerName
+	}
+	return names
+}
+
+func generateRandomString(n int) string {
+	const letterBytes = "ABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = letterBytes[rand.Intn(len(letterBytes))]
+	}
+	return string(b)
+}
+
+func generatePNR() string {
+	return fmt.Sprintf("%s", generateRandomString(6))
+}
+
+func toPriceBreakdown(booking models.Booking) PriceBreakdownResponse {
+	return PriceBreakdownResponse{
+		BaseFare:       booking.BaseFare,
+		AirportTax:     booking.AirportTax,
+		VAT:            booking.VATAmount,
+		Discount:       booking.DiscountAmount,
+		ConvenienceFee: booking.ConvenienceFee,
+		ChangeFee:      booking.ChangeFee,
+		RetainedFare:   booking.RetainedFareDifference,
+		Total:          booking.TotalPrice,
+	}
+}
+
+func sumPriceBreakdowns(breakdowns []PriceBreakdownResponse) PriceBreakdownResponse {
+	total := PriceBreakdownResponse{}
+	for _, b := range breakdowns {
+		total.BaseFare = total.BaseFare.Add(b.BaseFare)
+		total.AirportTax = total.AirportTax.Add(b.AirportTax)
+		total.VAT = total.VAT.Add(b.VAT)
+		total.Discount = total.Discount.Add(b.Discount)
+		total.ConvenienceFee = total.ConvenienceFee.Add(b.ConvenienceFee)
+		total.ChangeFee = total.ChangeFee.Add(b.ChangeFee)
+		total.RetainedFare = total.RetainedFare.Add(b.RetainedFare)
+		total.Total = total.Total.Add(b.Total)
+	}
+	return total
+}
+
+func toPassengerPrices(details []models.BookingDetail) []PassengerPriceResponse {
+	var prices []PassengerPriceResponse
+	for _, detail := range details {
+		prices = append(prices, PassengerPriceResponse{
+			FullName:   detail.PassengerName,
+			Type:       detail.PassengerType,
+			BaseFare:   detail.BaseFare,
+			AirportTax: detail.AirportTax,
+			VAT:        detail.VATAmount,
+			Total:      detail.Price,
+		})
+	}
+	return prices
+}
+
+// resolvePassengers mengisi data penumpang dari traveler tersimpan (jika
+// traveler_id dikirim) dan memastikan paspor berlaku sampai hari keberangkatan.
+func (s *bookingService) resolvePassengers(userID uint, passengers []PassengerRequest, departureTime time.Time) ([]PassengerRequest, error) {
+	resolved := make([]PassengerRequest, 0, len(passengers))
+	for _, p := range passengers {
+		if p.TravelerID != nil {
+			saved, err := s.travelerService.GetTraveler(userID, *p.TravelerID)
+			if err != nil {
+				return nil, ErrTravelerNotFound.Withf("saved traveler %d not found", *p.TravelerID)
+			}
+
+			p.Title = saved.Title
+			p.FullName = saved.FullName
+			p.DOB = saved.DOB.Format("2006-01-02")
+			p.Nationality = saved.Nationality
+			p.PassportNumber = ""
+			p.IssuingCountry = ""
+			p.ValidUntil = ""
+			if saved.PassportNumber != nil {
+				p.PassportNumber = *saved.PassportNumber
+			}
+			if saved.IssuingCountry != nil {
+				p.IssuingCountry = *saved.IssuingCountry
+			}
+			if saved.ValidUntil != nil {
+				p.ValidUntil = saved.ValidUntil.Format("2006-01-02")
+			}
+		}
+
+		if err := traveler.ValidatePassport(stringToPointer(p.PassportNumber), dateToPointer(p.ValidUntil), departureTime); err != nil {
+			appErr := apperror.From(err)
+			return nil, appErr.Withf("passenger %s: %s", p.FullName, appErr.Message)
+		}
+
+		resolved = append(resolved, p)
+	}
+	return resolved, nil
+}
+
+// validateInfantRatio memastikan setiap bayi dipangku oleh satu penumpang dewasa.
+func validateInfantRatio(passengers []PassengerRequest, departureTime time.Time) error {
+	adults, infants := 0, 0
+	for _, p := range passengers {
+		dob, _ := time.Parse("2006-01-02", p.DOB)
+		switch calculatePassengerType(dob, departureTime) {
+		case models.PassengerTypeAdult:
+			adults++
+		case models.PassengerTypeInfant:
+			infants++
+		}
+	}
+
+	if infants > adults {
+		return ErrInvalidPassenger.Withf("each infant must be accompanied by one adult")
+	}
+	return nil
+}
+
+// calculatePassengerType menentukan tipe penumpang dari usia pada hari
+// keberangkatan, bukan hari pemesanan. Perbandingan bulan dan tanggal
+// (bukan YearDay) agar tidak meleset satu hari di tahun kabisat.
+func calculatePassengerType(dob time.Time, departureTime time.Time) string {
+	age := departureTime.Year() - dob.Year()
+	if departureTime.Month() < dob.Month() || (departureTime.Month() == dob.Month() && departureTime.Day() < dob.Day()) {
+		age--
+	}
+	if age >= 12 {
+		return models.PassengerTypeAdult
+	} else if age >= 2 {
+		return models.PassengerTypeChild
+	}
+	return models.PassengerTypeInfant
+}
+
+func stringToPointer(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// escapeCSVFormula mencegah formula injection: nama penumpang seperti
+// "=HYPERLINK(...)" akan dieksekusi spreadsheet jika tidak diawali petik.
+// Sel satu karakter seperti "-" (kursi belum dipilih) dibiarkan apa adanya.
+func escapeCSVFormula(cell string) string {
+	if len(cell) > 1 && strings.ContainsRune("=+-@\t\r", rune(cell[0])) {
+		return "'" + cell
+	}
+	return cell
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func dateToPointer(dateStr string) *time.Time {
+	if dateStr == "" {
+		return nil
+	}
+	t, err := time.Parse("2006-01-02", dateStr)
+	if err != nil {
+		return nil
+	}
+	return &t
+}
+
+func formatPaymentMethodName(code string) string {
+	code = strings.ToLower(code)
+	switch code {
+	case "va_bca":
+		return "BCA Virtual Account"
+	case "va_mandiri":
+		return "Mandiri Virtual Account"
 	case "va_bri":
 		return "BRI Virtual Account"
 	case "ewallet_gopay":
@@ -608,6 +2001,58 @@ func formatPaymentMethodName(code string) string {
 	}
 }
 
+// renderPDF merender template ke file sementara lalu membacanya kembali.
+// os.CreateTemp memberi nama unik sehingga dua request untuk dokumen yang
+// sama di detik yang sama tidak saling menimpa.
+func renderPDF(templateName string, data interface{}) ([]byte, error) {
+	tmpFile, err := os.CreateTemp("", "ezytix-*.pdf")
+	if err != nil {
+		return nil, fmt.Errorf("failed create temp file: %w", err)
+	}
+	tmpFilePath := tmpFile.Name()
+	tmpFile.Close()
+	defer os.Remove(tmpFilePath)
+
+	if err := pdfprinter.GeneratePDF(templateName, data, tmpFilePath); err != nil {
+		return nil, fmt.Errorf("failed generate pdf: %w", err)
+	}
+
+	pdfBytes, err := os.ReadFile(tmpFilePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed read pdf file: %w", err)
+	}
+	return pdfBytes, nil
+}
+
+// loadAssetBase64 membaca gambar dari internal/assets/images. Gambar yang
+// gagal dibaca dikosongkan agar dokumen tetap bisa dicetak.
+func loadAssetBase64(name string) string {
+	cwd, err := os.Getwd()
+	if err != nil {
+		log.Printf("[PDF] Failed to get working directory: %v\n", err)
+		return ""
+	}
+
+	b64, err := pdfprinter.ImageToBase64(filepath.Join(cwd, "internal", "assets", "images", name))
+	if err != nil {
+		log.Printf("[PDF] Failed to load asset %s: %v\n", name, err)
+		return ""
+	}
+	return b64
+}
+
+// loadAirlineLogo mengunduh logo maskapai dan memakai logo bawaan jika gagal.
+func loadAirlineLogo(airline *models.Airline) string {
+	if airline != nil && airline.LogoURL != "" {
+		remoteB64, err := downloadImageToBase64(airline.LogoURL)
+		if err == nil && remoteB64 != "" {
+			return remoteB64
+		}
+		log.Printf("[PDF] Failed to download airline logo %s, using default: %v\n", airline.LogoURL, err)
+	}
+	return loadAssetBase64("Lion.png")
+}
+
 func downloadImageToBase64(url string) (string, error) {
 	resp, err := http.Get(url)
 	if err != nil {
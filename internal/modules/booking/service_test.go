@@ -0,0 +1,243 @@
+package booking
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"testing"
+	"time"
+
+	"ezytix-be/internal/models"
+	"ezytix-be/internal/modules/flight"
+	"ezytix-be/pkg/jwt"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ownerID = 10
+	otherID = 20
+)
+
+// errPaymentLookup menghentikan DownloadInvoice sebelum PDF dibuat; test cukup
+// memastikan request lolos pengecekan kepemilikan.
+var errPaymentLookup = errors.New("payment lookup reached")
+
+// fakeBookingRepository hanya mengimplementasikan method yang dipakai test.
+// Method lain panic karena interface yang di-embed bernilai nil.
+type fakeBookingRepository struct {
+	BookingRepository
+	bookings map[string]models.Booking
+}
+
+func newFakeBookingRepository() *fakeBookingRepository {
+	booking := models.Booking{ID: 1, UserID: ownerID, OrderID: "ORD-1", BookingCode: "BK-1", Status: models.BookingStatusPaid}
+	return &fakeBookingRepository{bookings: map[string]models.Booking{booking.BookingCode: booking}}
+}
+
+func (r *fakeBookingRepository) GetBookingForTicket(bookingCode string) (*models.Booking, error) {
+	booking, ok := r.bookings[bookingCode]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return &booking, nil
+}
+
+func (r *fakeBookingRepository) GetBookingsForInvoiceByOrderID(orderID string) ([]models.Booking, error) {
+	var bookings []models.Booking
+	for _, booking := range r.bookings {
+		if booking.OrderID == orderID {
+			bookings = append(bookings, booking)
+		}
+	}
+	return bookings, nil
+}
+
+func (r *fakeBookingRepository) FindBookingsByOrderID(orderID string) ([]models.Booking, error) {
+	var bookings []models.Booking
+	for _, booking := range r.bookings {
+		if booking.OrderID == orderID {
+			bookings = append(bookings, booking)
+		}
+	}
+	sort.Slice(bookings, func(i, j int) bool { return bookings[i].ID < bookings[j].ID })
+	return bookings, nil
+}
+
+func (r *fakeBookingRepository) GetPaymentByOrderID(orderID string) (*models.Payment, error) {
+	return nil, errPaymentLookup
+}
+
+func newTestBookingService() *bookingService {
+	return &bookingService{repo: newFakeBookingRepository()}
+}
+
+var (
+	owner    = &jwt.JWTClaims{UserID: ownerID, Role: "user"}
+	stranger = &jwt.JWTClaims{UserID: otherID, Role: "user"}
+	admin    = &jwt.JWTClaims{UserID: otherID, Role: jwt.RoleAdmin}
+)
+
+func TestDownloadInvoiceHidesOrdersOfOtherUsers(t *testing.T) {
+	s := newTestBookingService()
+
+	if _, err := s.DownloadInvoice(context.Background(), stranger, "ORD-1"); !errors.Is(err, ErrBookingNotFound) {
+		t.Fatalf("stranger: expected ErrBookingNotFound, got %v", err)
+	}
+	if _, err := s.DownloadInvoice(context.Background(), owner, "ORD-404"); !errors.Is(err, ErrBookingNotFound) {
+		t.Fatalf("unknown order: expected ErrBookingNotFound, got %v", err)
+	}
+}
+
+func TestDownloadInvoiceAllowsOwnerAndAdmin(t *testing.T) {
+	s := newTestBookingService()
+
+	for name, requester := range map[string]*jwt.JWTClaims{"owner": owner, "admin": admin} {
+		if _, err := s.DownloadInvoice(context.Background(), requester, "ORD-1"); !errors.Is(err, errPaymentLookup) {
+			t.Errorf("%s: expected to pass the ownership check, got %v", name, err)
+		}
+	}
+}
+
+func TestDownloadEticketHidesBookingsOfOtherUsers(t *testing.T) {
+	s := newTestBookingService()
+
+	if _, err := s.DownloadEticket(context.Background(), stranger, "BK-1"); !errors.Is(err, ErrBookingNotFound) {
+		t.Fatalf("stranger: expected ErrBookingNotFound, got %v", err)
+	}
+	if _, err := s.DownloadEticket(context.Background(), nil, "BK-1"); !errors.Is(err, ErrBookingNotFound) {
+		t.Fatalf("anonymous: expected ErrBookingNotFound, got %v", err)
+	}
+}
+
+func TestFindAccessibleBooking(t *testing.T) {
+	s := newTestBookingService()
+
+	tests := []struct {
+		name        string
+		requester   *jwt.JWTClaims
+		bookingCode string
+		wantErr     error
+	}{
+		{name: "owner", requester: owner, bookingCode: "BK-1"},
+		{name: "admin", requester: admin, bookingCode: "BK-1"},
+		{name: "stranger", requester: stranger, bookingCode: "BK-1", wantErr: ErrBookingNotFound},
+		{name: "unknown booking", requester: owner, bookingCode: "BK-404", wantErr: ErrBookingNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking, err := s.findAccessibleBooking(tt.requester, tt.bookingCode)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("expected %v, got %v", tt.wantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if booking.BookingCode != tt.bookingCode {
+				t.Fatalf("expected booking %s, got %s", tt.bookingCode, booking.BookingCode)
+			}
+		})
+	}
+}
+
+func TestValidateChangedItinerary(t *testing.T) {
+	day := time.Date(2026, 12, 1, 8, 0, 0, 0, time.UTC)
+	newFlight := func(id uint, origin uint, destination uint, departure time.Time) models.Flight {
+		return models.Flight{
+			ID:                   id,
+			FlightCode:           fmt.Sprintf("FL-%d", id),
+			OriginAirportID:      origin,
+			DestinationAirportID: destination,
+			DepartureTime:        departure,
+			ArrivalTime:          departure.Add(2 * time.Hour),
+		}
+	}
+	outbound := newFlight(1, 1, 2, day)
+	inbound := newFlight(2, 2, 1, day.Add(72*time.Hour))
+
+	repo := &fakeBookingRepository{bookings: map[string]models.Booking{
+		"BK-OUT": {ID: 1, OrderID: "ORD-RT", BookingCode: "BK-OUT", TripType: models.TripTypeRoundTrip, Status: models.BookingStatusPaid, FlightID: outbound.ID, Flight: outbound},
+		"BK-IN":  {ID: 2, OrderID: "ORD-RT", BookingCode: "BK-IN", TripType: models.TripTypeRoundTrip, Status: models.BookingStatusPaid, FlightID: inbound.ID, Flight: inbound},
+	}}
+	s := &bookingService{repo: repo}
+
+	tests := []struct {
+		name        string
+		bookingCode string
+		newFlight   models.Flight
+		wantErr     error
+	}{
+		{name: "outbound a day later", bookingCode: "BK-OUT", newFlight: newFlight(3, 1, 2, day.Add(24*time.Hour))},
+		{name: "outbound after return", bookingCode: "BK-OUT", newFlight: newFlight(3, 1, 2, day.Add(96*time.Hour)), wantErr: flight.ErrInvalidItinerary},
+		{name: "return before outbound lands", bookingCode: "BK-IN", newFlight: newFlight(3, 2, 1, day.Add(2*time.Hour)), wantErr: flight.ErrInvalidItinerary},
+		{name: "outbound onto the return flight", bookingCode: "BK-OUT", newFlight: inbound, wantErr: flight.ErrInvalidItinerary},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			booking := repo.bookings[tt.bookingCode]
+			err := s.validateChangedItinerary(&booking, tt.newFlight)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestEscapeCSVFormula(t *testing.T) {
+	tests := map[string]string{
+		"=HYPERLINK(\"http://evil\")": "'=HYPERLINK(\"http://evil\")",
+		"+6281234":                    "'+6281234",
+		"-2+3":                        "'-2+3",
+		"@SUM(A1)":                    "'@SUM(A1)",
+		"\t=1":                        "'\t=1",
+		"BUDI SANTOSO":                "BUDI SANTOSO",
+		"-":                           "-",
+		"":                            "",
+	}
+
+	for cell, want := range tests {
+		if got := escapeCSVFormula(cell); got != want {
+			t.Errorf("escapeCSVFormula(%q) = %q, want %q", cell, got, want)
+		}
+	}
+}
+
+func TestCalculatePassengerType(t *testing.T) {
+	date := func(value string) time.Time {
+		d, _ := time.Parse("2006-01-02", value)
+		return d
+	}
+
+	tests := []struct {
+		name      string
+		dob       string
+		departure string
+		want      string
+	}{
+		{name: "infant a day before second birthday", dob: "2024-03-15", departure: "2026-03-14", want: models.PassengerTypeInfant},
+		{name: "child on second birthday", dob: "2024-03-15", departure: "2026-03-15", want: models.PassengerTypeChild},
+		{name: "child a day before twelfth birthday", dob: "2014-07-01", departure: "2026-06-30", want: models.PassengerTypeChild},
+		{name: "adult on twelfth birthday", dob: "2014-07-01", departure: "2026-07-01", want: models.PassengerTypeAdult},
+		{name: "leap year departure before birthday", dob: "2022-03-01", departure: "2024-02-29", want: models.PassengerTypeInfant},
+		{name: "leap year departure on birthday", dob: "2022-03-01", departure: "2024-03-01", want: models.PassengerTypeChild},
+		{name: "leap day birth before birthday", dob: "2012-02-29", departure: "2024-02-28", want: models.PassengerTypeChild},
+		{name: "leap day birth on birthday", dob: "2012-02-29", departure: "2024-02-29", want: models.PassengerTypeAdult},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := calculatePassengerType(date(tt.dob), date(tt.departure)); got != tt.want {
+				t.Fatalf("expected %s, got %s", tt.want, got)
+			}
+		})
+	}
+}
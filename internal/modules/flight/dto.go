@@ -14,6 +14,12 @@ type CreateFlightClassRequest struct {
 	ClassCode  string          `json:"class_code" validate:"required"`
 	Price      decimal.Decimal `json:"price" validate:"required"`
 	TotalSeats int             `json:"total_seats" validate:"required,min=1"`
+
+	// Kosongkan untuk memakai default: anak 100%, bayi 10% dari harga dewasa
+	ChildFareType   string          `json:"child_fare_type" validate:"omitempty,oneof=percentage fixed"`
+	ChildFareValue  decimal.Decimal `json:"child_fare_value"`
+	InfantFareType  string          `json:"infant_fare_type" validate:"omitempty,oneof=percentage fixed"`
+	InfantFareValue decimal.Decimal `json:"infant_fare_value"`
 }
 
 type CreateFlightLegRequest struct {
@@ -41,9 +47,33 @@ type CreateFlightRequest struct {
 type SearchFlightRequest struct {
 	OriginAirportID      uint   `query:"origin"`
 	DestinationAirportID uint   `query:"destination"`
-	DepartureDate        string `query:"departure_date"`
-	SeatClass            string `query:"seat_class"`
-	PassengerCount       int    `query:"passengers"`
+	DepartureDate        string `query:"departure_date" validate:"omitempty,datetime=2006-01-02"`
+	SeatClass            string `query:"seat_class" validate:"omitempty,oneof=economy business first_class"`
+	PassengerCount       int    `query:"passengers" validate:"omitempty,min=1"`
+
+	AirlineIDs        string   `query:"airline_ids"`
+	MinPrice          *float64 `query:"min_price"`
+	MaxPrice          *float64 `query:"max_price"`
+	DepartureTimeFrom string   `query:"departure_time_from"`
+	DepartureTimeTo   string   `query:"departure_time_to"`
+	ArrivalTimeFrom   string   `query:"arrival_time_from"`
+	ArrivalTimeTo     string   `query:"arrival_time_to"`
+	MaxTransits       *int     `query:"max_transits"`
+	MaxDuration       int      `query:"max_duration"`
+
+	SortBy    string `query:"sort_by" validate:"omitempty,oneof=price departure_time duration transit"`
+	SortOrder string `query:"sort_order" validate:"omitempty,oneof=asc desc"`
+	Page      int    `query:"page"`
+	Limit     int    `query:"limit"`
+}
+
+type SearchFlightMeta struct {
+	Page       int             `json:"page"`
+	Limit      int             `json:"limit"`
+	Total      int64           `json:"total"`
+	TotalPages int             `json:"total_pages"`
+	MinPrice   decimal.Decimal `json:"min_price"`
+	MaxPrice   decimal.Decimal `json:"max_price"`
 }
 
 type FlightClassResponse struct {
@@ -169,4 +199,120 @@ func ToFlightResponse(f models.Flight) FlightResponse {
 	}
 
 	return res
-}
\ No newline at end of file
+}
+
+type ItinerarySegmentRequest struct {
+	OriginAirportID      uint   `json:"origin" validate:"required"`
+	DestinationAirportID uint   `json:"destination" validate:"required"`
+	DepartureDate        string `json:"departure_date" validate:"required,datetime=2006-01-02"`
+}
+
+type ItinerarySearchRequest struct {
+	TripType             string                    `json:"trip_type" validate:"required,oneof=round_trip multi_city"`
+	OriginAirportID      uint                      `json:"origin" validate:"required_if=TripType round_trip"`
+	DestinationAirportID uint                      `json:"destination" validate:"required_if=TripType round_trip"`
+	DepartureDate        string                    `json:"departure_date" validate:"required_if=TripType round_trip,omitempty,datetime=2006-01-02"`
+	ReturnDate           string                    `json:"return_date" validate:"required_if=TripType round_trip,omitempty,datetime=2006-01-02"`
+	Segments             []ItinerarySegmentRequest `json:"segments" validate:"required_if=TripType multi_city,omitempty,min=2,max=5,dive"`
+	SeatClass            string                    `json:"seat_class" validate:"omitempty,oneof=economy business first_class"`
+	PassengerCount       int                       `json:"passengers"`
+	Limit                int                       `json:"limit"`
+}
+
+type ItineraryOption struct {
+	Flights                []FlightResponse `json:"flights"`
+	PricePerPassenger      decimal.Decimal  `json:"price_per_passenger"`
+	TotalPrice             decimal.Decimal  `json:"total_price"`
+	TotalDurationMinutes   int              `json:"total_duration_minutes"`
+	TotalDurationFormatted string           `json:"total_duration_formatted"`
+}
+
+type ItinerarySearchResponse struct {
+	TripType       string            `json:"trip_type"`
+	PassengerCount int               `json:"passengers"`
+	Options        []ItineraryOption `json:"options"`
+}
+
+type FareCalendarRequest struct {
+	OriginAirportID      uint   `query:"origin" validate:"required"`
+	DestinationAirportID uint   `query:"destination" validate:"required,nefield=OriginAirportID"`
+	SeatClass            string `query:"seat_class" validate:"omitempty,oneof=economy business first_class"`
+	PassengerCount       int    `query:"passengers"`
+
+	// Pilih salah satu: Date + FlexDays (±N hari) atau Month (YYYY-MM)
+	Date     string `query:"date" validate:"omitempty,datetime=2006-01-02"`
+	FlexDays int    `query:"flex_days"`
+	Month    string `query:"month" validate:"omitempty,datetime=2006-01"`
+}
+
+type DailyLowestFare struct {
+	Date        time.Time
+	LowestPrice decimal.Decimal
+	FlightCount int
+}
+
+type FareCalendarDay struct {
+	Date        string           `json:"date"`
+	Available   bool             `json:"available"`
+	LowestPrice *decimal.Decimal `json:"lowest_price"`
+	FlightCount int              `json:"flight_count"`
+}
+
+type FareCalendarResponse struct {
+	OriginAirportID      uint              `json:"origin"`
+	DestinationAirportID uint              `json:"destination"`
+	SeatClass            string            `json:"seat_class"`
+	PassengerCount       int               `json:"passengers"`
+	StartDate            string            `json:"start_date"`
+	EndDate              string            `json:"end_date"`
+	CheapestDate         string            `json:"cheapest_date,omitempty"`
+	Days                 []FareCalendarDay `json:"days"`
+}
+
+type SeatMapCabinRequest struct {
+	SeatClass string `json:"seat_class" validate:"required,oneof=economy business first_class"`
+	StartRow  int    `json:"start_row" validate:"required,min=1"`
+	EndRow    int    `json:"end_row" validate:"required,gtefield=StartRow"`
+	// Susunan kolom dari kiri ke kanan, "-" menandakan lorong. Contoh: "ABC-DEF"
+	Columns string `json:"columns" validate:"required"`
+}
+
+type SaveSeatMapRequest struct {
+	Cabins       []SeatMapCabinRequest `json:"cabins" validate:"required,min=1,dive"`
+	ExitRows     []int                 `json:"exit_rows"`
+	BlockedSeats []string              `json:"blocked_seats"`
+}
+
+type SeatResponse struct {
+	SeatNumber string `json:"seat_number"`
+	Row        int    `json:"row"`
+	Column     string `json:"column"`
+	SeatClass  string `json:"seat_class"`
+	IsExitRow  bool   `json:"is_exit_row"`
+	IsWindow   bool   `json:"is_window"`
+	IsAisle    bool   `json:"is_aisle"`
+	Status     string `json:"status"`
+}
+
+type LegSeatMapResponse struct {
+	FlightLegID    uint           `json:"flight_leg_id"`
+	LegOrder       int            `json:"leg_order"`
+	FlightNumber   string         `json:"flight_number"`
+	Origin         string         `json:"origin"`
+	Destination    string         `json:"destination"`
+	AvailableSeats int            `json:"available_seats"`
+	Seats          []SeatResponse `json:"seats"`
+}
+
+func ToSeatResponse(seat models.FlightSeat) SeatResponse {
+	return SeatResponse{
+		SeatNumber: seat.SeatNumber,
+		Row:        seat.RowNumber,
+		Column:     seat.ColumnLetter,
+		SeatClass:  seat.SeatClass,
+		IsExitRow:  seat.IsExitRow,
+		IsWindow:   seat.IsWindow,
+		IsAisle:    seat.IsAisle,
+		Status:     seat.Status(),
+	}
+}
@@ -1,12 +1,17 @@
 package flight
 
 import (
-	"ezytix-be/internal/models"
 	"strconv"
 
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/validation"
+
 	"github.com/gofiber/fiber/v2"
 )
 
+var ErrInvalidFlightID = apperror.BadRequest("INVALID_FLIGHT_ID", "invalid flight ID")
+var ErrInvalidFlightLegID = apperror.BadRequest("INVALID_FLIGHT_LEG_ID", "invalid flight leg ID")
+
 type FlightHandler struct {
 	service FlightService
 }
@@ -18,17 +23,13 @@ func NewFlightHandler(service FlightService) *FlightHandler {
 func (h *FlightHandler) CreateFlight(c *fiber.Ctx) error {
 	var req CreateFlightRequest
 
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	flightModel, err := h.service.CreateFlight(req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	flightResponse := ToFlightResponse(*flightModel)
@@ -42,23 +43,13 @@ func (h *FlightHandler) CreateFlight(c *fiber.Ctx) error {
 func (h *FlightHandler) GetAllFlights(c *fiber.Ctx) error {
 	var req SearchFlightRequest
 	
-	if err := c.QueryParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{"error": "invalid query params"})
-	}
-
-	var flights []models.Flight
-	var err error
-
-	if req.OriginAirportID != 0 && req.DestinationAirportID != 0 && req.DepartureDate != "" {
-		flights, err = h.service.SearchFlights(req)
-	} else {
-		flights, err = h.service.GetAllFlights()
+	if err := validation.ParseQuery(c, &req); err != nil {
+		return err
 	}
 
+	flights, meta, err := h.service.SearchFlightsPaginated(req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	var flightResponses []FlightResponse
@@ -72,6 +63,42 @@ func (h *FlightHandler) GetAllFlights(c *fiber.Ctx) error {
 
 	return c.JSON(fiber.Map{
 		"data": flightResponses,
+		"meta": meta,
+	})
+}
+
+func (h *FlightHandler) SearchItineraries(c *fiber.Ctx) error {
+	var req ItinerarySearchRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.service.SearchItineraries(req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": resp,
+	})
+}
+
+func (h *FlightHandler) GetFareCalendar(c *fiber.Ctx) error {
+	var req FareCalendarRequest
+	if err := validation.ParseQuery(c, &req); err != nil {
+		return err
+	}
+
+	resp, err := h.service.GetFareCalendar(req)
+	if err != nil {
+		return err
+	}
+
+	// Harga per hari cukup stabil, biarkan browser/CDN menyimpan sebentar
+	c.Set(fiber.HeaderCacheControl, "public, max-age=300")
+
+	return c.JSON(fiber.Map{
+		"data": resp,
 	})
 }
 
@@ -79,16 +106,12 @@ func (h *FlightHandler) GetFlightByID(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid flight ID",
-		})
+		return ErrInvalidFlightID
 	}
 
 	flightModel, err := h.service.GetFlightByID(uint(id))
 	if err != nil {
-		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
-			"error": "flight not found",
-		})
+		return err
 	}
 
 	flightResponse := ToFlightResponse(*flightModel)
@@ -102,23 +125,17 @@ func (h *FlightHandler) UpdateFlight(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid flight ID",
-		})
+		return ErrInvalidFlightID
 	}
 
 	var req CreateFlightRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
 	flightModel, err := h.service.UpdateFlight(uint(id), req)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	flightResponse := ToFlightResponse(*flightModel)
@@ -133,18 +150,56 @@ func (h *FlightHandler) DeleteFlight(c *fiber.Ctx) error {
 	idStr := c.Params("id")
 	id, err := strconv.Atoi(idStr)
 	if err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": "invalid flight ID",
-		})
+		return ErrInvalidFlightID
 	}
 
 	if err := h.service.DeleteFlight(uint(id)); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"error": err.Error(),
-		})
+		return err
 	}
 
 	return c.JSON(fiber.Map{
 		"message": "flight deleted successfully",
 	})
-}
\ No newline at end of file
+}
+func (h *FlightHandler) GetSeatMap(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidFlightID
+	}
+
+	seatMap, err := h.service.GetSeatMap(uint(id))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": seatMap,
+	})
+}
+
+func (h *FlightHandler) SaveSeatMap(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidFlightID
+	}
+
+	legID, err := strconv.Atoi(c.Params("leg_id"))
+	if err != nil {
+		return ErrInvalidFlightLegID
+	}
+
+	var req SaveSeatMapRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	seatMap, err := h.service.SaveSeatMap(uint(id), uint(legID), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "seat map saved successfully",
+		"data":    seatMap,
+	})
+}
@@ -1,10 +1,17 @@
 package flight
 
 import (
+	"errors"
 	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
+	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
+	"github.com/shopspring/decimal"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type FlightRepository interface {
@@ -15,6 +22,11 @@ type FlightRepository interface {
 	DeleteFlight(id uint) error
 
 	SearchFlights(req SearchFlightRequest) ([]models.Flight, error)
+	SearchFlightsPaginated(req SearchFlightRequest) ([]models.Flight, *SearchFlightMeta, error)
+	FindLowestFaresByDay(req SearchFlightRequest, start time.Time, end time.Time) ([]DailyLowestFare, error)
+
+	FindSeatsByLegs(legs []models.FlightLeg) ([]models.FlightSeat, error)
+	ReplaceSeatMap(leg models.FlightLeg, seats []models.FlightSeat) error
 }
 
 type flightRepository struct {
@@ -26,7 +38,12 @@ func NewFlightRepository(db *gorm.DB) FlightRepository {
 }
 
 func (r *flightRepository) CreateFlight(flight *models.Flight) error {
-	return r.db.Create(flight).Error
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(flight).Error; err != nil {
+			return err
+		}
+		return ensureLegInventories(tx, flight)
+	})
 }
 
 func (r *flightRepository) GetAllFlights() ([]models.Flight, error) {
@@ -58,6 +75,9 @@ func (r *flightRepository) GetFlightByID(id uint) (*models.Flight, error) {
 		
 		First(&flight, id).Error
 
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrFlightNotFound
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -70,15 +90,17 @@ func (r *flightRepository) UpdateFlight(flight *models.Flight) error {
 			return err
 		}
 
-		if err := tx.Where("flight_id = ?", flight.ID).Delete(&models.FlightLeg{}).Error; err != nil {
+		var oldClasses []models.FlightClass
+		if err := tx.Where("flight_id = ?", flight.ID).Find(&oldClasses).Error; err != nil {
 			return err
 		}
 
-		if len(flight.FlightLegs) > 0 {
-			for i := range flight.FlightLegs {
-				flight.FlightLegs[i].FlightID = flight.ID
-			}
-			if err := tx.Create(&flight.FlightLegs).Error; err != nil {
+		movedLegs, err := upsertFlightLegs(tx, flight)
+		if err != nil {
+			return err
+		}
+		for _, moved := range movedLegs {
+			if err := moveLegInventory(tx, flight.ID, moved.from, moved.to); err != nil {
 				return err
 			}
 		}
@@ -95,10 +117,298 @@ func (r *flightRepository) UpdateFlight(flight *models.Flight) error {
 			}
 		}
 
-		return nil
+		if err := applyClassCapacity(tx, flight.ID, oldClasses, flight.FlightClasses); err != nil {
+			return err
+		}
+
+		return ensureLegInventories(tx, flight)
 	})
 }
 
+// movedLeg adalah leg yang nomor penerbangan, bandara asal atau jam
+// berangkatnya berubah, sehingga pindah ke leg fisik lain.
+type movedLeg struct {
+	from models.FlightLeg
+	to   models.FlightLeg
+}
+
+// upsertFlightLegs memperbarui leg yang sudah ada berdasarkan leg_order agar
+// ID-nya tetap, lalu menambah atau menghapus leg sisanya. Susunan leg tidak
+// boleh berubah selama flight masih punya booking aktif.
+func upsertFlightLegs(tx *gorm.DB, flight *models.Flight) ([]movedLeg, error) {
+	var existing []models.FlightLeg
+	if err := tx.Where("flight_id = ?", flight.ID).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	existingByOrder := make(map[int]models.FlightLeg, len(existing))
+	for _, leg := range existing {
+		existingByOrder[leg.LegOrder] = leg
+	}
+
+	var newLegs []*models.FlightLeg
+	var moved []movedLeg
+	for i := range flight.FlightLegs {
+		leg := &flight.FlightLegs[i]
+		leg.FlightID = flight.ID
+
+		old, ok := existingByOrder[leg.LegOrder]
+		if !ok {
+			newLegs = append(newLegs, leg)
+			continue
+		}
+		delete(existingByOrder, leg.LegOrder)
+
+		leg.ID = old.ID
+		leg.CreatedAt = old.CreatedAt
+		if err := tx.Omit("Airline", "OriginAirport", "DestinationAirport").Save(leg).Error; err != nil {
+			return nil, err
+		}
+		if !samePhysicalLeg(old, *leg) {
+			moved = append(moved, movedLeg{from: old, to: *leg})
+		}
+	}
+
+	if len(newLegs) == 0 && len(existingByOrder) == 0 {
+		return moved, nil
+	}
+
+	active, err := countActiveBookings(tx, flight.ID)
+	if err != nil {
+		return nil, err
+	}
+	if active > 0 {
+		return nil, ErrFlightHasBookings
+	}
+
+	for _, leg := range newLegs {
+		if err := tx.Omit("Airline", "OriginAirport", "DestinationAirport").Create(leg).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	var removedIDs []uint
+	for _, leg := range existingByOrder {
+		removedIDs = append(removedIDs, leg.ID)
+	}
+	if len(removedIDs) > 0 {
+		if err := tx.Where("id IN ?", removedIDs).Delete(&models.FlightLeg{}).Error; err != nil {
+			return nil, err
+		}
+	}
+
+	return moved, nil
+}
+
+func samePhysicalLeg(a, b models.FlightLeg) bool {
+	return a.FlightNumber == b.FlightNumber &&
+		a.OriginAirportID == b.OriginAirportID &&
+		a.DepartureTime.Equal(b.DepartureTime)
+}
+
+
+// countActiveBookings menghitung booking yang masih memegang kursi di flight,
+// termasuk perubahan jadwal yang sedang menunggu pembayaran ke flight ini.
+func countActiveBookings(tx *gorm.DB, flightID uint) (int64, error) {
+	var bookings int64
+	if err := tx.Model(&models.Booking{}).
+		Where("flight_id = ? AND status IN ?", flightID, []string{models.BookingStatusPending, models.BookingStatusPaid}).
+		Count(&bookings).Error; err != nil {
+		return 0, err
+	}
+
+	var changes int64
+	if err := tx.Model(&models.BookingChange{}).
+		Where("new_flight_id = ? AND status = ?", flightID, models.BookingChangeStatusPendingPayment).
+		Count(&changes).Error; err != nil {
+		return 0, err
+	}
+
+	return bookings + changes, nil
+}
+
+const physicalLegKey = "flight_number = ? AND origin_airport_id = ? AND departure_time = ?"
+
+func physicalLegArgs(leg models.FlightLeg, extra ...interface{}) []interface{} {
+	return append([]interface{}{leg.FlightNumber, leg.OriginAirportID, leg.DepartureTime}, extra...)
+}
+
+// moveLegInventory memindahkan stok leg saat leg pindah ke leg fisik lain.
+// Jika leg lama hanya dipakai flight ini dan leg tujuan belum punya stok,
+// barisnya cukup diganti kuncinya. Selain itu kursi yang dipegang flight ini
+// dikembalikan ke leg lama (atau barisnya dihapus jika tidak dipakai lagi) dan
+// diambil dari stok leg tujuan. Leg tujuan yang belum punya stok dibuat oleh
+// ensureLegInventories.
+func moveLegInventory(tx *gorm.DB, flightID uint, from, to models.FlightLeg) error {
+	var sharedLegs int64
+	if err := tx.Model(&models.FlightLeg{}).
+		Where("flight_id <> ? AND "+physicalLegKey, append([]interface{}{flightID}, physicalLegArgs(from)...)...).
+		Count(&sharedLegs).Error; err != nil {
+		return err
+	}
+	shared := sharedLegs > 0
+
+	var inventories []models.LegInventory
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+		Where(physicalLegKey, physicalLegArgs(from)...).
+		Find(&inventories).Error; err != nil {
+		return err
+	}
+
+	for _, inventory := range inventories {
+		var target models.LegInventory
+		err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where(physicalLegKey+" AND seat_class = ?", physicalLegArgs(to, inventory.SeatClass)...).
+			First(&target).Error
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		targetExists := err == nil
+
+		if !shared && !targetExists {
+			if err := tx.Model(&inventory).Updates(map[string]interface{}{
+				"flight_number":     to.FlightNumber,
+				"origin_airport_id": to.OriginAirportID,
+				"departure_time":    to.DepartureTime,
+			}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		held, err := countHeldSeats(tx, flightID, inventory.SeatClass)
+		if err != nil {
+			return err
+		}
+
+		if shared {
+			if err := tx.Model(&inventory).Update("available_seats", gorm.Expr("available_seats + ?", held)).Error; err != nil {
+				return err
+			}
+		} else if err := tx.Delete(&inventory).Error; err != nil {
+			return err
+		}
+
+		if targetExists {
+			if target.AvailableSeats < held {
+				return ErrInsufficientLegStock.Withf("leg %s only has %d %s seats left, %d are needed", to.FlightNumber, target.AvailableSeats, inventory.SeatClass, held)
+			}
+			if err := tx.Model(&target).Update("available_seats", gorm.Expr("available_seats - ?", held)).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	return moveLegSeats(tx, flightID, shared, from, to)
+}
+
+// moveLegSeats memperlakukan denah kursi dengan cara yang sama. Jika leg lama
+// masih dipakai flight lain, hanya kursi penumpang flight ini yang dilepas.
+// Jika leg tujuan sudah punya denah, denah lama dihapus dan penumpang memilih
+// kursi lagi di denah tujuan.
+func moveLegSeats(tx *gorm.DB, flightID uint, shared bool, from, to models.FlightLeg) error {
+	if shared {
+		return tx.Model(&models.FlightSeat{}).
+			Where(physicalLegKey, physicalLegArgs(from)...).
+			Where("booking_detail_id IN (?)", tx.Model(&models.BookingDetail{}).
+				Select("booking_details.id").
+				Joins("JOIN bookings ON bookings.id = booking_details.booking_id").
+				Where("bookings.flight_id = ?", flightID)).
+			Update("booking_detail_id", nil).Error
+	}
+
+	var targetSeats int64
+	if err := tx.Model(&models.FlightSeat{}).Where(physicalLegKey, physicalLegArgs(to)...).Count(&targetSeats).Error; err != nil {
+		return err
+	}
+	if targetSeats > 0 {
+		return tx.Where(physicalLegKey, physicalLegArgs(from)...).Delete(&models.FlightSeat{}).Error
+	}
+
+	return tx.Model(&models.FlightSeat{}).
+		Where(physicalLegKey, physicalLegArgs(from)...).
+		Updates(map[string]interface{}{
+			"flight_number":     to.FlightNumber,
+			"origin_airport_id": to.OriginAirportID,
+			"departure_time":    to.DepartureTime,
+		}).Error
+}
+
+// countHeldSeats menghitung kursi kelas tertentu yang sedang dipegang flight:
+// penumpang non-bayi di booking aktif ditambah perubahan jadwal ke flight ini
+// yang belum dibayar.
+func countHeldSeats(tx *gorm.DB, flightID uint, seatClass string) (int, error) {
+	var booked int64
+	if err := tx.Model(&models.BookingDetail{}).
+		Joins("JOIN bookings ON bookings.id = booking_details.booking_id").
+		Where("bookings.flight_id = ? AND bookings.status IN ?", flightID, []string{models.BookingStatusPending, models.BookingStatusPaid}).
+		Where("booking_details.seat_class = ? AND booking_details.passenger_type <> ?", seatClass, models.PassengerTypeInfant).
+		Count(&booked).Error; err != nil {
+		return 0, err
+	}
+
+	var changing int64
+	if err := tx.Model(&models.BookingDetail{}).
+		Joins("JOIN booking_changes ON booking_changes.booking_id = booking_details.booking_id").
+		Where("booking_changes.new_flight_id = ? AND booking_changes.status = ?", flightID, models.BookingChangeStatusPendingPayment).
+		Where("booking_changes.new_seat_class = ? AND booking_details.passenger_type <> ?", seatClass, models.PassengerTypeInfant).
+		Count(&changing).Error; err != nil {
+		return 0, err
+	}
+
+	return int(booked + changing), nil
+}
+
+// applyClassCapacity meneruskan perubahan jumlah kursi kelas ke stok setiap leg
+// flight. Kelas baru ditangani ensureLegInventories.
+func applyClassCapacity(tx *gorm.DB, flightID uint, oldClasses, newClasses []models.FlightClass) error {
+	oldSeats := make(map[string]int, len(oldClasses))
+	for _, class := range oldClasses {
+		oldSeats[class.SeatClass] = class.TotalSeats
+	}
+
+	for _, class := range newClasses {
+		old, ok := oldSeats[class.SeatClass]
+		if !ok || old == class.TotalSeats {
+			continue
+		}
+
+		err := tx.Exec(`
+			UPDATE leg_inventories li
+			SET available_seats = GREATEST(li.available_seats + ?, 0), updated_at = ?
+			FROM flight_legs fl
+			WHERE fl.flight_id = ? AND li.seat_class = ? AND `+models.LegInventoryJoin,
+			class.TotalSeats-old, time.Now(), flightID, class.SeatClass).Error
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// ensureLegInventories membuat stok per leg untuk setiap kelas. Leg yang sudah
+// punya stok (dipakai flight lain) dibiarkan apa adanya.
+func ensureLegInventories(tx *gorm.DB, flight *models.Flight) error {
+	var inventories []models.LegInventory
+	for _, leg := range flight.FlightLegs {
+		for _, class := range flight.FlightClasses {
+			inventories = append(inventories, models.LegInventory{
+				FlightNumber:    leg.FlightNumber,
+				OriginAirportID: leg.OriginAirportID,
+				DepartureTime:   leg.DepartureTime,
+				SeatClass:       class.SeatClass,
+				AvailableSeats:  class.TotalSeats,
+			})
+		}
+	}
+
+	if len(inventories) == 0 {
+		return nil
+	}
+
+	return tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&inventories).Error
+}
+
 func (r *flightRepository) DeleteFlight(id uint) error {
 	return r.db.Transaction(func(tx *gorm.DB) error {
 		if err := tx.Delete(&models.Flight{}, id).Error; err != nil {
@@ -111,15 +421,199 @@ func (r *flightRepository) DeleteFlight(id uint) error {
 func (r *flightRepository) SearchFlights(req SearchFlightRequest) ([]models.Flight, error) {
 	var flights []models.Flight
 
+	query := r.searchQuery(req)
+	query = applyPriceRange(query, req)
+	query = applySorting(query, req)
+	query = preloadSearchDetails(query, req)
+
+	if err := query.Select("flights.*").Find(&flights).Error; err != nil {
+		return nil, err
+	}
+
+	if err := r.applyLegAvailability(flights); err != nil {
+		return nil, err
+	}
+
+	return flights, nil
+}
+
+func (r *flightRepository) SearchFlightsPaginated(req SearchFlightRequest) ([]models.Flight, *SearchFlightMeta, error) {
+	var flights []models.Flight
+
+	var priceRange struct {
+		MinPrice decimal.Decimal
+		MaxPrice decimal.Decimal
+	}
+	err := r.searchQuery(req).
+		Select("COALESCE(MIN(fc.min_price), 0) AS min_price, COALESCE(MAX(fc.min_price), 0) AS max_price").
+		Scan(&priceRange).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var total int64
+	if err := applyPriceRange(r.searchQuery(req), req).Count(&total).Error; err != nil {
+		return nil, nil, err
+	}
+
+	query := applyPriceRange(r.searchQuery(req), req)
+	query = applySorting(query, req)
+	query = preloadSearchDetails(query, req)
+
+	err = query.
+		Select("flights.*").
+		Offset((req.Page - 1) * req.Limit).
+		Limit(req.Limit).
+		Find(&flights).Error
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if err := r.applyLegAvailability(flights); err != nil {
+		return nil, nil, err
+	}
+
+	meta := &SearchFlightMeta{
+		Page:       req.Page,
+		Limit:      req.Limit,
+		Total:      total,
+		TotalPages: int((total + int64(req.Limit) - 1) / int64(req.Limit)),
+		MinPrice:   priceRange.MinPrice,
+		MaxPrice:   priceRange.MaxPrice,
+	}
+
+	return flights, meta, nil
+}
+
+// FindLowestFaresByDay memakai filter yang sama dengan SearchFlights, hanya saja
+// tanggalnya berupa rentang [start, end) dan hasilnya diagregasi per hari.
+func (r *flightRepository) FindLowestFaresByDay(req SearchFlightRequest, start time.Time, end time.Time) ([]DailyLowestFare, error) {
+	var fares []DailyLowestFare
+
+	req.DepartureDate = ""
+	err := r.searchQuery(req).
+		Select("DATE(flights.departure_time) AS date, MIN(fc.min_price) AS lowest_price, COUNT(flights.id) AS flight_count").
+		Where("flights.departure_time >= ? AND flights.departure_time < ?", start, end).
+		Where("flights.departure_time > ?", time.Now()).
+		Group("DATE(flights.departure_time)").
+		Order("date ASC").
+		Scan(&fares).Error
+
+	return fares, err
+}
+
+// FindSeatsByLegs mengambil denah kursi leg fisik dari legs. Leg yang dipakai
+// bersama flight lain menghasilkan denah yang sama.
+func (r *flightRepository) FindSeatsByLegs(legs []models.FlightLeg) ([]models.FlightSeat, error) {
+	var seats []models.FlightSeat
+	if len(legs) == 0 {
+		return seats, nil
+	}
+
+	keys := make([][]interface{}, 0, len(legs))
+	for _, leg := range legs {
+		keys = append(keys, []interface{}{leg.FlightNumber, leg.OriginAirportID, leg.DepartureTime})
+	}
+
+	err := r.db.
+		Where("(flight_number, origin_airport_id, departure_time) IN ?", keys).
+		Order("departure_time ASC, row_number ASC, column_letter ASC").
+		Find(&seats).Error
+
+	return seats, err
+}
+
+func (r *flightRepository) ReplaceSeatMap(leg models.FlightLeg, seats []models.FlightSeat) error {
+	return r.db.Transaction(func(tx *gorm.DB) error {
+		onLeg := "flight_number = ? AND origin_airport_id = ? AND departure_time = ?"
+
+		var assigned int64
+		if err := tx.Model(&models.FlightSeat{}).
+			Where(onLeg+" AND booking_detail_id IS NOT NULL", leg.FlightNumber, leg.OriginAirportID, leg.DepartureTime).
+			Count(&assigned).Error; err != nil {
+			return err
+		}
+		if assigned > 0 {
+			return ErrSeatMapInUse
+		}
+
+		if err := tx.Where(onLeg, leg.FlightNumber, leg.OriginAirportID, leg.DepartureTime).
+			Delete(&models.FlightSeat{}).Error; err != nil {
+			return err
+		}
+
+		for i := range seats {
+			seats[i].PlaceOnLeg(leg)
+		}
+		return tx.CreateInBatches(&seats, 200).Error
+	})
+}
+
+// applyLegAvailability menurunkan TotalSeats tiap kelas menjadi stok terkecil
+// di antara leg-leg penerbangan tersebut, karena leg transit bisa dipakai
+// bersama oleh itinerary lain.
+func (r *flightRepository) applyLegAvailability(flights []models.Flight) error {
+	if len(flights) == 0 {
+		return nil
+	}
+
+	var flightIDs []uint
+	for _, f := range flights {
+		flightIDs = append(flightIDs, f.ID)
+	}
+
+	var rows []struct {
+		FlightID       uint
+		SeatClass      string
+		AvailableSeats int
+	}
+	err := r.db.Table("flight_legs AS fl").
+		Select("fl.flight_id, li.seat_class, MIN(li.available_seats) AS available_seats").
+		Joins("JOIN leg_inventories li ON "+models.LegInventoryJoin).
+		Where("fl.flight_id IN ?", flightIDs).
+		Group("fl.flight_id, li.seat_class").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	legSeats := make(map[string]int)
+	for _, row := range rows {
+		legSeats[fmt.Sprintf("%d:%s", row.FlightID, row.SeatClass)] = row.AvailableSeats
+	}
+
+	for i := range flights {
+		for j := range flights[i].FlightClasses {
+			class := &flights[i].FlightClasses[j]
+			if seats, ok := legSeats[fmt.Sprintf("%d:%s", class.FlightID, class.SeatClass)]; ok && seats < class.TotalSeats {
+				class.TotalSeats = seats
+			}
+		}
+	}
+
+	return nil
+}
+
+// searchQuery membangun query dasar pencarian. Harga termurah per penerbangan
+// dihitung di subquery "fc" supaya bisa dipakai untuk filter dan sorting.
+func (r *flightRepository) searchQuery(req SearchFlightRequest) *gorm.DB {
+	classQuery := r.db.Model(&models.FlightClass{}).
+		Select("flight_id, MIN(price) AS min_price").
+		Group("flight_id")
+
+	if req.SeatClass != "" {
+		classQuery = classQuery.Where("seat_class = ?", req.SeatClass)
+	}
+	if req.PassengerCount > 0 {
+		classQuery = classQuery.
+			Where("total_seats >= ?", req.PassengerCount).
+			Where("NOT EXISTS (SELECT 1 FROM flight_legs fl JOIN leg_inventories li ON "+models.LegInventoryJoin+
+				" WHERE fl.flight_id = flight_classes.flight_id AND li.seat_class = flight_classes.seat_class AND li.available_seats < ?)",
+				req.PassengerCount)
+	}
+
 	query := r.db.Model(&models.Flight{}).
-		Preload("Airline").             
-		Preload("OriginAirport").
-		Preload("DestinationAirport").
-		Preload("FlightLegs").
-		Preload("FlightLegs.Airline"). 
-		Preload("FlightLegs.OriginAirport").
-		Preload("FlightLegs.DestinationAirport").
-		Joins("JOIN flight_classes ON flight_classes.flight_id = flights.id")
+		Joins("JOIN (?) AS fc ON fc.flight_id = flights.id", classQuery)
 
 	if req.OriginAirportID != 0 {
 		query = query.Where("flights.origin_airport_id = ?", req.OriginAirportID)
@@ -137,19 +631,110 @@ func (r *flightRepository) SearchFlights(req SearchFlightRequest) ([]models.Flig
 			query = query.Where("flights.departure_time >= ? AND flights.departure_time < ?", startOfDay, endOfDay)
 		}
 	}
-	if req.SeatClass != "" {
-		query = query.Where("flight_classes.seat_class = ?", req.SeatClass) 
+
+	if airlineIDs, err := parseAirlineIDs(req.AirlineIDs); err == nil && len(airlineIDs) > 0 {
+		query = query.Where("flights.airline_id IN ?", airlineIDs)
 	}
-	if req.PassengerCount > 0 {
-		query = query.Where("flight_classes.total_seats >= ?", req.PassengerCount)
+
+	query = applyClockWindow(query, "flights.departure_time", req.DepartureTimeFrom, req.DepartureTimeTo)
+	query = applyClockWindow(query, "flights.arrival_time", req.ArrivalTimeFrom, req.ArrivalTimeTo)
+
+	if req.MaxTransits != nil {
+		query = query.Where("flights.transit_count <= ?", *req.MaxTransits)
+	}
+	if req.MaxDuration > 0 {
+		query = query.Where("flights.total_duration <= ?", req.MaxDuration)
 	}
+
+	return query
+}
+
+func applyPriceRange(query *gorm.DB, req SearchFlightRequest) *gorm.DB {
+	if req.MinPrice != nil {
+		query = query.Where("fc.min_price >= ?", *req.MinPrice)
+	}
+	if req.MaxPrice != nil {
+		query = query.Where("fc.min_price <= ?", *req.MaxPrice)
+	}
+	return query
+}
+
+var ErrSeatMapInUse = apperror.Conflict("SEAT_MAP_IN_USE", "seat map already has assigned seats and cannot be replaced")
+
+var searchSortColumns = map[string]string{
+	"price":          "fc.min_price",
+	"departure_time": "flights.departure_time",
+	"duration":       "flights.total_duration",
+	"transit":        "flights.transit_count",
+	"created_at":     "flights.created_at",
+}
+
+func applySorting(query *gorm.DB, req SearchFlightRequest) *gorm.DB {
+	column, ok := searchSortColumns[req.SortBy]
+	if !ok {
+		column = searchSortColumns["departure_time"]
+	}
+
+	direction := "ASC"
+	if strings.EqualFold(req.SortOrder, "desc") {
+		direction = "DESC"
+	}
+
+	return query.Order(fmt.Sprintf("%s %s", column, direction)).Order("flights.id ASC")
+}
+
+func applyClockWindow(query *gorm.DB, column string, from string, to string) *gorm.DB {
+	minuteOfDay := fmt.Sprintf("(EXTRACT(HOUR FROM %s) * 60 + EXTRACT(MINUTE FROM %s))", column, column)
+
+	if fromMinutes, err := parseClock(from); err == nil {
+		query = query.Where(minuteOfDay+" >= ?", fromMinutes)
+	}
+	if toMinutes, err := parseClock(to); err == nil {
+		query = query.Where(minuteOfDay+" <= ?", toMinutes)
+	}
+	return query
+}
+
+func preloadSearchDetails(query *gorm.DB, req SearchFlightRequest) *gorm.DB {
+	query = query.
+		Preload("Airline").             
+		Preload("OriginAirport").
+		Preload("DestinationAirport").
+		Preload("FlightLegs").
+		Preload("FlightLegs.Airline"). 
+		Preload("FlightLegs.OriginAirport").
+		Preload("FlightLegs.DestinationAirport")
+
 	if req.SeatClass != "" {
-		query = query.Preload("FlightClasses", "seat_class = ?", req.SeatClass)
-	} else {
-		query = query.Preload("FlightClasses")
+		return query.Preload("FlightClasses", "seat_class = ?", req.SeatClass)
 	}
+	return query.Preload("FlightClasses")
+}
 
-	err := query.Distinct("flights.*").Find(&flights).Error
+func parseAirlineIDs(raw string) ([]uint, error) {
+	var ids []uint
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		id, err := strconv.ParseUint(part, 10, 64)
+		if err != nil {
+			return nil, ErrInvalidSearch.Withf("invalid airline id %q", part)
+		}
+		ids = append(ids, uint(id))
+	}
+	return ids, nil
+}
 
-	return flights, err
-}
\ No newline at end of file
+// parseClock mengubah "HH:MM" menjadi menit sejak tengah malam.
+func parseClock(raw string) (int, error) {
+	if raw == "" {
+		return 0, ErrInvalidSearch.Withf("empty clock value")
+	}
+	t, err := time.Parse("15:04", raw)
+	if err != nil {
+		return 0, ErrInvalidSearch.Withf("invalid time %q, expected HH:MM", raw)
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
@@ -1,7 +1,10 @@
 package flight
 
 import (
+	"time"
+
 	"ezytix-be/internal/middleware"
+	"ezytix-be/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
@@ -14,9 +17,27 @@ func FlightRegisterRoutes(app *fiber.App, db *gorm.DB) {
 
 	api := app.Group("/api/v1")
 
+	// Pencarian paling berat ke database, jadi diberi batas lebih ketat
+	searchLimit := middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "flight-search",
+		Max:    30,
+		Window: time.Minute,
+	})
+
+	// Pencarian tetap publik. Partner yang mengirim API key dibatasi sesuai
+	// limit key-nya, bukan limit per IP di bawah
 	flights := api.Group("/flights")
+	flights.Use(middleware.OptionalAPIKey, middleware.RequireScope(models.ScopeFlightsRead))
+	flights.Use(middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "flights",
+		Max:    120,
+		Window: time.Minute,
+	}))
 	flights.Get("/", handler.GetAllFlights)
+	flights.Post("/itineraries/search", searchLimit, handler.SearchItineraries)
+	flights.Get("/fare-calendar", searchLimit, handler.GetFareCalendar)
 	flights.Get("/:id", handler.GetFlightByID)
+	flights.Get("/:id/seat-map", handler.GetSeatMap)
 
 	admin := api.Group("/admin/flights")
 	admin.Use(middleware.JWTMiddleware)
@@ -24,4 +45,5 @@ func FlightRegisterRoutes(app *fiber.App, db *gorm.DB) {
 	admin.Post("/", handler.CreateFlight)
 	admin.Put("/:id", handler.UpdateFlight)
 	admin.Delete("/:id", handler.DeleteFlight)
+	admin.Put("/:id/legs/:leg_id/seat-map", handler.SaveSeatMap)
 }
\ No newline at end of file
@@ -1,9 +1,29 @@
 package flight
 
 import (
-	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"time"
+
 	"ezytix-be/internal/models"
+	"ezytix-be/internal/utils"
+	"ezytix-be/pkg/apperror"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrFlightNotFound        = apperror.NotFound("FLIGHT_NOT_FOUND", "flight not found")
+	ErrFlightLegNotFound     = apperror.NotFound("FLIGHT_LEG_NOT_FOUND", "flight leg not found")
+	ErrSameOriginDestination = apperror.Validation("SAME_ORIGIN_DESTINATION", "origin and destination airport cannot be the same")
+	ErrInvalidSchedule       = apperror.Validation("INVALID_SCHEDULE", "arrival time must be after departure time")
+	ErrInvalidSearch         = apperror.BadRequest("INVALID_SEARCH", "invalid search parameters")
+	ErrInvalidSeatMap        = apperror.Validation("INVALID_SEAT_MAP", "invalid seat map")
+	ErrInvalidItinerary      = apperror.Validation("INVALID_ITINERARY", "invalid itinerary")
+	ErrInvalidFare           = apperror.Validation("INVALID_FARE", "invalid fare component")
+	ErrInsufficientLegStock  = apperror.InsufficientStock("INSUFFICIENT_LEG_STOCK", "not enough seats left on the leg")
+	ErrFlightHasBookings     = apperror.Conflict("FLIGHT_HAS_BOOKINGS", "flight legs cannot be added or removed while the flight has active bookings")
 )
 
 type FlightService interface {
@@ -13,8 +33,27 @@ type FlightService interface {
 	UpdateFlight(id uint, req CreateFlightRequest) (*models.Flight, error)
 	DeleteFlight(id uint) error
 	SearchFlights(req SearchFlightRequest) ([]models.Flight, error)
+	SearchFlightsPaginated(req SearchFlightRequest) ([]models.Flight, *SearchFlightMeta, error)
+	SearchItineraries(req ItinerarySearchRequest) (*ItinerarySearchResponse, error)
+	GetFareCalendar(req FareCalendarRequest) (*FareCalendarResponse, error)
+	GetSeatMap(flightID uint) ([]LegSeatMapResponse, error)
+	SaveSeatMap(flightID uint, legID uint, req SaveSeatMapRequest) (*LegSeatMapResponse, error)
 }
 
+const (
+	// Jeda minimum antara kedatangan satu penerbangan dan keberangkatan berikutnya
+	MinConnectionTime = 90 * time.Minute
+
+	maxItinerarySegments   = 5
+	maxItineraryCandidates = 500
+
+	defaultSearchPageLimit = 20
+	maxSearchPageLimit     = 100
+
+	defaultFareCalendarFlexDays = 3
+	maxFareCalendarFlexDays     = 15
+)
+
 type flightService struct {
 	repo FlightRepository
 }
@@ -25,10 +64,10 @@ func NewFlightService(repo FlightRepository) FlightService {
 
 func (s *flightService) CreateFlight(req CreateFlightRequest) (*models.Flight, error) {
 	if req.OriginAirportID == req.DestinationAirportID {
-		return nil, errors.New("origin and destination airport cannot be the same")
+		return nil, ErrSameOriginDestination
 	}
 	if req.ArrivalTime.Before(req.DepartureTime) {
-		return nil, errors.New("arrival time must be after departure time")
+		return nil, ErrInvalidSchedule
 	}
 
 	totalDurationMinutes := int(req.ArrivalTime.Sub(req.DepartureTime).Minutes())
@@ -58,7 +97,7 @@ func (s *flightService) CreateFlight(req CreateFlightRequest) (*models.Flight, e
 	var legs []models.FlightLeg
 	for _, legReq := range req.FlightLegs {
 		if legReq.ArrivalTime.Before(legReq.DepartureTime) {
-			return nil, errors.New("leg arrival time must be after departure time")
+			return nil, ErrInvalidSchedule.Withf("leg arrival time must be after departure time")
 		}
 
 		legDuration := int(legReq.ArrivalTime.Sub(legReq.DepartureTime).Minutes())
@@ -79,12 +118,11 @@ func (s *flightService) CreateFlight(req CreateFlightRequest) (*models.Flight, e
 
 	var classes []models.FlightClass
 	for _, classReq := range req.FlightClasses {
-		classes = append(classes, models.FlightClass{
-			SeatClass:  classReq.SeatClass,
-			ClassCode:  classReq.ClassCode,
-			Price:      classReq.Price,
-			TotalSeats: classReq.TotalSeats,
-		})
+		class, err := newFlightClass(classReq)
+		if err != nil {
+			return nil, err
+		}
+		classes = append(classes, class)
 	}
 	flight.FlightClasses = classes
 
@@ -105,14 +143,14 @@ func (s *flightService) GetFlightByID(id uint) (*models.Flight, error) {
 func (s *flightService) UpdateFlight(id uint, req CreateFlightRequest) (*models.Flight, error) {
 	existingFlight, err := s.repo.GetFlightByID(id)
 	if err != nil {
-		return nil, errors.New("flight not found")
+		return nil, ErrFlightNotFound
 	}
 
 	if req.OriginAirportID == req.DestinationAirportID {
-		return nil, errors.New("origin and destination airport cannot be the same")
+		return nil, ErrSameOriginDestination
 	}
 	if req.ArrivalTime.Before(req.DepartureTime) {
-		return nil, errors.New("arrival time must be after departure time")
+		return nil, ErrInvalidSchedule
 	}
 
 	totalDurationMinutes := int(req.ArrivalTime.Sub(req.DepartureTime).Minutes())
@@ -137,10 +175,16 @@ func (s *flightService) UpdateFlight(id uint, req CreateFlightRequest) (*models.
 	existingFlight.TransitInfo = transitInfo
 
 	var newLegs []models.FlightLeg
+	legOrders := make(map[int]bool, len(req.FlightLegs))
 	for _, legReq := range req.FlightLegs {
 		if legReq.ArrivalTime.Before(legReq.DepartureTime) {
-			return nil, errors.New("leg arrival time must be after departure time")
+			return nil, ErrInvalidSchedule.Withf("leg arrival time must be after departure time")
+		}
+		// Leg lama dicocokkan lewat leg_order, jadi nilainya harus unik
+		if legOrders[legReq.LegOrder] {
+			return nil, ErrInvalidSchedule.Withf("leg_order %d is used more than once", legReq.LegOrder)
 		}
+		legOrders[legReq.LegOrder] = true
 		legDuration := int(legReq.ArrivalTime.Sub(legReq.DepartureTime).Minutes())
 
 		newLegs = append(newLegs, models.FlightLeg{
@@ -159,12 +203,11 @@ func (s *flightService) UpdateFlight(id uint, req CreateFlightRequest) (*models.
 
 	var newClasses []models.FlightClass
 	for _, classReq := range req.FlightClasses {
-		newClasses = append(newClasses, models.FlightClass{
-			SeatClass:  classReq.SeatClass,
-			ClassCode:  classReq.ClassCode,
-			Price:      classReq.Price,
-			TotalSeats: classReq.TotalSeats,
-		})
+		class, err := newFlightClass(classReq)
+		if err != nil {
+			return nil, err
+		}
+		newClasses = append(newClasses, class)
 	}
 	existingFlight.FlightClasses = newClasses
 
@@ -177,7 +220,7 @@ func (s *flightService) UpdateFlight(id uint, req CreateFlightRequest) (*models.
 func (s *flightService) DeleteFlight(id uint) error {
 	_, err := s.repo.GetFlightByID(id)
 	if err != nil {
-		return errors.New("flight not found")
+		return ErrFlightNotFound
 	}
 
 	return s.repo.DeleteFlight(id)
@@ -185,7 +228,7 @@ func (s *flightService) DeleteFlight(id uint) error {
 
 func (s *flightService) SearchFlights(req SearchFlightRequest) ([]models.Flight, error) {
 	if req.OriginAirportID == 0 || req.DestinationAirportID == 0 || req.DepartureDate == "" {
-		return nil, errors.New("origin, destination, and date are required")
+		return nil, ErrInvalidSearch.Withf("origin, destination, and date are required")
 	}
 
 	if req.PassengerCount <= 0 {
@@ -193,4 +236,557 @@ func (s *flightService) SearchFlights(req SearchFlightRequest) ([]models.Flight,
 	}
 
 	return s.repo.SearchFlights(req)
-}
\ No newline at end of file
+}
+
+func (s *flightService) SearchFlightsPaginated(req SearchFlightRequest) ([]models.Flight, *SearchFlightMeta, error) {
+	switch req.SortBy {
+	case "", "price", "departure_time", "duration", "transit":
+	default:
+		return nil, nil, ErrInvalidSearch.Withf("sort_by must be one of price, departure_time, duration, transit")
+	}
+
+	switch req.SortOrder {
+	case "", "asc", "desc":
+	default:
+		return nil, nil, ErrInvalidSearch.Withf("sort_order must be asc or desc")
+	}
+
+	for _, clock := range []string{req.DepartureTimeFrom, req.DepartureTimeTo, req.ArrivalTimeFrom, req.ArrivalTimeTo} {
+		if clock == "" {
+			continue
+		}
+		if _, err := parseClock(clock); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if _, err := parseAirlineIDs(req.AirlineIDs); err != nil {
+		return nil, nil, err
+	}
+
+	if req.MinPrice != nil && req.MaxPrice != nil && *req.MinPrice > *req.MaxPrice {
+		return nil, nil, ErrInvalidSearch.Withf("min_price cannot be greater than max_price")
+	}
+
+	if req.Page <= 0 {
+		req.Page = 1
+	}
+	if req.Limit <= 0 {
+		req.Limit = defaultSearchPageLimit
+	}
+	if req.Limit > maxSearchPageLimit {
+		req.Limit = maxSearchPageLimit
+	}
+
+	isRouteSearch := req.OriginAirportID != 0 && req.DestinationAirportID != 0 && req.DepartureDate != ""
+	if isRouteSearch && req.PassengerCount <= 0 {
+		req.PassengerCount = 1
+	}
+
+	// Tanpa kriteria rute, listing mengikuti urutan lama (flight terbaru dulu)
+	if !isRouteSearch && req.SortBy == "" {
+		req.SortBy = "created_at"
+		req.SortOrder = "desc"
+	}
+
+	return s.repo.SearchFlightsPaginated(req)
+}
+
+func (s *flightService) GetFareCalendar(req FareCalendarRequest) (*FareCalendarResponse, error) {
+	if req.OriginAirportID == 0 || req.DestinationAirportID == 0 {
+		return nil, ErrInvalidSearch.Withf("origin and destination are required")
+	}
+	if req.OriginAirportID == req.DestinationAirportID {
+		return nil, ErrSameOriginDestination
+	}
+	if req.PassengerCount <= 0 {
+		req.PassengerCount = 1
+	}
+
+	var start, end time.Time
+	switch {
+	case req.Month != "":
+		month, err := time.Parse("2006-01", req.Month)
+		if err != nil {
+			return nil, ErrInvalidSearch.Withf("invalid month format, expected YYYY-MM")
+		}
+		start = month
+		end = month.AddDate(0, 1, 0)
+	case req.Date != "":
+		date, err := time.Parse("2006-01-02", req.Date)
+		if err != nil {
+			return nil, ErrInvalidSearch.Withf("invalid date format, expected YYYY-MM-DD")
+		}
+		flexDays := req.FlexDays
+		if flexDays <= 0 {
+			flexDays = defaultFareCalendarFlexDays
+		}
+		if flexDays > maxFareCalendarFlexDays {
+			flexDays = maxFareCalendarFlexDays
+		}
+		start = date.AddDate(0, 0, -flexDays)
+		end = date.AddDate(0, 0, flexDays+1)
+	default:
+		return nil, ErrInvalidSearch.Withf("either date or month is required")
+	}
+
+	// Hari yang sudah lewat tidak perlu ditampilkan
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if start.Before(today) {
+		start = today
+	}
+	if !end.After(start) {
+		return nil, ErrInvalidSearch.Withf("requested dates are in the past")
+	}
+
+	fares, err := s.repo.FindLowestFaresByDay(SearchFlightRequest{
+		OriginAirportID:      req.OriginAirportID,
+		DestinationAirportID: req.DestinationAirportID,
+		SeatClass:            req.SeatClass,
+		PassengerCount:       req.PassengerCount,
+	}, start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	faresByDate := make(map[string]DailyLowestFare, len(fares))
+	for _, fare := range fares {
+		faresByDate[fare.Date.Format("2006-01-02")] = fare
+	}
+
+	resp := &FareCalendarResponse{
+		OriginAirportID:      req.OriginAirportID,
+		DestinationAirportID: req.DestinationAirportID,
+		SeatClass:            req.SeatClass,
+		PassengerCount:       req.PassengerCount,
+		StartDate:            start.Format("2006-01-02"),
+		EndDate:              end.AddDate(0, 0, -1).Format("2006-01-02"),
+		Days:                 []FareCalendarDay{},
+	}
+
+	var cheapest *decimal.Decimal
+	for day := start; day.Before(end); day = day.AddDate(0, 0, 1) {
+		key := day.Format("2006-01-02")
+		entry := FareCalendarDay{Date: key}
+
+		if fare, ok := faresByDate[key]; ok {
+			price := fare.LowestPrice
+			entry.Available = true
+			entry.LowestPrice = &price
+			entry.FlightCount = fare.FlightCount
+
+			if cheapest == nil || price.LessThan(*cheapest) {
+				cheapest = &price
+				resp.CheapestDate = key
+			}
+		}
+
+		resp.Days = append(resp.Days, entry)
+	}
+
+	return resp, nil
+}
+
+func (s *flightService) GetSeatMap(flightID uint) ([]LegSeatMapResponse, error) {
+	flightData, err := s.repo.GetFlightByID(flightID)
+	if err != nil {
+		return nil, ErrFlightNotFound
+	}
+
+	legs := flightData.FlightLegs
+	sort.Slice(legs, func(i, j int) bool { return legs[i].LegOrder < legs[j].LegOrder })
+
+	seats, err := s.repo.FindSeatsByLegs(legs)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := []LegSeatMapResponse{}
+	for _, leg := range legs {
+		var legSeats []models.FlightSeat
+		for _, seat := range seats {
+			if seat.IsOnLeg(leg) {
+				legSeats = append(legSeats, seat)
+			}
+		}
+		responses = append(responses, toLegSeatMapResponse(leg, legSeats))
+	}
+
+	return responses, nil
+}
+
+func (s *flightService) SaveSeatMap(flightID uint, legID uint, req SaveSeatMapRequest) (*LegSeatMapResponse, error) {
+	flightData, err := s.repo.GetFlightByID(flightID)
+	if err != nil {
+		return nil, ErrFlightNotFound
+	}
+
+	var leg *models.FlightLeg
+	for i := range flightData.FlightLegs {
+		if flightData.FlightLegs[i].ID == legID {
+			leg = &flightData.FlightLegs[i]
+			break
+		}
+	}
+	if leg == nil {
+		return nil, ErrFlightLegNotFound
+	}
+
+	seats, err := buildSeatMap(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.ReplaceSeatMap(*leg, seats); err != nil {
+		return nil, err
+	}
+
+	resp := toLegSeatMapResponse(*leg, seats)
+	return &resp, nil
+}
+
+// buildSeatMap menerjemahkan definisi kabin menjadi daftar kursi fisik.
+func buildSeatMap(req SaveSeatMapRequest) ([]models.FlightSeat, error) {
+	if len(req.Cabins) == 0 {
+		return nil, ErrInvalidSeatMap.Withf("seat map must contain at least one cabin")
+	}
+
+	exitRows := make(map[int]bool)
+	for _, row := range req.ExitRows {
+		exitRows[row] = true
+	}
+
+	blocked := make(map[string]bool)
+	for _, seatNumber := range req.BlockedSeats {
+		blocked[strings.ToUpper(strings.TrimSpace(seatNumber))] = true
+	}
+
+	var seats []models.FlightSeat
+	usedRows := make(map[int]bool)
+
+	for _, cabin := range req.Cabins {
+		if cabin.StartRow < 1 || cabin.EndRow < cabin.StartRow {
+			return nil, ErrInvalidSeatMap.Withf("invalid row range %d-%d", cabin.StartRow, cabin.EndRow)
+		}
+
+		layout := strings.ToUpper(strings.ReplaceAll(cabin.Columns, " ", "-"))
+		var letters []byte
+		for i := 0; i < len(layout); i++ {
+			ch := layout[i]
+			if ch == '-' {
+				continue
+			}
+			if ch < 'A' || ch > 'Z' {
+				return nil, ErrInvalidSeatMap.Withf("invalid seat column %q", string(ch))
+			}
+			letters = append(letters, ch)
+		}
+		if len(letters) == 0 {
+			return nil, ErrInvalidSeatMap.Withf("cabin columns cannot be empty")
+		}
+
+		for row := cabin.StartRow; row <= cabin.EndRow; row++ {
+			if usedRows[row] {
+				return nil, ErrInvalidSeatMap.Withf("row %d is defined in more than one cabin", row)
+			}
+			usedRows[row] = true
+
+			for i := 0; i < len(layout); i++ {
+				ch := layout[i]
+				if ch == '-' {
+					continue
+				}
+
+				seatNumber := fmt.Sprintf("%d%c", row, ch)
+				seats = append(seats, models.FlightSeat{
+					SeatNumber:   seatNumber,
+					RowNumber:    row,
+					ColumnLetter: string(ch),
+					SeatClass:    cabin.SeatClass,
+					IsExitRow:    exitRows[row],
+					IsWindow:     ch == letters[0] || ch == letters[len(letters)-1],
+					IsAisle:      (i > 0 && layout[i-1] == '-') || (i < len(layout)-1 && layout[i+1] == '-'),
+					IsBlocked:    blocked[seatNumber],
+				})
+				delete(blocked, seatNumber)
+			}
+		}
+	}
+
+	for seatNumber := range blocked {
+		return nil, ErrInvalidSeatMap.Withf("blocked seat %s is not part of the seat map", seatNumber)
+	}
+
+	return seats, nil
+}
+
+func toLegSeatMapResponse(leg models.FlightLeg, seats []models.FlightSeat) LegSeatMapResponse {
+	resp := LegSeatMapResponse{
+		FlightLegID:  leg.ID,
+		LegOrder:     leg.LegOrder,
+		FlightNumber: leg.FlightNumber,
+		Seats:        []SeatResponse{},
+	}
+	if leg.OriginAirport != nil {
+		resp.Origin = leg.OriginAirport.Code
+	}
+	if leg.DestinationAirport != nil {
+		resp.Destination = leg.DestinationAirport.Code
+	}
+
+	for _, seat := range seats {
+		if seat.Status() == models.SeatStatusAvailable {
+			resp.AvailableSeats++
+		}
+		resp.Seats = append(resp.Seats, ToSeatResponse(seat))
+	}
+
+	return resp
+}
+
+func (s *flightService) SearchItineraries(req ItinerarySearchRequest) (*ItinerarySearchResponse, error) {
+	segments := req.Segments
+	switch req.TripType {
+	case models.TripTypeRoundTrip:
+		if req.OriginAirportID == 0 || req.DestinationAirportID == 0 || req.DepartureDate == "" || req.ReturnDate == "" {
+			return nil, ErrInvalidSearch.Withf("origin, destination, departure date and return date are required")
+		}
+		segments = []ItinerarySegmentRequest{
+			{OriginAirportID: req.OriginAirportID, DestinationAirportID: req.DestinationAirportID, DepartureDate: req.DepartureDate},
+			{OriginAirportID: req.DestinationAirportID, DestinationAirportID: req.OriginAirportID, DepartureDate: req.ReturnDate},
+		}
+	case models.TripTypeMultiCity:
+		if len(segments) < 2 || len(segments) > maxItinerarySegments {
+			return nil, ErrInvalidSearch.Withf("multi city search requires 2 to %d segments", maxItinerarySegments)
+		}
+	default:
+		return nil, ErrInvalidSearch.Withf("trip type must be round_trip or multi_city")
+	}
+
+	if req.PassengerCount <= 0 {
+		req.PassengerCount = 1
+	}
+	if req.Limit <= 0 || req.Limit > 100 {
+		req.Limit = 20
+	}
+
+	candidates := make([][]models.Flight, len(segments))
+	for i, seg := range segments {
+		flights, err := s.SearchFlights(SearchFlightRequest{
+			OriginAirportID:      seg.OriginAirportID,
+			DestinationAirportID: seg.DestinationAirportID,
+			DepartureDate:        seg.DepartureDate,
+			SeatClass:            req.SeatClass,
+			PassengerCount:       req.PassengerCount,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("segment %d: %w", i+1, err)
+		}
+
+		sort.SliceStable(flights, func(a, b int) bool {
+			priceA, _ := cheapestClassPrice(flights[a], req.PassengerCount)
+			priceB, _ := cheapestClassPrice(flights[b], req.PassengerCount)
+			return priceA.LessThan(priceB)
+		})
+		candidates[i] = flights
+	}
+
+	var combos [][]models.Flight
+	var walk func(depth int, picked []models.Flight)
+	walk = func(depth int, picked []models.Flight) {
+		if len(combos) >= maxItineraryCandidates {
+			return
+		}
+		if depth == len(candidates) {
+			combos = append(combos, append([]models.Flight(nil), picked...))
+			return
+		}
+		for _, f := range candidates[depth] {
+			if _, ok := cheapestClassPrice(f, req.PassengerCount); !ok {
+				continue
+			}
+			if depth > 0 && !hasMinimumConnection(picked[depth-1], f) {
+				continue
+			}
+			walk(depth+1, append(picked, f))
+		}
+	}
+	walk(0, nil)
+
+	passengerCount := decimal.NewFromInt(int64(req.PassengerCount))
+	options := []ItineraryOption{}
+	for _, combo := range combos {
+		pricePerPassenger := decimal.Zero
+		var flightResponses []FlightResponse
+		for _, f := range combo {
+			price, _ := cheapestClassPrice(f, req.PassengerCount)
+			pricePerPassenger = pricePerPassenger.Add(price)
+			flightResponses = append(flightResponses, ToFlightResponse(f))
+		}
+
+		totalMinutes := int(combo[len(combo)-1].ArrivalTime.Sub(combo[0].DepartureTime).Minutes())
+		options = append(options, ItineraryOption{
+			Flights:                flightResponses,
+			PricePerPassenger:      pricePerPassenger,
+			TotalPrice:             pricePerPassenger.Mul(passengerCount),
+			TotalDurationMinutes:   totalMinutes,
+			TotalDurationFormatted: utils.FormatDuration(totalMinutes),
+		})
+	}
+
+	sort.SliceStable(options, func(a, b int) bool {
+		return options[a].TotalPrice.LessThan(options[b].TotalPrice)
+	})
+	if len(options) > req.Limit {
+		options = options[:req.Limit]
+	}
+
+	return &ItinerarySearchResponse{
+		TripType:       req.TripType,
+		PassengerCount: req.PassengerCount,
+		Options:        options,
+	}, nil
+}
+
+// ValidateItinerary memastikan urutan penerbangan membentuk perjalanan yang
+// diminta: arah pulang-pergi benar, kronologis, dan jeda antar penerbangan cukup.
+func ValidateItinerary(tripType string, flights []models.Flight) error {
+	switch tripType {
+	case models.TripTypeOneWay:
+		if len(flights) != 1 {
+			return ErrInvalidItinerary.Withf("one way trip must contain exactly one flight")
+		}
+	case models.TripTypeRoundTrip:
+		if len(flights) != 2 {
+			return ErrInvalidItinerary.Withf("round trip must contain exactly two flights")
+		}
+		outbound, inbound := flights[0], flights[1]
+		if inbound.OriginAirportID != outbound.DestinationAirportID || inbound.DestinationAirportID != outbound.OriginAirportID {
+			return ErrInvalidItinerary.Withf("return flight must fly back from the outbound destination to the outbound origin")
+		}
+	case models.TripTypeMultiCity:
+		if len(flights) < 2 || len(flights) > maxItinerarySegments {
+			return ErrInvalidItinerary.Withf("multi city trip must contain 2 to %d flights", maxItinerarySegments)
+		}
+	default:
+		return ErrInvalidItinerary.Withf("unknown trip type %s", tripType)
+	}
+
+	seen := make(map[uint]bool)
+	for i, f := range flights {
+		if seen[f.ID] {
+			return ErrInvalidItinerary.Withf("flight %s is booked more than once", f.FlightCode)
+		}
+		seen[f.ID] = true
+
+		if i > 0 && !hasMinimumConnection(flights[i-1], f) {
+			return ErrInvalidItinerary.Withf("flight %s must depart at least %d minutes after flight %s arrives",
+				f.FlightCode, int(MinConnectionTime.Minutes()), flights[i-1].FlightCode)
+		}
+	}
+
+	return nil
+}
+
+// InferTripType menebak jenis perjalanan dari urutan penerbangan ketika
+// klien tidak mengirimkan trip_type.
+func InferTripType(flights []models.Flight) string {
+	switch {
+	case len(flights) <= 1:
+		return models.TripTypeOneWay
+	case len(flights) == 2 &&
+		flights[1].OriginAirportID == flights[0].DestinationAirportID &&
+		flights[1].DestinationAirportID == flights[0].OriginAirportID:
+		return models.TripTypeRoundTrip
+	default:
+		return models.TripTypeMultiCity
+	}
+}
+
+func hasMinimumConnection(previous models.Flight, next models.Flight) bool {
+	return !next.DepartureTime.Before(previous.ArrivalTime.Add(MinConnectionTime))
+}
+
+// cheapestClassPrice hanya mempertimbangkan kelas yang masih cukup kursinya.
+// TotalSeats di sini sudah diturunkan ke stok leg terkecil oleh
+// applyLegAvailability, jadi kelas yang habis di salah satu leg ikut terlewati.
+func cheapestClassPrice(f models.Flight, seatsNeeded int) (decimal.Decimal, bool) {
+	var cheapest decimal.Decimal
+	found := false
+	for _, fc := range f.FlightClasses {
+		if fc.TotalSeats < seatsNeeded {
+			continue
+		}
+		if !found || fc.Price.LessThan(cheapest) {
+			cheapest = fc.Price
+			found = true
+		}
+	}
+	return cheapest, found
+}
+
+func newFlightClass(req CreateFlightClassRequest) (models.FlightClass, error) {
+	childType, childValue, err := normalizeFareComponent(req.ChildFareType, req.ChildFareValue, decimal.NewFromInt(100))
+	if err != nil {
+		return models.FlightClass{}, fmt.Errorf("child fare: %w", err)
+	}
+
+	infantType, infantValue, err := normalizeFareComponent(req.InfantFareType, req.InfantFareValue, decimal.NewFromInt(10))
+	if err != nil {
+		return models.FlightClass{}, fmt.Errorf("infant fare: %w", err)
+	}
+
+	return models.FlightClass{
+		SeatClass:       req.SeatClass,
+		ClassCode:       req.ClassCode,
+		Price:           req.Price,
+		TotalSeats:      req.TotalSeats,
+		ChildFareType:   childType,
+		ChildFareValue:  childValue,
+		InfantFareType:  infantType,
+		InfantFareValue: infantValue,
+	}, nil
+}
+
+func normalizeFareComponent(fareType string, value decimal.Decimal, defaultPercent decimal.Decimal) (string, decimal.Decimal, error) {
+	switch fareType {
+	case "":
+		return models.FareTypePercentage, defaultPercent, nil
+	case models.FareTypePercentage:
+		if value.IsNegative() || value.GreaterThan(decimal.NewFromInt(100)) {
+			return "", decimal.Zero, ErrInvalidFare.Withf("percentage must be between 0 and 100")
+		}
+	case models.FareTypeFixed:
+		if value.IsNegative() {
+			return "", decimal.Zero, ErrInvalidFare.Withf("fixed fare cannot be negative")
+		}
+	default:
+		return "", decimal.Zero, ErrInvalidFare.Withf("unknown fare type %s", fareType)
+	}
+	return fareType, value, nil
+}
+
+// PassengerFare menghitung harga satu penumpang sesuai tipenya
+// (Dewasa/Anak-anak/Bayi) berdasarkan komponen tarif di kelas tersebut.
+func PassengerFare(class models.FlightClass, passengerType string) decimal.Decimal {
+	var fareType string
+	var value decimal.Decimal
+
+	switch passengerType {
+	case models.PassengerTypeChild:
+		fareType, value = class.ChildFareType, class.ChildFareValue
+	case models.PassengerTypeInfant:
+		fareType, value = class.InfantFareType, class.InfantFareValue
+	default:
+		return class.Price
+	}
+
+	switch fareType {
+	case models.FareTypeFixed:
+		return value
+	case models.FareTypePercentage:
+		return class.Price.Mul(value).Div(decimal.NewFromInt(100)).Round(2)
+	}
+	return class.Price
+}
@@ -1,6 +1,10 @@
 package payment
 
-import "time"
+import (
+	"time"
+
+	"ezytix-be/internal/models"
+)
 
 type InitiatePaymentRequest struct {
 	OrderID     string `json:"order_id" validate:"required"`
@@ -37,4 +41,40 @@ type QrisData struct {
 
 type GopayData struct {
 	Deeplink string `json:"deeplink"`
+}
+
+type EmitWebhookRequest struct {
+	TransactionStatus string `json:"transaction_status" validate:"required,oneof=pending settlement capture deny cancel expire"`
+}
+
+type PaymentEventFilter struct {
+	OrderID          string `query:"order_id"`
+	TransactionID    string `query:"transaction_id"`
+	ProcessingStatus string `query:"processing_status" validate:"omitempty,oneof=processed ignored failed"`
+	Page             int    `query:"page"`
+	Limit            int    `query:"limit"`
+}
+
+type PaymentEventListResponse struct {
+	Events []models.PaymentEvent `json:"events"`
+	Page   int                   `json:"page"`
+	Limit  int                   `json:"limit"`
+	Total  int64                 `json:"total"`
+}
+
+type PaymentMismatch struct {
+	OrderID       string `json:"order_id"`
+	TransactionID string `json:"transaction_id"`
+	LocalStatus   string `json:"local_status"`
+	GatewayStatus string `json:"gateway_status"`
+	LocalAmount   string `json:"local_amount"`
+	GatewayAmount string `json:"gateway_amount"`
+	Reason        string `json:"reason"`
+}
+
+type ReconciliationReport struct {
+	Date         string            `json:"date"`
+	TotalChecked int               `json:"total_checked"`
+	Mismatches   []PaymentMismatch `json:"mismatches"`
+	GeneratedAt  time.Time         `json:"generated_at"`
 }
\ No newline at end of file
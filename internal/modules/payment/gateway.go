@@ -0,0 +1,69 @@
+package payment
+
+import (
+	"log"
+	"time"
+
+	"ezytix-be/internal/config"
+)
+
+const (
+	GatewayMidtrans = "midtrans"
+	GatewayFake     = "fake"
+)
+
+type ChargeRequest struct {
+	OrderID       string
+	PaymentType   string
+	Bank          string
+	GrossAmount   int64
+	OrderTime     time.Time
+	ExpiryMinutes int
+	UserID        uint
+}
+
+type ChargeResult struct {
+	TransactionID     string
+	TransactionStatus string
+	Bank              string
+	VaNumber          string
+	BillKey           string
+	BillerCode        string
+	QrUrl             string
+	Deeplink          string
+}
+
+type TransactionStatusResult struct {
+	OrderID           string
+	TransactionID     string
+	PaymentType       string
+	GrossAmount       string
+	StatusCode        string
+	TransactionStatus string
+	TransactionTime   string
+	FraudStatus       string
+}
+
+type RefundRequest struct {
+	RefundKey string
+	Amount    int64
+	Reason    string
+}
+
+type PaymentGateway interface {
+	Charge(req ChargeRequest) (*ChargeResult, error)
+	Cancel(transactionID string) error
+	Status(transactionID string) (*TransactionStatusResult, error)
+	Refund(transactionID string, req RefundRequest) error
+	VerifyWebhook(payload map[string]interface{}) error
+}
+
+func NewPaymentGateway() PaymentGateway {
+	switch config.AppConfig.PaymentGateway {
+	case GatewayFake:
+		log.Println("⚠️ [PAYMENT] Using fake payment gateway, no real transactions will be made")
+		return NewFakeGateway(config.AppConfig.FakeGatewayServerKey, config.AppConfig.FakeGatewayWebhookURL)
+	default:
+		return NewMidtransGateway(config.AppConfig.MidtransServerKey, config.AppConfig.MidtransIsProduction)
+	}
+}
@@ -0,0 +1,234 @@
+package payment
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+
+	"ezytix-be/internal/config"
+)
+
+type fakeTransaction struct {
+	OrderID           string
+	PaymentType       string
+	GrossAmount       int64
+	TransactionStatus string
+	TransactionTime   time.Time
+}
+
+// FakeGateway mensimulasikan payment gateway secara lokal untuk QA dan CI.
+// Semua transaksi disimpan di memori dan hilang ketika server restart.
+type FakeGateway struct {
+	mu           sync.Mutex
+	serverKey    string
+	webhookURL   string
+	httpClient   *http.Client
+	transactions map[string]*fakeTransaction
+}
+
+func NewFakeGateway(serverKey string, webhookURL string) *FakeGateway {
+	if webhookURL == "" {
+		webhookURL = fmt.Sprintf("http://localhost:%s/api/v1/payments/webhook", config.AppConfig.Port)
+	}
+
+	return &FakeGateway{
+		serverKey:    serverKey,
+		webhookURL:   webhookURL,
+		httpClient:   &http.Client{Timeout: 10 * time.Second},
+		transactions: make(map[string]*fakeTransaction),
+	}
+}
+
+func (g *FakeGateway) Charge(req ChargeRequest) (*ChargeResult, error) {
+	transactionID := randomHex(16)
+
+	result := &ChargeResult{
+		TransactionID:     transactionID,
+		TransactionStatus: "pending",
+	}
+
+	switch req.PaymentType {
+	case "bank_transfer":
+		result.Bank = req.Bank
+		result.VaNumber = "8808" + randomDigits(12)
+	case "echannel":
+		result.BillKey = randomDigits(12)
+		result.BillerCode = "70012"
+	case "qris":
+		result.QrUrl = fmt.Sprintf("https://fake-gateway.ezytix.local/qris/%s/qr-code", transactionID)
+	case "gopay":
+		result.QrUrl = fmt.Sprintf("https://fake-gateway.ezytix.local/gopay/%s/qr-code", transactionID)
+		result.Deeplink = fmt.Sprintf("https://fake-gateway.ezytix.local/gopay/%s/deeplink", transactionID)
+	default:
+		return nil, errors.New("unsupported payment type")
+	}
+
+	g.mu.Lock()
+	g.transactions[transactionID] = &fakeTransaction{
+		OrderID:           req.OrderID,
+		PaymentType:       req.PaymentType,
+		GrossAmount:       req.GrossAmount,
+		TransactionStatus: "pending",
+		TransactionTime:   time.Now(),
+	}
+	g.mu.Unlock()
+
+	return result, nil
+}
+
+func (g *FakeGateway) Cancel(transactionID string) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	trx, ok := g.transactions[transactionID]
+	if !ok {
+		return errors.New("fake gateway: transaction not found")
+	}
+	if trx.TransactionStatus != "pending" {
+		return fmt.Errorf("fake gateway: transaction with status %s cannot be cancelled", trx.TransactionStatus)
+	}
+
+	trx.TransactionStatus = "cancel"
+	return nil
+}
+
+func (g *FakeGateway) Status(transactionID string) (*TransactionStatusResult, error) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	trx, ok := g.transactions[transactionID]
+	if !ok {
+		return nil, errors.New("fake gateway: transaction not found")
+	}
+
+	return &TransactionStatusResult{
+		OrderID:           trx.OrderID,
+		TransactionID:     transactionID,
+		PaymentType:       trx.PaymentType,
+		GrossAmount:       formatGrossAmount(trx.GrossAmount),
+		StatusCode:        statusCodeFor(trx.TransactionStatus),
+		TransactionStatus: trx.TransactionStatus,
+		TransactionTime:   trx.TransactionTime.Format("2006-01-02 15:04:05"),
+		FraudStatus:       "accept",
+	}, nil
+}
+
+func (g *FakeGateway) Refund(transactionID string, req RefundRequest) error {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	trx, ok := g.transactions[transactionID]
+	if !ok {
+		return errors.New("fake gateway: transaction not found")
+	}
+	if trx.TransactionStatus != "settlement" && trx.TransactionStatus != "capture" {
+		return fmt.Errorf("fake gateway: transaction with status %s cannot be refunded", trx.TransactionStatus)
+	}
+	if req.Amount <= 0 || req.Amount > trx.GrossAmount {
+		return errors.New("fake gateway: invalid refund amount")
+	}
+
+	trx.TransactionStatus = "partial_refund"
+	if req.Amount == trx.GrossAmount {
+		trx.TransactionStatus = "refund"
+	}
+	return nil
+}
+
+func (g *FakeGateway) VerifyWebhook(payload map[string]interface{}) error {
+	return verifySHA512Signature(payload, g.serverKey)
+}
+
+// EmitWebhook mengubah status transaksi lalu mengirim notifikasi bertanda tangan
+// ke endpoint webhook, sama seperti yang dilakukan Midtrans.
+func (g *FakeGateway) EmitWebhook(transactionID string, status string) (map[string]interface{}, error) {
+	switch status {
+	case "pending", "settlement", "capture", "deny", "cancel", "expire":
+	default:
+		return nil, fmt.Errorf("fake gateway: unsupported status %s", status)
+	}
+
+	g.mu.Lock()
+	trx, ok := g.transactions[transactionID]
+	if !ok {
+		g.mu.Unlock()
+		return nil, errors.New("fake gateway: transaction not found")
+	}
+	trx.TransactionStatus = status
+	payload := g.buildNotification(transactionID, trx)
+	g.mu.Unlock()
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := g.httpClient.Post(g.webhookURL, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return payload, fmt.Errorf("fake gateway: failed to deliver webhook: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return payload, fmt.Errorf("fake gateway: webhook endpoint responded with status %d", resp.StatusCode)
+	}
+
+	return payload, nil
+}
+
+func (g *FakeGateway) buildNotification(transactionID string, trx *fakeTransaction) map[string]interface{} {
+	statusCode := statusCodeFor(trx.TransactionStatus)
+	grossAmount := formatGrossAmount(trx.GrossAmount)
+
+	return map[string]interface{}{
+		"transaction_time":   trx.TransactionTime.Format("2006-01-02 15:04:05"),
+		"transaction_status": trx.TransactionStatus,
+		"transaction_id":     transactionID,
+		"status_message":     "fake gateway notification",
+		"status_code":        statusCode,
+		"signature_key":      signWebhook(trx.OrderID, statusCode, grossAmount, g.serverKey),
+		"payment_type":       trx.PaymentType,
+		"order_id":           trx.OrderID,
+		"gross_amount":       grossAmount,
+		"fraud_status":       "accept",
+		"currency":           "IDR",
+	}
+}
+
+func statusCodeFor(status string) string {
+	switch status {
+	case "settlement", "capture", "refund", "partial_refund":
+		return "200"
+	case "pending":
+		return "201"
+	default:
+		return "202"
+	}
+}
+
+func formatGrossAmount(amount int64) string {
+	return fmt.Sprintf("%d.00", amount)
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+func randomDigits(n int) string {
+	const charset = "0123456789"
+	b := make([]byte, n)
+	for i := range b {
+		idx, _ := rand.Int(rand.Reader, big.NewInt(int64(len(charset))))
+		b[i] = charset[idx.Int64()]
+	}
+	return string(b)
+}
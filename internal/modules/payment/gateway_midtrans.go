@@ -0,0 +1,172 @@
+package payment
+
+import (
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"fmt"
+
+	"ezytix-be/internal/config"
+	"ezytix-be/pkg/apperror"
+
+	"github.com/midtrans/midtrans-go"
+	"github.com/midtrans/midtrans-go/coreapi"
+)
+
+type midtransGateway struct {
+	client    coreapi.Client
+	serverKey string
+}
+
+func NewMidtransGateway(serverKey string, isProduction bool) PaymentGateway {
+	var client coreapi.Client
+
+	env := midtrans.Sandbox
+	if isProduction {
+		env = midtrans.Production
+	}
+
+	client.New(serverKey, env)
+
+	return &midtransGateway{
+		client:    client,
+		serverKey: serverKey,
+	}
+}
+
+func (g *midtransGateway) Charge(req ChargeRequest) (*ChargeResult, error) {
+	chargeReq := &coreapi.ChargeReq{
+		PaymentType: coreapi.CoreapiPaymentType(req.PaymentType),
+		TransactionDetails: midtrans.TransactionDetails{
+			OrderID:  req.OrderID,
+			GrossAmt: req.GrossAmount,
+		},
+		CustomExpiry: &coreapi.CustomExpiry{
+			OrderTime:      req.OrderTime.Format("2006-01-02 15:04:05 -0700"),
+			ExpiryDuration: req.ExpiryMinutes,
+			Unit:           "minute",
+		},
+		Metadata: map[string]interface{}{
+			"user_id": fmt.Sprintf("%d", req.UserID),
+		},
+	}
+
+	switch req.PaymentType {
+	case "bank_transfer":
+		chargeReq.BankTransfer = &coreapi.BankTransferDetails{
+			Bank: midtrans.Bank(req.Bank),
+		}
+	case "echannel":
+		chargeReq.EChannel = &coreapi.EChannelDetail{
+			BillInfo1: "Payment For:",
+			BillInfo2: "Flight Ticket",
+		}
+	case "qris":
+		chargeReq.Qris = &coreapi.QrisDetails{
+			Acquirer: "gopay",
+		}
+	case "gopay":
+		chargeReq.Gopay = &coreapi.GopayDetails{
+			EnableCallback: true,
+			CallbackUrl:    config.AppConfig.FrontendURL + "/payment-finish",
+		}
+	default:
+		return nil, errors.New("unsupported payment type")
+	}
+
+	resp, midErr := g.client.ChargeTransaction(chargeReq)
+	if midErr != nil {
+		return nil, fmt.Errorf("midtrans error: %v", midErr)
+	}
+
+	result := &ChargeResult{
+		TransactionID:     resp.TransactionID,
+		TransactionStatus: resp.TransactionStatus,
+	}
+
+	switch req.PaymentType {
+	case "bank_transfer":
+		if len(resp.VaNumbers) > 0 {
+			result.VaNumber = resp.VaNumbers[0].VANumber
+			result.Bank = resp.VaNumbers[0].Bank
+		} else if resp.PermataVaNumber != "" {
+			result.VaNumber = resp.PermataVaNumber
+			result.Bank = "permata"
+		}
+	case "echannel":
+		result.BillKey = resp.BillKey
+		result.BillerCode = resp.BillerCode
+	case "qris", "gopay":
+		for _, action := range resp.Actions {
+			if action.Name == "generate-qr-code" {
+				result.QrUrl = action.URL
+			}
+			if action.Name == "deeplink-redirect" {
+				result.Deeplink = action.URL
+			}
+		}
+	}
+
+	return result, nil
+}
+
+func (g *midtransGateway) Cancel(transactionID string) error {
+	if _, midErr := g.client.CancelTransaction(transactionID); midErr != nil {
+		return fmt.Errorf("midtrans cancel error: %v", midErr)
+	}
+	return nil
+}
+
+func (g *midtransGateway) Status(transactionID string) (*TransactionStatusResult, error) {
+	resp, midErr := g.client.CheckTransaction(transactionID)
+	if midErr != nil {
+		return nil, fmt.Errorf("midtrans status error: %v", midErr)
+	}
+
+	return &TransactionStatusResult{
+		OrderID:           resp.OrderID,
+		TransactionID:     resp.TransactionID,
+		PaymentType:       resp.PaymentType,
+		GrossAmount:       resp.GrossAmount,
+		StatusCode:        resp.StatusCode,
+		TransactionStatus: resp.TransactionStatus,
+		TransactionTime:   resp.TransactionTime,
+		FraudStatus:       resp.FraudStatus,
+	}, nil
+}
+
+func (g *midtransGateway) Refund(transactionID string, req RefundRequest) error {
+	refundReq := &coreapi.RefundReq{
+		RefundKey: req.RefundKey,
+		Amount:    req.Amount,
+		Reason:    req.Reason,
+	}
+
+	if _, midErr := g.client.RefundTransaction(transactionID, refundReq); midErr != nil {
+		return fmt.Errorf("midtrans refund error: %v", midErr)
+	}
+	return nil
+}
+
+func (g *midtransGateway) VerifyWebhook(payload map[string]interface{}) error {
+	return verifySHA512Signature(payload, g.serverKey)
+}
+
+var ErrInvalidSignature = apperror.Unauthorized("INVALID_SIGNATURE", "invalid signature key")
+
+func verifySHA512Signature(payload map[string]interface{}, serverKey string) error {
+	orderID, _ := payload["order_id"].(string)
+	statusCode, _ := payload["status_code"].(string)
+	grossAmount, _ := payload["gross_amount"].(string)
+	signatureKey, _ := payload["signature_key"].(string)
+
+	if signatureKey != signWebhook(orderID, statusCode, grossAmount, serverKey) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func signWebhook(orderID, statusCode, grossAmount, serverKey string) string {
+	hash := sha512.Sum512([]byte(orderID + statusCode + grossAmount + serverKey))
+	return hex.EncodeToString(hash[:])
+}
@@ -1,11 +1,22 @@
 package payment
 
 import (
-	"strings"
+	"ezytix-be/internal/middleware"
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/jwt"
+	"ezytix-be/pkg/validation"
+	"strconv"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+var (
+	ErrMissingOrderID    = apperror.BadRequest("MISSING_ORDER_ID", "order ID is required")
+	ErrInvalidEventID    = apperror.BadRequest("INVALID_EVENT_ID", "invalid event ID")
+	ErrInvalidReportDate = apperror.BadRequest("INVALID_REPORT_DATE", "invalid date, expected format YYYY-MM-DD")
+)
+
 type PaymentHandler struct {
 	service PaymentService
 }
@@ -15,20 +26,19 @@ func NewPaymentHandler(service PaymentService) *PaymentHandler {
 }
 
 func (h *PaymentHandler) InitiatePayment(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
 	var req InitiatePaymentRequest
-	if err := c.BodyParser(&req); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Invalid request body",
-		})
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
 	}
 
-	resp, err := h.service.InitiatePayment(req)
+	resp, err := h.service.InitiatePayment(userClaims, req)
 	if err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
@@ -41,17 +51,11 @@ func (h *PaymentHandler) InitiatePayment(c *fiber.Ctx) error {
 func (h *PaymentHandler) HandleWebhook(c *fiber.Ctx) error {
 	var payload map[string]interface{}
 	if err := c.BodyParser(&payload); err != nil {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Invalid request body",
-		})
+		return validation.ErrInvalidBody.Wrap(err)
 	}
 
 	if err := h.service.ProcessWebhook(payload); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": err.Error(),
-		})
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -61,19 +65,18 @@ func (h *PaymentHandler) HandleWebhook(c *fiber.Ctx) error {
 }
 
 func (h *PaymentHandler) CancelPayment(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
 	orderID := c.Params("orderID")
 	if orderID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
-			"status":  "error",
-			"message": "Order ID is required",
-		})
+		return ErrMissingOrderID
 	}
 
-	if err := h.service.CancelPayment(orderID); err != nil {
-		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
-			"status":  "error",
-			"message": err.Error(),
-		})
+	if err := h.service.CancelOrderPayment(userClaims, orderID); err != nil {
+		return err
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
@@ -83,30 +86,124 @@ func (h *PaymentHandler) CancelPayment(c *fiber.Ctx) error {
 }
 
 func (h *PaymentHandler) GetPaymentStatus(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
 	orderID := c.Params("orderID")
 	if orderID == "" {
-		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		return ErrMissingOrderID
+	}
+
+	resp, err := h.service.GetPaymentByOrderID(userClaims, orderID)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Payment data retrieved successfully",
+		"data":    resp,
+	})
+}
+
+func (h *PaymentHandler) ListPaymentEvents(c *fiber.Ctx) error {
+	var filter PaymentEventFilter
+	if err := validation.ParseQuery(c, &filter); err != nil {
+		return err
+	}
+
+	resp, err := h.service.ListEvents(filter)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Payment events retrieved successfully",
+		"data":    resp,
+	})
+}
+
+func (h *PaymentHandler) ReplayPaymentEvent(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidEventID
+	}
+
+	event, err := h.service.ReplayEvent(uint(id))
+	if err != nil && event == nil {
+		return err
+	}
+	if err != nil {
+		// Event tetap dikirim agar admin bisa melihat catatan hasil replay
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
 			"status":  "error",
-			"message": "Order ID is required",
+			"code":    "REPLAY_FAILED",
+			"message": err.Error(),
+			"data":    event,
 		})
 	}
 
-	resp, err := h.service.GetPaymentByOrderID(orderID)
-	if err != nil {
-		status := fiber.StatusInternalServerError
-		if strings.Contains(err.Error(), "record not found") {
-			status = fiber.StatusNotFound
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Payment event replayed successfully",
+		"data":    event,
+	})
+}
+
+func (h *PaymentHandler) GetMismatchReport(c *fiber.Ctx) error {
+	date := time.Now().AddDate(0, 0, -1)
+	if dateStr := c.Query("date"); dateStr != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", dateStr, time.Local)
+		if err != nil {
+			return ErrInvalidReportDate
 		}
+		date = parsed
+	}
+
+	report, err := h.service.GetMismatchReport(date)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusOK).JSON(fiber.Map{
+		"status":  "success",
+		"message": "Reconciliation report generated successfully",
+		"data":    report,
+	})
+}
+
+type FakeGatewayHandler struct {
+	gateway *FakeGateway
+}
+
+func NewFakeGatewayHandler(gateway *FakeGateway) *FakeGatewayHandler {
+	return &FakeGatewayHandler{gateway}
+}
+
+func (h *FakeGatewayHandler) EmitWebhook(c *fiber.Ctx) error {
+	transactionID := c.Params("transactionID")
+
+	var req EmitWebhookRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
 
-		return c.Status(status).JSON(fiber.Map{
+	payload, err := h.gateway.EmitWebhook(transactionID, req.TransactionStatus)
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
 			"status":  "error",
+			"code":    "WEBHOOK_EMIT_FAILED",
 			"message": err.Error(),
+			"data":    payload,
 		})
 	}
 
 	return c.Status(fiber.StatusOK).JSON(fiber.Map{
 		"status":  "success",
-		"message": "Payment data retrieved successfully",
-		"data":    resp,
+		"message": "Webhook emitted successfully",
+		"data":    payload,
 	})
 }
\ No newline at end of file
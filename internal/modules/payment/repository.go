@@ -5,14 +5,26 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type PaymentRepository interface {
 	CreatePayment(payment *models.Payment) error
 	FindPaymentByOrderID(orderID string) (*models.Payment, error)
+	FindPaymentsByOrderID(orderID string) ([]models.Payment, error)
+	FindSettledPaymentByOrderID(orderID string) (*models.Payment, error)
 	FindPaymentByTransactionID(transactionID string) (*models.Payment, error)
 	UpdatePaymentStatus(orderID string, status string, paidAt *time.Time) error
 	UpdatePaymentStatusByTransactionID(transactionID string, status string, paidAt *time.Time) error
+	TransitionPaymentStatus(transactionID string, fromStatus string, toStatus string, paidAt *time.Time) (bool, error)
+	FindPendingPaymentsExpiringBefore(deadline time.Time) ([]models.Payment, error)
+	FindPaymentsCreatedBetween(start time.Time, end time.Time) ([]models.Payment, error)
+
+	CreateEvent(event *models.PaymentEvent) (bool, error)
+	FindEventByID(id uint) (*models.PaymentEvent, error)
+	FindEventByDedupKey(dedupKey string) (*models.PaymentEvent, error)
+	FindEvents(filter PaymentEventFilter) ([]models.PaymentEvent, int64, error)
+	UpdateEventResult(id uint, processingStatus string, note string, isReplay bool) error
 }
 
 type paymentRepository struct {
@@ -36,6 +48,27 @@ func (r *paymentRepository) FindPaymentByOrderID(orderID string) (*models.Paymen
 	return &payment, nil
 }
 
+// FindPaymentsByOrderID mengembalikan semua tagihan order. Satu order bisa
+// punya beberapa tagihan jika customer berganti metode pembayaran.
+func (r *paymentRepository) FindPaymentsByOrderID(orderID string) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.Where("order_id = ?", orderID).Order("id asc").Find(&payments).Error
+	return payments, err
+}
+
+// FindSettledPaymentByOrderID mengembalikan tagihan order yang sudah dibayar,
+// bukan sekadar tagihan terbaru.
+func (r *paymentRepository) FindSettledPaymentByOrderID(orderID string) (*models.Payment, error) {
+	var payment models.Payment
+	err := r.db.Where("order_id = ? AND transaction_status = ?", orderID, models.PaymentStatusSettlement).
+		Order("id desc").
+		First(&payment).Error
+	if err != nil {
+		return nil, err
+	}
+	return &payment, nil
+}
+
 func (r *paymentRepository) FindPaymentByTransactionID(transactionID string) (*models.Payment, error) {
 	var payment models.Payment
 	err := r.db.Where("transaction_id = ?", transactionID).First(&payment).Error
@@ -72,4 +105,109 @@ func (r *paymentRepository) UpdatePaymentStatusByTransactionID(transactionID str
 	}
 
 	return r.db.Model(&models.Payment{}).Where("transaction_id = ?", transactionID).Updates(updates).Error
+}
+
+func (r *paymentRepository) TransitionPaymentStatus(transactionID string, fromStatus string, toStatus string, paidAt *time.Time) (bool, error) {
+	updates := map[string]interface{}{
+		"transaction_status": toStatus,
+		"updated_at":         time.Now(),
+	}
+
+	if paidAt != nil {
+		updates["paid_at"] = paidAt
+	}
+
+	result := r.db.Model(&models.Payment{}).
+		Where("transaction_id = ? AND transaction_status = ?", transactionID, fromStatus).
+		Updates(updates)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *paymentRepository) FindPendingPaymentsExpiringBefore(deadline time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.
+		Where("transaction_status = ? AND expiry_time IS NOT NULL AND expiry_time <= ?", models.PaymentStatusPending, deadline).
+		Order("expiry_time ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+func (r *paymentRepository) FindPaymentsCreatedBetween(start time.Time, end time.Time) ([]models.Payment, error) {
+	var payments []models.Payment
+	err := r.db.
+		Where("created_at >= ? AND created_at < ? AND transaction_id <> ''", start, end).
+		Order("created_at ASC").
+		Find(&payments).Error
+	return payments, err
+}
+
+func (r *paymentRepository) CreateEvent(event *models.PaymentEvent) (bool, error) {
+	result := r.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "dedup_key"}},
+		DoNothing: true,
+	}).Create(event)
+
+	return result.RowsAffected > 0, result.Error
+}
+
+func (r *paymentRepository) FindEventByID(id uint) (*models.PaymentEvent, error) {
+	var event models.PaymentEvent
+	if err := r.db.First(&event, id).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *paymentRepository) FindEventByDedupKey(dedupKey string) (*models.PaymentEvent, error) {
+	var event models.PaymentEvent
+	if err := r.db.Where("dedup_key = ?", dedupKey).First(&event).Error; err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func (r *paymentRepository) FindEvents(filter PaymentEventFilter) ([]models.PaymentEvent, int64, error) {
+	var events []models.PaymentEvent
+	var total int64
+
+	query := r.db.Model(&models.PaymentEvent{})
+
+	if filter.OrderID != "" {
+		query = query.Where("order_id = ?", filter.OrderID)
+	}
+	if filter.TransactionID != "" {
+		query = query.Where("transaction_id = ?", filter.TransactionID)
+	}
+	if filter.ProcessingStatus != "" {
+		query = query.Where("processing_status = ?", filter.ProcessingStatus)
+	}
+
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	err := query.
+		Order("created_at DESC").
+		Offset((filter.Page - 1) * filter.Limit).
+		Limit(filter.Limit).
+		Find(&events).Error
+
+	return events, total, err
+}
+
+func (r *paymentRepository) UpdateEventResult(id uint, processingStatus string, note string, isReplay bool) error {
+	now := time.Now()
+	updates := map[string]interface{}{
+		"processing_status": processingStatus,
+		"processing_note":   note,
+		"processed_at":      &now,
+		"updated_at":        now,
+	}
+
+	if isReplay {
+		updates["replay_count"] = gorm.Expr("replay_count + 1")
+	}
+
+	return r.db.Model(&models.PaymentEvent{}).Where("id = ?", id).Updates(updates).Error
 }
\ No newline at end of file
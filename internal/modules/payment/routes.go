@@ -1,21 +1,56 @@
 package payment
 
 import (
+	"time"
+
 	"ezytix-be/internal/middleware"
+	"ezytix-be/internal/models"
 	"ezytix-be/internal/modules/booking"
+	"ezytix-be/internal/modules/pricing"
+	"ezytix-be/internal/scheduler"
 	"github.com/gofiber/fiber/v2"
 	"gorm.io/gorm"
 )
 
-func PaymentRegisterRoutes(app *fiber.App, db *gorm.DB) {
+func PaymentRegisterRoutes(app *fiber.App, db *gorm.DB) PaymentService {
 	paymentRepo := NewPaymentRepository(db)
 	bookingRepo := booking.NewBookingRepository(db)
-	paymentService := NewPaymentService(paymentRepo, bookingRepo)
+	gateway := NewPaymentGateway()
+	pricingService := pricing.NewPricingService(pricing.NewPricingRepository(db))
+	paymentService := NewPaymentService(paymentRepo, bookingRepo, gateway, pricingService)
 	paymentHandler := NewPaymentHandler(paymentService)
 
 	api := app.Group("/api/v1/payments")
-	api.Post("/initiate", middleware.JWTMiddleware, paymentHandler.InitiatePayment)
-	api.Post("/orders/:orderID/cancel", middleware.JWTMiddleware, paymentHandler.CancelPayment)
-	api.Get("/orders/:orderID", middleware.JWTMiddleware, paymentHandler.GetPaymentStatus)
-	api.Post("/webhook", paymentHandler.HandleWebhook)
+	// Partner dengan API key boleh membayar order miliknya sendiri
+	pay := middleware.RequireScope(models.ScopePaymentsWrite)
+	api.Post("/initiate", middleware.RequireAuth, pay, middleware.RateLimit(middleware.RateLimitConfig{
+		Name:    "payment-initiate",
+		Max:     10,
+		Window:  time.Minute,
+		KeyFunc: middleware.KeyByUser,
+	}), paymentHandler.InitiatePayment)
+	api.Post("/orders/:orderID/cancel", middleware.RequireAuth, pay, paymentHandler.CancelPayment)
+	api.Get("/orders/:orderID", middleware.RequireAuth, middleware.RequireScope(models.ScopeBookingsRead), paymentHandler.GetPaymentStatus)
+	// Batas longgar: Midtrans mengirim dari sedikit IP dan akan retry jika ditolak
+	api.Post("/webhook", middleware.RateLimit(middleware.RateLimitConfig{
+		Name:   "payment-webhook",
+		Max:    300,
+		Window: time.Minute,
+	}), paymentHandler.HandleWebhook)
+
+	admin := app.Group("/api/v1/admin/payments")
+	admin.Use(middleware.JWTMiddleware)
+	admin.Use(middleware.RequireRole("admin"))
+	admin.Get("/events", paymentHandler.ListPaymentEvents)
+	admin.Post("/events/:id/replay", paymentHandler.ReplayPaymentEvent)
+	admin.Get("/reconciliation", paymentHandler.GetMismatchReport)
+
+	if fakeGateway, ok := gateway.(*FakeGateway); ok {
+		fakeHandler := NewFakeGatewayHandler(fakeGateway)
+		api.Post("/fake-gateway/transactions/:transactionID/notify", fakeHandler.EmitWebhook)
+	}
+
+	scheduler.StartReconcileJob(paymentService)
+
+	return paymentService
 }
\ No newline at end of file
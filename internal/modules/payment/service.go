@@ -1,71 +1,128 @@
 package payment
 
 import (
-	"crypto/sha512"
-	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"log"
+	"strings"
 	"time"
 
-	"ezytix-be/internal/config"
 	"ezytix-be/internal/models"
+	"ezytix-be/internal/modules/booking"
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/jwt"
 
-	"github.com/midtrans/midtrans-go"
-	"github.com/midtrans/midtrans-go/coreapi"
+	"github.com/shopspring/decimal"
 )
 
 type BookingServiceContract interface {
 	GetBookingByOrderID(orderID string) (*models.Booking, error)
 	UpdateBookingStatus(orderID string, status string) error
+	SetConvenienceFee(orderID string, fee decimal.Decimal) error
+	GetBookingChangeByCode(changeCode string) (*models.BookingChange, error)
+	CompleteBookingChange(changeCode string) error
+}
+
+// FeeCalculator menghitung biaya layanan per metode pembayaran.
+type FeeCalculator interface {
+	ConvenienceFee(paymentType string, amount decimal.Decimal) (decimal.Decimal, error)
 }
 
 type PaymentService interface {
-	InitiatePayment(req InitiatePaymentRequest) (*InitiatePaymentResponse, error)
+	InitiatePayment(requester *jwt.JWTClaims, req InitiatePaymentRequest) (*InitiatePaymentResponse, error)
 	ProcessWebhook(payload map[string]interface{}) error
 	CancelPayment(orderID string) error
-	GetPaymentByOrderID(orderID string) (*InitiatePaymentResponse, error)
-}
-
-type paymentService struct {
-	repo           PaymentRepository
-	bookingRepo    BookingServiceContract
-	midtransClient coreapi.Client
+	CancelOrderPayment(requester *jwt.JWTClaims, orderID string) error
+	GetPaymentByOrderID(requester *jwt.JWTClaims, orderID string) (*InitiatePaymentResponse, error)
+	RefundPayment(orderID string, refundKey string, amount decimal.Decimal, reason string) error
+	ListEvents(filter PaymentEventFilter) (*PaymentEventListResponse, error)
+	ReplayEvent(eventID uint) (*models.PaymentEvent, error)
+	ReconcileOrder(orderID string) (bool, error)
+	ReconcilePendingPayments() error
+	ReportDailyMismatches() error
+	GetMismatchReport(date time.Time) (*ReconciliationReport, error)
 }
 
-func NewPaymentService(repo PaymentRepository, bookingRepo BookingServiceContract) PaymentService {
+var (
+	// ErrOrderNotFound juga dipakai untuk order milik user lain agar keberadaan
+	// order tersebut tidak bocor.
+	ErrOrderNotFound        = apperror.NotFound("ORDER_NOT_FOUND", "order not found")
+	ErrPaymentNotFound      = apperror.NotFound("PAYMENT_NOT_FOUND", "payment not found")
+	ErrPaymentEventNotFound = apperror.NotFound("PAYMENT_EVENT_NOT_FOUND", "payment event not found")
+	ErrOrderAlreadyPaid     = apperror.Conflict("ORDER_ALREADY_PAID", "booking already paid")
+	ErrOrderNotPayable      = apperror.Conflict("ORDER_NOT_PAYABLE", "order is not waiting for payment")
+	ErrOrderExpired         = apperror.Expired("ORDER_EXPIRED", "booking expired")
+	ErrPaymentConflict      = apperror.Conflict("PAYMENT_CONFLICT", "payment status changed concurrently, please retry")
+	ErrPaymentNotRefundable = apperror.Conflict("PAYMENT_NOT_REFUNDABLE", "payment cannot be refunded")
+	ErrInvalidRefundAmount  = apperror.BadRequest("INVALID_REFUND_AMOUNT", "invalid refund amount")
+)
 
-	var client coreapi.Client
-	
-	env := midtrans.Sandbox
-	if config.AppConfig.MidtransIsProduction {
-		env = midtrans.Production
-	}
+// Pembayaran pending yang akan kedaluwarsa dalam rentang ini dicek ke gateway
+// agar webhook yang hilang tidak membuat booking terbayar ikut dibatalkan.
+const reconcileWindow = 10 * time.Minute
+
+// Transisi status yang sah. Notifikasi yang mencoba mundur (misalnya pending
+// setelah settlement) dicatat tetapi tidak diterapkan.
+var paymentStatusTransitions = map[string][]string{
+	models.PaymentStatusPending: {
+		models.PaymentStatusSettlement,
+		models.PaymentStatusCancel,
+		models.PaymentStatusExpire,
+		models.PaymentStatusDeny,
+	},
+	models.PaymentStatusSettlement: {
+		models.PaymentStatusRefund,
+		models.PaymentStatusPartialRefund,
+	},
+	models.PaymentStatusPartialRefund: {
+		models.PaymentStatusRefund,
+	},
+}
 
-	client.New(config.AppConfig.MidtransServerKey, env)
+type paymentService struct {
+	repo        PaymentRepository
+	bookingRepo BookingServiceContract
+	gateway     PaymentGateway
+	fees        FeeCalculator
+}
 
+func NewPaymentService(repo PaymentRepository, bookingRepo BookingServiceContract, gateway PaymentGateway, fees FeeCalculator) PaymentService {
 	return &paymentService{
-		repo:           repo,
-		bookingRepo:    bookingRepo,
-		midtransClient: client,
+		repo:        repo,
+		bookingRepo: bookingRepo,
+		gateway:     gateway,
+		fees:        fees,
 	}
 }
 
-func (s *paymentService) InitiatePayment(req InitiatePaymentRequest) (*InitiatePaymentResponse, error) {
+func (s *paymentService) InitiatePayment(requester *jwt.JWTClaims, req InitiatePaymentRequest) (*InitiatePaymentResponse, error) {
+	if err := s.authorizeOrder(requester, req.OrderID); err != nil {
+		return nil, err
+	}
+
+	if models.IsBookingChangeCode(req.OrderID) {
+		return s.initiateChangePayment(req)
+	}
 
 	booking, err := s.bookingRepo.GetBookingByOrderID(req.OrderID)
 	if err != nil {
-		return nil, errors.New("booking not found")
+		return nil, ErrOrderNotFound
 	}
 	if booking.Status == models.BookingStatusPaid {
-		return nil, errors.New("booking already paid")
+		return nil, ErrOrderAlreadyPaid
+	}
+	// Order yang dibatalkan atau kedaluwarsa sudah melepas kursinya
+	if booking.Status != models.BookingStatusPending {
+		return nil, ErrOrderNotPayable.Withf("booking with status %s cannot be paid", booking.Status)
 	}
 
 	if booking.ExpiredAt == nil {
-		return nil, errors.New("booking expiry data is invalid")
+		return nil, apperror.Internal(fmt.Errorf("booking %s has no expiry", req.OrderID))
 	}
 
 	if time.Now().After(*booking.ExpiredAt) {
-		return nil, errors.New("booking expired")
+		return nil, ErrOrderExpired
 	}
 
 	existing, _ := s.repo.FindPaymentByOrderID(req.OrderID)
@@ -80,100 +137,108 @@ func (s *paymentService) InitiatePayment(req InitiatePaymentRequest) (*InitiateP
 	minutesLeft := int(remainingDuration.Minutes())
 
 	if minutesLeft < 1 {
-		return nil, errors.New("booking time is almost up, please re-book")
+		return nil, ErrOrderExpired.Withf("booking time is almost up, please re-book")
 	}
 
-	now := time.Now()
-	midtransTimeFormat := now.Format("2006-01-02 15:04:05 -0700")
+	// Tagihan dengan metode lama ditutup dulu agar customer tidak bisa
+	// membayar dua tagihan dengan nominal berbeda untuk order yang sama.
+	if err := s.CancelPayment(req.OrderID); err != nil {
+		return nil, fmt.Errorf("failed to cancel previous charge: %w", err)
+	}
+
+	// Biaya layanan bergantung metode bayar, jadi dihitung ulang setiap kali
+	// user memilih metode (subtotal = total tanpa biaya layanan sebelumnya).
+	subTotal := booking.TotalPrice.Sub(booking.ConvenienceFee)
+	convenienceFee, err := s.fees.ConvenienceFee(req.PaymentType, subTotal)
+	if err != nil {
+		return nil, fmt.Errorf("failed to calculate convenience fee: %w", err)
+	}
+	if err := s.bookingRepo.SetConvenienceFee(req.OrderID, convenienceFee); err != nil {
+		return nil, fmt.Errorf("failed to save convenience fee: %w", err)
+	}
+	booking.ConvenienceFee = convenienceFee
+	booking.TotalPrice = subTotal.Add(convenienceFee)
 
 	grossAmt := int64(booking.TotalPrice.InexactFloat64())
-	
-	chargeReq := &coreapi.ChargeReq{
-		PaymentType: coreapi.CoreapiPaymentType(req.PaymentType),
-		TransactionDetails: midtrans.TransactionDetails{
-			OrderID:  req.OrderID,
-			GrossAmt: grossAmt,
-		},
-		CustomExpiry: &coreapi.CustomExpiry{
-			OrderTime:      midtransTimeFormat,
-			ExpiryDuration: minutesLeft,        
-			Unit:           "minute",
-		},
-		Metadata: map[string]interface{}{
-			"user_id": fmt.Sprintf("%d", booking.UserID),
-		},
-	}
-
-	switch req.PaymentType {
-	case "bank_transfer":
-		chargeReq.BankTransfer = &coreapi.BankTransferDetails{
-			Bank: midtrans.Bank(req.Bank),
-		}
-	case "echannel":
-		chargeReq.EChannel = &coreapi.EChannelDetail{
-			BillInfo1: "Payment For:",
-			BillInfo2: "Flight Ticket",
-		}
-	case "qris":
-		chargeReq.Qris = &coreapi.QrisDetails{
-			Acquirer: "gopay",
-		}
-	case "gopay":
-		chargeReq.Gopay = &coreapi.GopayDetails{
-			EnableCallback: true,
-			CallbackUrl:    config.AppConfig.FrontendURL + "/payment-finish",
-		}
-	default:
-		return nil, errors.New("unsupported payment type")
+
+	chargeReq := ChargeRequest{
+		OrderID:       req.OrderID,
+		PaymentType:   req.PaymentType,
+		Bank:          req.Bank,
+		GrossAmount:   grossAmt,
+		OrderTime:     time.Now(),
+		ExpiryMinutes: minutesLeft,
+		UserID:        booking.UserID,
 	}
 
-	resp, midtransErr := s.midtransClient.ChargeTransaction(chargeReq)
-	if midtransErr != nil {
-		return nil, fmt.Errorf("midtrans error: %v", midtransErr)
+	result, err := s.gateway.Charge(chargeReq)
+	if err != nil {
+		return nil, err
 	}
 
-	return s.saveAndRespond(booking, req, resp, booking.ExpiredAt)
+	return s.saveAndRespond(booking.TotalPrice, req, result, booking.ExpiredAt)
 }
 
-func (s *paymentService) saveAndRespond(booking *models.Booking, req InitiatePaymentRequest, resp *coreapi.ChargeResponse, strictExpiry *time.Time) (*InitiatePaymentResponse, error) {
-	var vaNumber, bank, billKey, billerCode, qrUrl, deeplink string
+// initiateChangePayment menagih selisih tarif dan biaya reschedule. Kode
+// perubahan dipakai sebagai order_id sehingga webhook dan rekonsiliasi
+// melewati jalur yang sama dengan pembayaran order biasa.
+func (s *paymentService) initiateChangePayment(req InitiatePaymentRequest) (*InitiatePaymentResponse, error) {
+	change, err := s.bookingRepo.GetBookingChangeByCode(req.OrderID)
+	if err != nil {
+		return nil, ErrOrderNotFound.Withf("reschedule not found")
+	}
+	if change.Status != models.BookingChangeStatusPendingPayment {
+		return nil, ErrOrderNotPayable.Withf("reschedule is not waiting for payment")
+	}
+	if change.ExpiredAt == nil || time.Now().After(*change.ExpiredAt) {
+		return nil, ErrOrderExpired.Withf("reschedule expired")
+	}
 
-	switch req.PaymentType {
-	case "bank_transfer":
-		if len(resp.VaNumbers) > 0 {
-			vaNumber = resp.VaNumbers[0].VANumber
-			bank = resp.VaNumbers[0].Bank
-		} else if resp.PermataVaNumber != "" {
-			vaNumber = resp.PermataVaNumber
-			bank = "permata"
-		}
-	case "echannel":
-		billKey = resp.BillKey
-		billerCode = resp.BillerCode
-	case "qris", "gopay":
-		for _, action := range resp.Actions {
-			if action.Name == "generate-qr-code" {
-				qrUrl = action.URL
-			}
-			if action.Name == "deeplink-redirect" {
-				deeplink = action.URL
-			}
+	existing, _ := s.repo.FindPaymentByOrderID(req.OrderID)
+	if existing != nil && existing.TransactionStatus == models.PaymentStatusPending {
+		if existing.PaymentType == req.PaymentType {
+			return s.constructResponseFromModel(existing), nil
 		}
 	}
-	
+
+	minutesLeft := int(change.ExpiredAt.Sub(time.Now()).Minutes())
+	if minutesLeft < 1 {
+		return nil, ErrOrderExpired.Withf("reschedule time is almost up, please request a new one")
+	}
+	if err := s.CancelPayment(req.OrderID); err != nil {
+		return nil, fmt.Errorf("failed to cancel previous charge: %w", err)
+	}
+
+	result, err := s.gateway.Charge(ChargeRequest{
+		OrderID:       req.OrderID,
+		PaymentType:   req.PaymentType,
+		Bank:          req.Bank,
+		GrossAmount:   int64(change.AmountDue.InexactFloat64()),
+		OrderTime:     time.Now(),
+		ExpiryMinutes: minutesLeft,
+		UserID:        change.UserID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return s.saveAndRespond(change.AmountDue, req, result, change.ExpiredAt)
+}
+
+func (s *paymentService) saveAndRespond(amount decimal.Decimal, req InitiatePaymentRequest, result *ChargeResult, strictExpiry *time.Time) (*InitiatePaymentResponse, error) {
 	paymentModel := &models.Payment{
 		OrderID:           req.OrderID,
-		TransactionID:     resp.TransactionID,
+		TransactionID:     result.TransactionID,
 		PaymentType:       req.PaymentType,
-		GrossAmount:       booking.TotalPrice,
-		TransactionStatus: resp.TransactionStatus,
+		GrossAmount:       amount,
+		TransactionStatus: result.TransactionStatus,
 		
-		Bank:       bank,
-		VaNumber:   vaNumber,
-		BillKey:    billKey,
-		BillerCode: billerCode,
-		QrUrl:      qrUrl,
-		Deeplink:   deeplink,
+		Bank:       result.Bank,
+		VaNumber:   result.VaNumber,
+		BillKey:    result.BillKey,
+		BillerCode: result.BillerCode,
+		QrUrl:      result.QrUrl,
+		Deeplink:   result.Deeplink,
 		
 		ExpiryTime: strictExpiry,
 	}
@@ -223,77 +288,495 @@ func (s *paymentService) constructResponseFromModel(p *models.Payment) *Initiate
 }
 
 func (s *paymentService) ProcessWebhook(payload map[string]interface{}) error {
+	if err := s.gateway.VerifyWebhook(payload); err != nil {
+		return err
+	}
+
+	event, err := s.recordEvent(payload)
+	if err != nil {
+		return err
+	}
+
+	if event == nil {
+		return nil
+	}
+
+	return s.processEvent(event, payload, false)
+}
+
+func (s *paymentService) ListEvents(filter PaymentEventFilter) (*PaymentEventListResponse, error) {
+	if filter.Page < 1 {
+		filter.Page = 1
+	}
+	if filter.Limit < 1 || filter.Limit > 100 {
+		filter.Limit = 20
+	}
+
+	events, total, err := s.repo.FindEvents(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	if events == nil {
+		events = []models.PaymentEvent{}
+	}
+
+	return &PaymentEventListResponse{
+		Events: events,
+		Page:   filter.Page,
+		Limit:  filter.Limit,
+		Total:  total,
+	}, nil
+}
+
+func (s *paymentService) ReplayEvent(eventID uint) (*models.PaymentEvent, error) {
+	event, err := s.repo.FindEventByID(eventID)
+	if err != nil {
+		return nil, ErrPaymentEventNotFound
+	}
+
+	var payload map[string]interface{}
+	if err := json.Unmarshal([]byte(event.RawPayload), &payload); err != nil {
+		return nil, fmt.Errorf("stored payload is corrupted: %v", err)
+	}
+
+	processErr := s.processEvent(event, payload, true)
+
+	replayed, err := s.repo.FindEventByID(eventID)
+	if err != nil {
+		return nil, err
+	}
+
+	if processErr != nil {
+		return replayed, processErr
+	}
+	return replayed, nil
+}
+
+func (s *paymentService) ReconcileOrder(orderID string) (bool, error) {
+	payment, err := s.repo.FindPaymentByOrderID(orderID)
+	if err != nil {
+		return false, nil
+	}
+
+	if payment.TransactionStatus == models.PaymentStatusPending {
+		if err := s.reconcilePayment(payment); err != nil {
+			return false, err
+		}
+
+		payment, err = s.repo.FindPaymentByTransactionID(payment.TransactionID)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	return payment.TransactionStatus == models.PaymentStatusSettlement, nil
+}
+
+func (s *paymentService) ReconcilePendingPayments() error {
+	payments, err := s.repo.FindPendingPaymentsExpiringBefore(time.Now().Add(reconcileWindow))
+	if err != nil {
+		return err
+	}
+
+	for i := range payments {
+		if err := s.reconcilePayment(&payments[i]); err != nil {
+			log.Printf("[RECONCILE] Failed to reconcile transaction %s (order %s): %v\n", payments[i].TransactionID, payments[i].OrderID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *paymentService) ReportDailyMismatches() error {
+	yesterday := time.Now().AddDate(0, 0, -1)
+
+	report, err := s.GetMismatchReport(yesterday)
+	if err != nil {
+		return err
+	}
+
+	log.Printf("[RECONCILE] Daily report %s: %d payments checked, %d mismatches\n", report.Date, report.TotalChecked, len(report.Mismatches))
+	for _, m := range report.Mismatches {
+		log.Printf("[RECONCILE] Mismatch order=%s trx=%s local=%s/%s gateway=%s/%s: %s\n",
+			m.OrderID, m.TransactionID, m.LocalStatus, m.LocalAmount, m.GatewayStatus, m.GatewayAmount, m.Reason)
+	}
+
+	return nil
+}
+
+func (s *paymentService) GetMismatchReport(date time.Time) (*ReconciliationReport, error) {
+	start := time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, date.Location())
+	end := start.AddDate(0, 0, 1)
+
+	payments, err := s.repo.FindPaymentsCreatedBetween(start, end)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &ReconciliationReport{
+		Date:         start.Format("2006-01-02"),
+		TotalChecked: len(payments),
+		Mismatches:   []PaymentMismatch{},
+		GeneratedAt:  time.Now(),
+	}
+
+	for _, p := range payments {
+		mismatch := PaymentMismatch{
+			OrderID:       p.OrderID,
+			TransactionID: p.TransactionID,
+			LocalStatus:   p.TransactionStatus,
+			LocalAmount:   p.GrossAmount.StringFixed(2),
+		}
+
+		result, err := s.gateway.Status(p.TransactionID)
+		if err != nil {
+			mismatch.Reason = fmt.Sprintf("gateway lookup failed: %v", err)
+			report.Mismatches = append(report.Mismatches, mismatch)
+			continue
+		}
+
+		mismatch.GatewayStatus = result.TransactionStatus
+		mismatch.GatewayAmount = result.GrossAmount
+
+		var reasons []string
+		if toInternalPaymentStatus(result.TransactionStatus) != p.TransactionStatus {
+			reasons = append(reasons, "status differs")
+		}
+		if gatewayAmount, err := decimal.NewFromString(result.GrossAmount); err != nil || !gatewayAmount.Equal(p.GrossAmount.Floor()) {
+			reasons = append(reasons, "amount differs")
+		}
+
+		if len(reasons) > 0 {
+			mismatch.Reason = strings.Join(reasons, ", ")
+			report.Mismatches = append(report.Mismatches, mismatch)
+		}
+	}
+
+	return report, nil
+}
+
+func (s *paymentService) reconcilePayment(payment *models.Payment) error {
+	result, err := s.gateway.Status(payment.TransactionID)
+	if err != nil {
+		return err
+	}
+
+	payload := map[string]interface{}{
+		"order_id":           payment.OrderID,
+		"transaction_id":     payment.TransactionID,
+		"transaction_status": result.TransactionStatus,
+		"transaction_time":   result.TransactionTime,
+		"status_code":        result.StatusCode,
+		"gross_amount":       result.GrossAmount,
+		"payment_type":       result.PaymentType,
+		"fraud_status":       result.FraudStatus,
+		"source":             "reconciler",
+	}
+
+	event, err := s.recordEvent(payload)
+	if err != nil {
+		return err
+	}
+
+	if event == nil {
+		return nil
+	}
+
+	return s.processEvent(event, payload, false)
+}
+
+// recordEvent menyimpan notifikasi mentah. Mengembalikan nil jika notifikasi
+// yang sama sudah pernah diproses (bukan gagal) sehingga tidak perlu diulang.
+func (s *paymentService) recordEvent(payload map[string]interface{}) (*models.PaymentEvent, error) {
 	orderID, _ := payload["order_id"].(string)
 	transactionID, _ := payload["transaction_id"].(string)
+	transactionStatus, _ := payload["transaction_status"].(string)
 	statusCode, _ := payload["status_code"].(string)
-	grossAmount, _ := payload["gross_amount"].(string)
-	signatureKey, _ := payload["signature_key"].(string)
+	transactionTime, _ := payload["transaction_time"].(string)
+
+	rawPayload, err := json.Marshal(payload)
+	if err != nil {
+		return nil, err
+	}
+
+	event := &models.PaymentEvent{
+		DedupKey:          fmt.Sprintf("%s:%s:%s", transactionID, transactionStatus, transactionTime),
+		OrderID:           orderID,
+		TransactionID:     transactionID,
+		TransactionStatus: transactionStatus,
+		StatusCode:        statusCode,
+		TransactionTime:   transactionTime,
+		RawPayload:        string(rawPayload),
+		ProcessingStatus:  models.PaymentEventStatusFailed,
+	}
+
+	created, err := s.repo.CreateEvent(event)
+	if err != nil {
+		return nil, err
+	}
+	if created {
+		return event, nil
+	}
+
+	existing, err := s.repo.FindEventByDedupKey(event.DedupKey)
+	if err != nil {
+		return nil, err
+	}
+
+	if existing.ProcessingStatus != models.PaymentEventStatusFailed {
+		log.Printf("[WEBHOOK] Duplicate notification %s ignored\n", event.DedupKey)
+		return nil, nil
+	}
+
+	return existing, nil
+}
+
+func (s *paymentService) processEvent(event *models.PaymentEvent, payload map[string]interface{}, isReplay bool) error {
+	processingStatus, note, err := s.applyNotification(payload)
+	if err != nil {
+		processingStatus = models.PaymentEventStatusFailed
+		note = err.Error()
+	}
+
+	if updateErr := s.repo.UpdateEventResult(event.ID, processingStatus, note, isReplay); updateErr != nil {
+		log.Printf("[WEBHOOK] Failed to update event %d result: %v\n", event.ID, updateErr)
+	}
+
+	return err
+}
+
+func (s *paymentService) applyNotification(payload map[string]interface{}) (string, string, error) {
+	orderID, _ := payload["order_id"].(string)
+	transactionID, _ := payload["transaction_id"].(string)
 	transactionStatus, _ := payload["transaction_status"].(string)
 
-	input := orderID + statusCode + grossAmount + config.AppConfig.MidtransServerKey
-	hash := sha512.Sum512([]byte(input))
-	if signatureKey != hex.EncodeToString(hash[:]) {
-		return errors.New("invalid signature key")
+	internalStatus := toInternalPaymentStatus(transactionStatus)
+	isPaid := internalStatus == models.PaymentStatusSettlement
+
+	payment, err := s.repo.FindPaymentByTransactionID(transactionID)
+	if err != nil {
+		return "", "", fmt.Errorf("payment for transaction %s not found", transactionID)
 	}
 
-	var internalStatus string
-	isPaid := false
+	if payment.TransactionStatus != internalStatus {
+		if isPaid && isClosedPaymentStatus(payment.TransactionStatus) {
+			return s.refundLateSettlement(payment, payment.TransactionStatus, "payment "+payment.TransactionStatus)
+		}
+		if !canTransitionPaymentStatus(payment.TransactionStatus, internalStatus) {
+			note := fmt.Sprintf("illegal transition %s -> %s", payment.TransactionStatus, internalStatus)
+			log.Printf("[WEBHOOK] %s for transaction %s\n", note, transactionID)
+			return models.PaymentEventStatusIgnored, note, nil
+		}
+
+		var paidAt *time.Time
+		if isPaid {
+			now := time.Now()
+			paidAt = &now
+		}
+
+		ok, err := s.repo.TransitionPaymentStatus(transactionID, payment.TransactionStatus, internalStatus, paidAt)
+		if err != nil {
+			return "", "", err
+		}
+		if !ok {
+			return "", "", ErrPaymentConflict
+		}
+	} else if !isPaid {
+		return models.PaymentEventStatusIgnored, "status unchanged", nil
+	}
+
+	if isPaid {
+		if models.IsBookingChangeCode(orderID) {
+			if err := s.bookingRepo.CompleteBookingChange(orderID); err != nil {
+				if errors.Is(err, booking.ErrBookingChangeClosed) || errors.Is(err, booking.ErrInvalidBookingStatus) {
+					return s.refundLateSettlement(payment, models.PaymentStatusSettlement, fmt.Sprintf("reschedule could not be applied (%v)", err))
+				}
+				return "", "", err
+			}
+		} else if err := s.bookingRepo.UpdateBookingStatus(orderID, models.BookingStatusPaid); err != nil {
+			if errors.Is(err, booking.ErrBookingAlreadyCancelled) {
+				return s.refundLateSettlement(payment, models.PaymentStatusSettlement, "booking cancelled")
+			}
+			return "", "", err
+		}
+	}
+
+	return models.PaymentEventStatusProcessed, fmt.Sprintf("%s -> %s", payment.TransactionStatus, internalStatus), nil
+}
+
+// refundLateSettlement menangani dana yang tetap masuk setelah pembayaran atau
+// ordernya ditutup, misalnya settlement yang tiba setelah cron atau customer
+// membatalkan booking. Kursi order tersebut sudah dilepas, jadi booking tidak
+// dihidupkan lagi dan dana dikembalikan penuh. Jika refund gagal, event
+// tercatat failed dan status lokal tetap berbeda dari gateway sehingga muncul
+// di laporan mismatch harian. closedBy hanya dipakai untuk log dan catatan.
+func (s *paymentService) refundLateSettlement(payment *models.Payment, fromStatus string, closedBy string) (string, string, error) {
+	log.Printf("[WEBHOOK] Late settlement for transaction %s (order %s) after %s, refunding\n",
+		payment.TransactionID, payment.OrderID, closedBy)
+
+	refundReq := RefundRequest{
+		RefundKey: "late-" + payment.TransactionID,
+		Amount:    payment.GrossAmount.IntPart(),
+		Reason:    "payment settled after the order was closed",
+	}
+	if err := s.gateway.Refund(payment.TransactionID, refundReq); err != nil {
+		log.Printf("[WEBHOOK] Refund of late settlement %s failed, needs manual follow-up: %v\n", payment.TransactionID, err)
+		return "", "", fmt.Errorf("late settlement after %s could not be refunded: %w", closedBy, err)
+	}
+
+	now := time.Now()
+	ok, err := s.repo.TransitionPaymentStatus(payment.TransactionID, fromStatus, models.PaymentStatusRefund, &now)
+	if err != nil {
+		return "", "", err
+	}
+	if !ok {
+		return "", "", ErrPaymentConflict
+	}
+
+	note := fmt.Sprintf("late settlement after %s, refunded %s", closedBy, payment.GrossAmount.StringFixed(2))
+	return models.PaymentEventStatusProcessed, note, nil
+}
+
+// isClosedPaymentStatus bernilai true untuk pembayaran yang sudah ditutup
+// sebelum dana diterima.
+func isClosedPaymentStatus(status string) bool {
+	switch status {
+	case models.PaymentStatusCancel, models.PaymentStatusExpire, models.PaymentStatusDeny:
+		return true
+	}
+	return false
+}
+
+func toInternalPaymentStatus(transactionStatus string) string {
 	switch transactionStatus {
 	case "capture", "settlement":
-		internalStatus = models.PaymentStatusSettlement
-		isPaid = true
+		return models.PaymentStatusSettlement
 	case "pending":
-		internalStatus = models.PaymentStatusPending
+		return models.PaymentStatusPending
 	case "deny", "cancel", "expire":
-		internalStatus = models.PaymentStatusCancel
+		return models.PaymentStatusCancel
 	default:
-		internalStatus = transactionStatus
+		return transactionStatus
 	}
+}
 
-	var paidAt *time.Time
-	if isPaid {
-		now := time.Now()
-		paidAt = &now
+func canTransitionPaymentStatus(from string, to string) bool {
+	for _, allowed := range paymentStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
 	}
+	return false
+}
 
-	if err := s.repo.UpdatePaymentStatusByTransactionID(transactionID, internalStatus, paidAt); err != nil {
-		return err
+// authorizeOrder memastikan order (atau reschedule) milik requester, kecuali
+// requester adalah admin.
+func (s *paymentService) authorizeOrder(requester *jwt.JWTClaims, orderID string) error {
+	var ownerID uint
+	if models.IsBookingChangeCode(orderID) {
+		change, err := s.bookingRepo.GetBookingChangeByCode(orderID)
+		if err != nil {
+			return ErrOrderNotFound
+		}
+		ownerID = change.UserID
+	} else {
+		booking, err := s.bookingRepo.GetBookingByOrderID(orderID)
+		if err != nil {
+			return ErrOrderNotFound
+		}
+		ownerID = booking.UserID
 	}
 
-	if isPaid {
-		return s.bookingRepo.UpdateBookingStatus(orderID, models.BookingStatusPaid)
+	if !requester.CanAccess(ownerID) {
+		return ErrOrderNotFound
 	}
-
 	return nil
 }
 
+func (s *paymentService) CancelOrderPayment(requester *jwt.JWTClaims, orderID string) error {
+	if err := s.authorizeOrder(requester, orderID); err != nil {
+		return err
+	}
+	return s.CancelPayment(orderID)
+}
+
+// CancelPayment membatalkan semua tagihan order yang masih pending, termasuk
+// tagihan lama dari metode pembayaran yang sudah diganti. Jika gateway menolak
+// pembatalan dan tagihan tetap dibayar, settlement-nya di-refund lewat
+// refundLateSettlement.
 func (s *paymentService) CancelPayment(orderID string) error {
-	payment, err := s.repo.FindPaymentByOrderID(orderID)
+	payments, err := s.repo.FindPaymentsByOrderID(orderID)
 	if err != nil {
 		return err
 	}
 
-	isPending := payment.TransactionStatus == models.PaymentStatusPending || payment.TransactionStatus == "waiting"
+	for _, payment := range payments {
+		if !isPendingPaymentStatus(payment.TransactionStatus) {
+			continue
+		}
 
-	if isPending {
-		fmt.Printf("🚀 Cancelling Transaction ID: %s for Order: %s\n", payment.TransactionID, orderID)
-		
-		_, midErr := s.midtransClient.CancelTransaction(payment.TransactionID)
-		if midErr != nil {
-			fmt.Printf("⚠️ Midtrans Cancel Note: %v\n", midErr)
+		log.Printf("[PAYMENT] Cancelling transaction %s for order %s\n", payment.TransactionID, orderID)
+		if err := s.gateway.Cancel(payment.TransactionID); err != nil {
+			log.Printf("[PAYMENT] Gateway cancel for transaction %s failed: %v\n", payment.TransactionID, err)
 		}
 
-		return s.repo.UpdatePaymentStatusByTransactionID(payment.TransactionID, models.PaymentStatusCancel, nil)
+		ok, err := s.repo.TransitionPaymentStatus(payment.TransactionID, payment.TransactionStatus, models.PaymentStatusCancel, nil)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return ErrPaymentConflict
+		}
 	}
 
 	return nil
 }
 
-func (s *paymentService) GetPaymentByOrderID(orderID string) (*InitiatePaymentResponse, error) {
-	payment, err := s.repo.FindPaymentByOrderID(orderID)
+func isPendingPaymentStatus(status string) bool {
+	return status == models.PaymentStatusPending || status == "waiting"
+}
+
+// RefundPayment mengembalikan dana dari tagihan order yang sudah dibayar.
+// Tagihan terbaru belum tentu yang dibayar jika customer sempat berganti
+// metode pembayaran.
+func (s *paymentService) RefundPayment(orderID string, refundKey string, amount decimal.Decimal, reason string) error {
+	payment, err := s.repo.FindSettledPaymentByOrderID(orderID)
 	if err != nil {
+		return ErrPaymentNotRefundable.Withf("order %s has no settled payment to refund", orderID)
+	}
+
+	if amount.LessThanOrEqual(decimal.Zero) || amount.GreaterThan(payment.GrossAmount) {
+		return ErrInvalidRefundAmount
+	}
+
+	refundReq := RefundRequest{
+		RefundKey: refundKey,
+		Amount:    amount.IntPart(),
+		Reason:    reason,
+	}
+
+	if err := s.gateway.Refund(payment.TransactionID, refundReq); err != nil {
+		return err
+	}
+
+	refundStatus := models.PaymentStatusPartialRefund
+	if amount.Equal(payment.GrossAmount) {
+		refundStatus = models.PaymentStatusRefund
+	}
+
+	return s.repo.UpdatePaymentStatusByTransactionID(payment.TransactionID, refundStatus, nil)
+}
+
+func (s *paymentService) GetPaymentByOrderID(requester *jwt.JWTClaims, orderID string) (*InitiatePaymentResponse, error) {
+	if err := s.authorizeOrder(requester, orderID); err != nil {
 		return nil, err
 	}
+
+	payment, err := s.repo.FindPaymentByOrderID(orderID)
+	if err != nil {
+		return nil, ErrPaymentNotFound
+	}
 	resp := &InitiatePaymentResponse{
 		OrderID:           payment.OrderID,
 		TransactionID:     payment.TransactionID,
@@ -0,0 +1,281 @@
+package payment
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"ezytix-be/internal/models"
+	"ezytix-be/pkg/jwt"
+
+	"github.com/shopspring/decimal"
+	"gorm.io/gorm"
+)
+
+const (
+	ownerID = 10
+	otherID = 20
+)
+
+var (
+	owner    = &jwt.JWTClaims{UserID: ownerID, Role: "user"}
+	stranger = &jwt.JWTClaims{UserID: otherID, Role: "user"}
+	admin    = &jwt.JWTClaims{UserID: otherID, Role: jwt.RoleAdmin}
+)
+
+// Fake di bawah hanya mengimplementasikan method yang dipakai test. Method
+// lain panic karena interface yang di-embed bernilai nil.
+type fakePaymentRepository struct {
+	PaymentRepository
+	payments []*models.Payment
+}
+
+func (r *fakePaymentRepository) FindPaymentByOrderID(orderID string) (*models.Payment, error) {
+	for i := len(r.payments) - 1; i >= 0; i-- {
+		if r.payments[i].OrderID == orderID {
+			return r.payments[i], nil
+		}
+	}
+	return nil, gorm.ErrRecordNotFound
+}
+
+func (r *fakePaymentRepository) FindPaymentsByOrderID(orderID string) ([]models.Payment, error) {
+	var payments []models.Payment
+	for _, payment := range r.payments {
+		if payment.OrderID == orderID {
+			payments = append(payments, *payment)
+		}
+	}
+	return payments, nil
+}
+
+func (r *fakePaymentRepository) TransitionPaymentStatus(transactionID string, fromStatus string, toStatus string, paidAt *time.Time) (bool, error) {
+	for _, payment := range r.payments {
+		if payment.TransactionID == transactionID && payment.TransactionStatus == fromStatus {
+			payment.TransactionStatus = toStatus
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakePaymentRepository) CreatePayment(payment *models.Payment) error {
+	r.payments = append(r.payments, payment)
+	return nil
+}
+
+type fakeBookingContract struct {
+	BookingServiceContract
+	bookings map[string]*models.Booking
+	changes  map[string]*models.BookingChange
+}
+
+func (b *fakeBookingContract) GetBookingByOrderID(orderID string) (*models.Booking, error) {
+	booking, ok := b.bookings[orderID]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return booking, nil
+}
+
+func (b *fakeBookingContract) GetBookingChangeByCode(changeCode string) (*models.BookingChange, error) {
+	change, ok := b.changes[changeCode]
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+	return change, nil
+}
+
+func (b *fakeBookingContract) SetConvenienceFee(orderID string, fee decimal.Decimal) error {
+	b.bookings[orderID].ConvenienceFee = fee
+	return nil
+}
+
+type flatFee struct{}
+
+func (flatFee) ConvenienceFee(paymentType string, amount decimal.Decimal) (decimal.Decimal, error) {
+	return decimal.NewFromInt(5000), nil
+}
+
+type fakeGateway struct {
+	PaymentGateway
+	cancelled []string
+	charged   []ChargeRequest
+}
+
+func (g *fakeGateway) Cancel(transactionID string) error {
+	g.cancelled = append(g.cancelled, transactionID)
+	return nil
+}
+
+func (g *fakeGateway) Charge(req ChargeRequest) (*ChargeResult, error) {
+	g.charged = append(g.charged, req)
+	return &ChargeResult{TransactionID: fmt.Sprintf("TRX-NEW-%d", len(g.charged)), TransactionStatus: models.PaymentStatusPending}, nil
+}
+
+func newTestPaymentService() (*paymentService, *fakePaymentRepository, *fakeGateway) {
+	repo := &fakePaymentRepository{payments: []*models.Payment{
+		{OrderID: "ORD-1", TransactionID: "TRX-1", TransactionStatus: models.PaymentStatusPending, GrossAmount: decimal.NewFromInt(1500000)},
+	}}
+	bookings := &fakeBookingContract{
+		bookings: map[string]*models.Booking{"ORD-1": {ID: 1, UserID: ownerID, OrderID: "ORD-1"}},
+		changes:  map[string]*models.BookingChange{"CHG-1": {ID: 1, UserID: ownerID, ChangeCode: "CHG-1"}},
+	}
+	gateway := &fakeGateway{}
+
+	return &paymentService{repo: repo, bookingRepo: bookings, gateway: gateway, fees: flatFee{}}, repo, gateway
+}
+
+func TestAuthorizeOrder(t *testing.T) {
+	s, _, _ := newTestPaymentService()
+
+	tests := []struct {
+		name      string
+		requester *jwt.JWTClaims
+		orderID   string
+		wantErr   error
+	}{
+		{name: "owner", requester: owner, orderID: "ORD-1"},
+		{name: "admin", requester: admin, orderID: "ORD-1"},
+		{name: "stranger", requester: stranger, orderID: "ORD-1", wantErr: ErrOrderNotFound},
+		{name: "anonymous", requester: nil, orderID: "ORD-1", wantErr: ErrOrderNotFound},
+		{name: "unknown order", requester: owner, orderID: "ORD-404", wantErr: ErrOrderNotFound},
+		{name: "reschedule owner", requester: owner, orderID: "CHG-1"},
+		{name: "reschedule admin", requester: admin, orderID: "CHG-1"},
+		{name: "reschedule stranger", requester: stranger, orderID: "CHG-1", wantErr: ErrOrderNotFound},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := s.authorizeOrder(tt.requester, tt.orderID)
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("expected %v, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestGetPaymentByOrderID(t *testing.T) {
+	s, _, _ := newTestPaymentService()
+
+	if _, err := s.GetPaymentByOrderID(stranger, "ORD-1"); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("stranger: expected ErrOrderNotFound, got %v", err)
+	}
+
+	for name, requester := range map[string]*jwt.JWTClaims{"owner": owner, "admin": admin} {
+		resp, err := s.GetPaymentByOrderID(requester, "ORD-1")
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if resp.TransactionID != "TRX-1" {
+			t.Fatalf("%s: expected transaction TRX-1, got %s", name, resp.TransactionID)
+		}
+	}
+}
+
+func TestInitiatePaymentHidesOrdersOfOtherUsers(t *testing.T) {
+	s, repo, _ := newTestPaymentService()
+
+	_, err := s.InitiatePayment(stranger, InitiatePaymentRequest{OrderID: "ORD-1", PaymentType: "qris"})
+	if !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound, got %v", err)
+	}
+	if status := repo.payments[0].TransactionStatus; status != models.PaymentStatusPending {
+		t.Fatalf("payment status must stay pending, got %s", status)
+	}
+}
+
+func TestCancelOrderPaymentRejectsOtherUsers(t *testing.T) {
+	s, repo, gateway := newTestPaymentService()
+
+	if err := s.CancelOrderPayment(stranger, "ORD-1"); !errors.Is(err, ErrOrderNotFound) {
+		t.Fatalf("expected ErrOrderNotFound, got %v", err)
+	}
+	if len(gateway.cancelled) != 0 {
+		t.Fatalf("gateway cancel must not be called, got %v", gateway.cancelled)
+	}
+	if status := repo.payments[0].TransactionStatus; status != models.PaymentStatusPending {
+		t.Fatalf("payment status must stay pending, got %s", status)
+	}
+}
+
+func TestCancelOrderPaymentAllowsOwnerAndAdmin(t *testing.T) {
+	for name, requester := range map[string]*jwt.JWTClaims{"owner": owner, "admin": admin} {
+		s, repo, gateway := newTestPaymentService()
+
+		if err := s.CancelOrderPayment(requester, "ORD-1"); err != nil {
+			t.Fatalf("%s: unexpected error: %v", name, err)
+		}
+		if len(gateway.cancelled) != 1 || gateway.cancelled[0] != "TRX-1" {
+			t.Fatalf("%s: expected TRX-1 to be cancelled at the gateway, got %v", name, gateway.cancelled)
+		}
+		if status := repo.payments[0].TransactionStatus; status != models.PaymentStatusCancel {
+			t.Fatalf("%s: expected payment status %s, got %s", name, models.PaymentStatusCancel, status)
+		}
+	}
+}
+
+func TestCancelPaymentCancelsEveryPendingCharge(t *testing.T) {
+	s, repo, gateway := newTestPaymentService()
+	repo.payments = append(repo.payments,
+		&models.Payment{OrderID: "ORD-1", TransactionID: "TRX-2", TransactionStatus: models.PaymentStatusPending},
+		&models.Payment{OrderID: "ORD-1", TransactionID: "TRX-3", TransactionStatus: models.PaymentStatusExpire},
+	)
+
+	if err := s.CancelPayment("ORD-1"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gateway.cancelled) != 2 || gateway.cancelled[0] != "TRX-1" || gateway.cancelled[1] != "TRX-2" {
+		t.Fatalf("expected TRX-1 and TRX-2 to be cancelled at the gateway, got %v", gateway.cancelled)
+	}
+	for i, want := range []string{models.PaymentStatusCancel, models.PaymentStatusCancel, models.PaymentStatusExpire} {
+		if status := repo.payments[i].TransactionStatus; status != want {
+			t.Fatalf("%s: expected status %s, got %s", repo.payments[i].TransactionID, want, status)
+		}
+	}
+}
+
+func TestInitiatePaymentWithNewMethodCancelsPreviousCharge(t *testing.T) {
+	s, repo, gateway := newTestPaymentService()
+	expiredAt := time.Now().Add(30 * time.Minute)
+	booking := s.bookingRepo.(*fakeBookingContract).bookings["ORD-1"]
+	booking.Status = models.BookingStatusPending
+	booking.ExpiredAt = &expiredAt
+	booking.TotalPrice = decimal.NewFromInt(1500000)
+	repo.payments[0].PaymentType = "bank_transfer"
+
+	resp, err := s.InitiatePayment(owner, InitiatePaymentRequest{OrderID: "ORD-1", PaymentType: "qris"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(gateway.cancelled) != 1 || gateway.cancelled[0] != "TRX-1" {
+		t.Fatalf("expected TRX-1 to be cancelled at the gateway, got %v", gateway.cancelled)
+	}
+	if status := repo.payments[0].TransactionStatus; status != models.PaymentStatusCancel {
+		t.Fatalf("expected previous charge status %s, got %s", models.PaymentStatusCancel, status)
+	}
+	if len(gateway.charged) != 1 || resp.TransactionID != "TRX-NEW-1" {
+		t.Fatalf("expected one new charge, got %v", gateway.charged)
+	}
+}
+
+func TestInitiatePaymentWithSameMethodReusesCharge(t *testing.T) {
+	s, repo, gateway := newTestPaymentService()
+	expiredAt := time.Now().Add(30 * time.Minute)
+	booking := s.bookingRepo.(*fakeBookingContract).bookings["ORD-1"]
+	booking.Status = models.BookingStatusPending
+	booking.ExpiredAt = &expiredAt
+	repo.payments[0].PaymentType = "qris"
+
+	resp, err := s.InitiatePayment(owner, InitiatePaymentRequest{OrderID: "ORD-1", PaymentType: "qris"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if resp.TransactionID != "TRX-1" || len(gateway.cancelled) != 0 || len(gateway.charged) != 0 {
+		t.Fatalf("expected TRX-1 to be reused, got %s (cancelled %v, charged %d)", resp.TransactionID, gateway.cancelled, len(gateway.charged))
+	}
+}
@@ -0,0 +1,22 @@
+package pricing
+
+import "github.com/shopspring/decimal"
+
+type CreateFeeRuleRequest struct {
+	Name          string          `json:"name" validate:"required"`
+	FeeType       string          `json:"fee_type" validate:"required,oneof=airport_tax vat convenience_fee"`
+	CalcType      string          `json:"calc_type" validate:"required,oneof=percentage fixed"`
+	Value         decimal.Decimal `json:"value" validate:"required"`
+	AirportID     *uint           `json:"airport_id"`
+	PaymentType   string          `json:"payment_type" validate:"omitempty,oneof=bank_transfer echannel qris gopay"`
+	PassengerType string          `json:"passenger_type"`
+	IsActive      *bool           `json:"is_active"`
+}
+
+// PassengerCharges adalah rincian harga satu penumpang pada satu penerbangan.
+type PassengerCharges struct {
+	BaseFare   decimal.Decimal `json:"base_fare"`
+	AirportTax decimal.Decimal `json:"airport_tax"`
+	VAT        decimal.Decimal `json:"vat"`
+	Total      decimal.Decimal `json:"total"`
+}
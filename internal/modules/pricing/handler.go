@@ -0,0 +1,85 @@
+package pricing
+
+import (
+	"strconv"
+
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidFeeRuleID = apperror.BadRequest("INVALID_FEE_RULE_ID", "invalid fee rule ID")
+
+type PricingHandler struct {
+	service PricingService
+}
+
+func NewPricingHandler(service PricingService) *PricingHandler {
+	return &PricingHandler{service}
+}
+
+func (h *PricingHandler) CreateFeeRule(c *fiber.Ctx) error {
+	var req CreateFeeRuleRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	rule, err := h.service.CreateFeeRule(req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "fee rule created successfully",
+		"data":    rule,
+	})
+}
+
+func (h *PricingHandler) GetAllFeeRules(c *fiber.Ctx) error {
+	rules, err := h.service.GetAllFeeRules()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": rules,
+	})
+}
+
+func (h *PricingHandler) UpdateFeeRule(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidFeeRuleID
+	}
+
+	var req CreateFeeRuleRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	rule, err := h.service.UpdateFeeRule(uint(id), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "fee rule updated successfully",
+		"data":    rule,
+	})
+}
+
+func (h *PricingHandler) DeleteFeeRule(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidFeeRuleID
+	}
+
+	if err := h.service.DeleteFeeRule(uint(id)); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "fee rule deleted successfully",
+	})
+}
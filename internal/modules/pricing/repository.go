@@ -0,0 +1,59 @@
+package pricing
+
+import (
+	"ezytix-be/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PricingRepository interface {
+	CreateFeeRule(rule *models.FeeRule) error
+	GetAllFeeRules() ([]models.FeeRule, error)
+	GetFeeRuleByID(id uint) (*models.FeeRule, error)
+	GetActiveFeeRules(feeType string) ([]models.FeeRule, error)
+	UpdateFeeRule(rule *models.FeeRule) error
+	DeleteFeeRule(id uint) error
+}
+
+type pricingRepository struct {
+	db *gorm.DB
+}
+
+func NewPricingRepository(db *gorm.DB) PricingRepository {
+	return &pricingRepository{db}
+}
+
+func (r *pricingRepository) CreateFeeRule(rule *models.FeeRule) error {
+	return r.db.Create(rule).Error
+}
+
+func (r *pricingRepository) GetAllFeeRules() ([]models.FeeRule, error) {
+	var rules []models.FeeRule
+	err := r.db.Order("fee_type ASC, id ASC").Find(&rules).Error
+	return rules, err
+}
+
+func (r *pricingRepository) GetFeeRuleByID(id uint) (*models.FeeRule, error) {
+	var rule models.FeeRule
+	if err := r.db.First(&rule, id).Error; err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r *pricingRepository) GetActiveFeeRules(feeType string) ([]models.FeeRule, error) {
+	var rules []models.FeeRule
+	err := r.db.
+		Where("fee_type = ? AND is_active = ?", feeType, true).
+		Order("id ASC").
+		Find(&rules).Error
+	return rules, err
+}
+
+func (r *pricingRepository) UpdateFeeRule(rule *models.FeeRule) error {
+	return r.db.Save(rule).Error
+}
+
+func (r *pricingRepository) DeleteFeeRule(id uint) error {
+	return r.db.Delete(&models.FeeRule{}, id).Error
+}
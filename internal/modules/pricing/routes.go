@@ -0,0 +1,22 @@
+package pricing
+
+import (
+	"ezytix-be/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func PricingRegisterRoutes(app *fiber.App, db *gorm.DB) {
+	repo := NewPricingRepository(db)
+	service := NewPricingService(repo)
+	handler := NewPricingHandler(service)
+
+	admin := app.Group("/api/v1/admin/fee-rules")
+	admin.Use(middleware.JWTMiddleware)
+	admin.Use(middleware.RequireRole("admin"))
+	admin.Get("/", handler.GetAllFeeRules)
+	admin.Post("/", handler.CreateFeeRule)
+	admin.Put("/:id", handler.UpdateFeeRule)
+	admin.Delete("/:id", handler.DeleteFeeRule)
+}
@@ -0,0 +1,199 @@
+package pricing
+
+import (
+	"strings"
+
+	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrFeeRuleNotFound = apperror.NotFound("FEE_RULE_NOT_FOUND", "fee rule not found")
+	ErrInvalidFeeRule  = apperror.Validation("INVALID_FEE_RULE", "invalid fee rule")
+)
+
+type PricingService interface {
+	CreateFeeRule(req CreateFeeRuleRequest) (*models.FeeRule, error)
+	GetAllFeeRules() ([]models.FeeRule, error)
+	UpdateFeeRule(id uint, req CreateFeeRuleRequest) (*models.FeeRule, error)
+	DeleteFeeRule(id uint) error
+
+	QuotePassenger(flight models.Flight, passengerType string, baseFare decimal.Decimal) (*PassengerCharges, error)
+	ConvenienceFee(paymentType string, amount decimal.Decimal) (decimal.Decimal, error)
+}
+
+type pricingService struct {
+	repo PricingRepository
+}
+
+func NewPricingService(repo PricingRepository) PricingService {
+	return &pricingService{repo}
+}
+
+func (s *pricingService) CreateFeeRule(req CreateFeeRuleRequest) (*models.FeeRule, error) {
+	rule := &models.FeeRule{IsActive: true}
+	if err := applyFeeRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateFeeRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *pricingService) GetAllFeeRules() ([]models.FeeRule, error) {
+	return s.repo.GetAllFeeRules()
+}
+
+func (s *pricingService) UpdateFeeRule(id uint, req CreateFeeRuleRequest) (*models.FeeRule, error) {
+	rule, err := s.repo.GetFeeRuleByID(id)
+	if err != nil {
+		return nil, ErrFeeRuleNotFound
+	}
+
+	if err := applyFeeRuleRequest(rule, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateFeeRule(rule); err != nil {
+		return nil, err
+	}
+	return rule, nil
+}
+
+func (s *pricingService) DeleteFeeRule(id uint) error {
+	if _, err := s.repo.GetFeeRuleByID(id); err != nil {
+		return ErrFeeRuleNotFound
+	}
+	return s.repo.DeleteFeeRule(id)
+}
+
+// QuotePassenger menghitung pajak bandara (per leg, sesuai bandara keberangkatan)
+// dan PPN untuk satu penumpang. Nominal dibulatkan ke rupiah penuh.
+func (s *pricingService) QuotePassenger(flight models.Flight, passengerType string, baseFare decimal.Decimal) (*PassengerCharges, error) {
+	airportTaxRules, err := s.repo.GetActiveFeeRules(models.FeeTypeAirportTax)
+	if err != nil {
+		return nil, err
+	}
+	vatRules, err := s.repo.GetActiveFeeRules(models.FeeTypeVAT)
+	if err != nil {
+		return nil, err
+	}
+
+	charges := &PassengerCharges{
+		BaseFare:   baseFare,
+		AirportTax: decimal.Zero,
+		VAT:        decimal.Zero,
+	}
+
+	departureAirports := []uint{flight.OriginAirportID}
+	if len(flight.FlightLegs) > 0 {
+		departureAirports = nil
+		for _, leg := range flight.FlightLegs {
+			departureAirports = append(departureAirports, leg.OriginAirportID)
+		}
+	}
+
+	for _, airportID := range departureAirports {
+		for _, rule := range airportTaxRules {
+			if rule.AirportID != nil && *rule.AirportID != airportID {
+				continue
+			}
+			if !matchesPassengerType(rule, passengerType) {
+				continue
+			}
+			charges.AirportTax = charges.AirportTax.Add(applyRule(rule, baseFare))
+		}
+	}
+
+	for _, rule := range vatRules {
+		if !matchesPassengerType(rule, passengerType) {
+			continue
+		}
+		charges.VAT = charges.VAT.Add(applyRule(rule, baseFare))
+	}
+
+	charges.Total = charges.BaseFare.Add(charges.AirportTax).Add(charges.VAT)
+	return charges, nil
+}
+
+func (s *pricingService) ConvenienceFee(paymentType string, amount decimal.Decimal) (decimal.Decimal, error) {
+	rules, err := s.repo.GetActiveFeeRules(models.FeeTypeConvenienceFee)
+	if err != nil {
+		return decimal.Zero, err
+	}
+
+	fee := decimal.Zero
+	for _, rule := range rules {
+		if rule.PaymentType != "" && !strings.EqualFold(rule.PaymentType, paymentType) {
+			continue
+		}
+		fee = fee.Add(applyRule(rule, amount))
+	}
+	return fee, nil
+}
+
+func applyRule(rule models.FeeRule, base decimal.Decimal) decimal.Decimal {
+	if rule.CalcType == models.FeeCalcPercentage {
+		return base.Mul(rule.Value).Div(decimal.NewFromInt(100)).Round(0)
+	}
+	return rule.Value
+}
+
+func matchesPassengerType(rule models.FeeRule, passengerType string) bool {
+	return rule.PassengerType == "" || strings.EqualFold(rule.PassengerType, passengerType)
+}
+
+func applyFeeRuleRequest(rule *models.FeeRule, req CreateFeeRuleRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return ErrInvalidFeeRule.Withf("name is required")
+	}
+
+	switch req.FeeType {
+	case models.FeeTypeAirportTax, models.FeeTypeVAT, models.FeeTypeConvenienceFee:
+	default:
+		return ErrInvalidFeeRule.Withf("fee type must be airport_tax, vat or convenience_fee")
+	}
+
+	switch req.CalcType {
+	case models.FeeCalcPercentage:
+		if req.Value.GreaterThan(decimal.NewFromInt(100)) {
+			return ErrInvalidFeeRule.Withf("percentage cannot exceed 100")
+		}
+	case models.FeeCalcFixed:
+	default:
+		return ErrInvalidFeeRule.Withf("calc type must be percentage or fixed")
+	}
+
+	if req.Value.IsNegative() {
+		return ErrInvalidFeeRule.Withf("value cannot be negative")
+	}
+	if req.AirportID != nil && req.FeeType != models.FeeTypeAirportTax {
+		return ErrInvalidFeeRule.Withf("airport_id only applies to airport_tax rules")
+	}
+	if req.PaymentType != "" && req.FeeType != models.FeeTypeConvenienceFee {
+		return ErrInvalidFeeRule.Withf("payment_type only applies to convenience_fee rules")
+	}
+
+	switch req.PassengerType {
+	case "", models.PassengerTypeAdult, models.PassengerTypeChild, models.PassengerTypeInfant:
+	default:
+		return ErrInvalidFeeRule.Withf("passenger_type must be Dewasa, Anak-anak or Bayi")
+	}
+
+	rule.Name = req.Name
+	rule.FeeType = req.FeeType
+	rule.CalcType = req.CalcType
+	rule.Value = req.Value
+	rule.AirportID = req.AirportID
+	rule.PaymentType = req.PaymentType
+	rule.PassengerType = req.PassengerType
+	if req.IsActive != nil {
+		rule.IsActive = *req.IsActive
+	}
+
+	return nil
+}
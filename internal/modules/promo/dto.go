@@ -0,0 +1,58 @@
+package promo
+
+import (
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+type CreatePromotionRequest struct {
+	Code          string           `json:"code" validate:"required,min=3,max=30"`
+	Description   string           `json:"description"`
+	DiscountType  string           `json:"discount_type" validate:"required,oneof=percentage fixed"`
+	DiscountValue decimal.Decimal  `json:"discount_value" validate:"required"`
+	MaxDiscount   *decimal.Decimal `json:"max_discount"`
+	MinSpend      decimal.Decimal  `json:"min_spend"`
+
+	OriginAirportID      *uint  `json:"origin_airport_id"`
+	DestinationAirportID *uint  `json:"destination_airport_id"`
+	AirlineID            *uint  `json:"airline_id"`
+	SeatClass            string `json:"seat_class" validate:"omitempty,oneof=economy business first_class"`
+
+	ValidFrom    time.Time `json:"valid_from" validate:"required"`
+	ValidUntil   time.Time `json:"valid_until" validate:"required"`
+	UsageLimit   int       `json:"usage_limit" validate:"min=0"`
+	PerUserLimit int       `json:"per_user_limit" validate:"min=0"`
+	IsActive     *bool     `json:"is_active"`
+}
+
+type ValidatePromoItemRequest struct {
+	FlightID  uint            `json:"flight_id" validate:"required"`
+	SeatClass string          `json:"seat_class" validate:"required,oneof=economy business first_class"`
+	Amount    decimal.Decimal `json:"amount" validate:"required"`
+}
+
+type ValidatePromoRequest struct {
+	Code  string                     `json:"code" validate:"required"`
+	Items []ValidatePromoItemRequest `json:"items" validate:"required,min=1,dive"`
+}
+
+// PromoItem adalah satu penerbangan dalam order yang akan dinilai oleh promo.
+type PromoItem struct {
+	FlightID             uint
+	AirlineID            uint
+	OriginAirportID      uint
+	DestinationAirportID uint
+	SeatClass            string
+	Amount               decimal.Decimal
+}
+
+type PromoQuote struct {
+	PromotionID    uint            `json:"-"`
+	Code           string          `json:"code"`
+	Description    string          `json:"description"`
+	EligibleAmount decimal.Decimal `json:"eligible_amount"`
+	DiscountAmount decimal.Decimal `json:"discount_amount"`
+	// Diskon per item, urutannya sama dengan items yang dinilai
+	ItemDiscounts []decimal.Decimal `json:"item_discounts"`
+}
@@ -0,0 +1,109 @@
+package promo
+
+import (
+	"strconv"
+
+	"ezytix-be/internal/middleware"
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/jwt"
+	"ezytix-be/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidPromotionID = apperror.BadRequest("INVALID_PROMOTION_ID", "invalid promotion ID")
+
+type PromoHandler struct {
+	service PromoService
+}
+
+func NewPromoHandler(service PromoService) *PromoHandler {
+	return &PromoHandler{service}
+}
+
+func (h *PromoHandler) CreatePromotion(c *fiber.Ctx) error {
+	var req CreatePromotionRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	promotion, err := h.service.CreatePromotion(req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "promotion created successfully",
+		"data":    promotion,
+	})
+}
+
+func (h *PromoHandler) GetAllPromotions(c *fiber.Ctx) error {
+	promotions, err := h.service.GetAllPromotions()
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": promotions,
+	})
+}
+
+func (h *PromoHandler) UpdatePromotion(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidPromotionID
+	}
+
+	var req CreatePromotionRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	promotion, err := h.service.UpdatePromotion(uint(id), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "promotion updated successfully",
+		"data":    promotion,
+	})
+}
+
+func (h *PromoHandler) DeletePromotion(c *fiber.Ctx) error {
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidPromotionID
+	}
+
+	if err := h.service.DeletePromotion(uint(id)); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "promotion deleted successfully",
+	})
+}
+
+func (h *PromoHandler) ValidatePromo(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	var req ValidatePromoRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	quote, err := h.service.ValidatePromo(userClaims.UserID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"valid": true,
+		"data":  quote,
+	})
+}
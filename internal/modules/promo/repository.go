@@ -0,0 +1,67 @@
+package promo
+
+import (
+	"ezytix-be/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type PromoRepository interface {
+	CreatePromotion(promotion *models.Promotion) error
+	GetAllPromotions() ([]models.Promotion, error)
+	GetPromotionByID(id uint) (*models.Promotion, error)
+	FindPromotionByCode(code string) (*models.Promotion, error)
+	UpdatePromotion(promotion *models.Promotion) error
+	DeletePromotion(id uint) error
+	CountActiveRedemptions(promotionID uint, userID uint) (int64, error)
+}
+
+type promoRepository struct {
+	db *gorm.DB
+}
+
+func NewPromoRepository(db *gorm.DB) PromoRepository {
+	return &promoRepository{db}
+}
+
+func (r *promoRepository) CreatePromotion(promotion *models.Promotion) error {
+	return r.db.Create(promotion).Error
+}
+
+func (r *promoRepository) GetAllPromotions() ([]models.Promotion, error) {
+	var promotions []models.Promotion
+	err := r.db.Order("created_at DESC").Find(&promotions).Error
+	return promotions, err
+}
+
+func (r *promoRepository) GetPromotionByID(id uint) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := r.db.First(&promotion, id).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promoRepository) FindPromotionByCode(code string) (*models.Promotion, error) {
+	var promotion models.Promotion
+	if err := r.db.Where("code = ?", code).First(&promotion).Error; err != nil {
+		return nil, err
+	}
+	return &promotion, nil
+}
+
+func (r *promoRepository) UpdatePromotion(promotion *models.Promotion) error {
+	return r.db.Save(promotion).Error
+}
+
+func (r *promoRepository) DeletePromotion(id uint) error {
+	return r.db.Delete(&models.Promotion{}, id).Error
+}
+
+func (r *promoRepository) CountActiveRedemptions(promotionID uint, userID uint) (int64, error) {
+	var count int64
+	err := r.db.Model(&models.PromoRedemption{}).
+		Where("promotion_id = ? AND user_id = ? AND status = ?", promotionID, userID, models.PromoRedemptionStatusActive).
+		Count(&count).Error
+	return count, err
+}
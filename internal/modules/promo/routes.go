@@ -0,0 +1,30 @@
+package promo
+
+import (
+	"ezytix-be/internal/middleware"
+	"ezytix-be/internal/modules/flight"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func PromoRegisterRoutes(app *fiber.App, db *gorm.DB) {
+	repo := NewPromoRepository(db)
+	flightService := flight.NewFlightService(flight.NewFlightRepository(db))
+	service := NewPromoService(repo, flightService)
+	handler := NewPromoHandler(service)
+
+	api := app.Group("/api/v1")
+
+	promotions := api.Group("/promotions")
+	promotions.Use(middleware.JWTMiddleware)
+	promotions.Post("/validate", handler.ValidatePromo)
+
+	admin := api.Group("/admin/promotions")
+	admin.Use(middleware.JWTMiddleware)
+	admin.Use(middleware.RequireRole("admin"))
+	admin.Get("/", handler.GetAllPromotions)
+	admin.Post("/", handler.CreatePromotion)
+	admin.Put("/:id", handler.UpdatePromotion)
+	admin.Delete("/:id", handler.DeletePromotion)
+}
@@ -0,0 +1,290 @@
+package promo
+
+import (
+	"strings"
+	"time"
+
+	"ezytix-be/internal/models"
+	"ezytix-be/internal/modules/flight"
+	"ezytix-be/pkg/apperror"
+
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrPromotionNotFound       = apperror.NotFound("PROMOTION_NOT_FOUND", "promotion not found")
+	ErrPromoCodeExists         = apperror.Conflict("PROMO_CODE_EXISTS", "promo code already exists")
+	ErrPromotionInUse          = apperror.Conflict("PROMOTION_IN_USE", "promotion has been used, deactivate it instead")
+	ErrInvalidPromotion        = apperror.Validation("INVALID_PROMOTION", "invalid promotion")
+	ErrInvalidPromoRequest     = apperror.Validation("INVALID_PROMO_REQUEST", "invalid promo request")
+	ErrPromoNotFound           = apperror.NotFound("PROMO_NOT_FOUND", "promo code not found")
+	ErrPromoInactive           = apperror.Validation("PROMO_INACTIVE", "promo code is not active")
+	ErrPromoExpired            = apperror.Expired("PROMO_EXPIRED", "promo code has expired")
+	ErrPromoUsageLimit         = apperror.Conflict("PROMO_USAGE_LIMIT_REACHED", "promo code usage limit has been reached")
+	ErrPromoUserLimit          = apperror.Conflict("PROMO_USER_LIMIT_REACHED", "you have reached the usage limit for this promo code")
+	ErrPromoNotApplicable      = apperror.Validation("PROMO_NOT_APPLICABLE", "promo code is not applicable to the selected flights")
+	ErrPromoMinSpendNotReached = apperror.Validation("PROMO_MIN_SPEND_NOT_REACHED", "minimum spend for this promo code has not been reached")
+)
+
+type PromoService interface {
+	CreatePromotion(req CreatePromotionRequest) (*models.Promotion, error)
+	GetAllPromotions() ([]models.Promotion, error)
+	UpdatePromotion(id uint, req CreatePromotionRequest) (*models.Promotion, error)
+	DeletePromotion(id uint) error
+	ValidatePromo(userID uint, req ValidatePromoRequest) (*PromoQuote, error)
+	Quote(userID uint, code string, items []PromoItem) (*PromoQuote, error)
+}
+
+type promoService struct {
+	repo          PromoRepository
+	flightService flight.FlightService
+}
+
+func NewPromoService(repo PromoRepository, flightService flight.FlightService) PromoService {
+	return &promoService{
+		repo:          repo,
+		flightService: flightService,
+	}
+}
+
+func (s *promoService) CreatePromotion(req CreatePromotionRequest) (*models.Promotion, error) {
+	promotion := &models.Promotion{IsActive: true}
+	if err := applyPromotionRequest(promotion, req); err != nil {
+		return nil, err
+	}
+
+	if _, err := s.repo.FindPromotionByCode(promotion.Code); err == nil {
+		return nil, ErrPromoCodeExists
+	}
+
+	if err := s.repo.CreatePromotion(promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func (s *promoService) GetAllPromotions() ([]models.Promotion, error) {
+	return s.repo.GetAllPromotions()
+}
+
+func (s *promoService) UpdatePromotion(id uint, req CreatePromotionRequest) (*models.Promotion, error) {
+	promotion, err := s.repo.GetPromotionByID(id)
+	if err != nil {
+		return nil, ErrPromotionNotFound
+	}
+
+	oldCode := promotion.Code
+	if err := applyPromotionRequest(promotion, req); err != nil {
+		return nil, err
+	}
+
+	if promotion.Code != oldCode {
+		if promotion.UsedCount > 0 {
+			return nil, ErrPromotionInUse.Withf("code of a promotion that has been used cannot be changed")
+		}
+		if _, err := s.repo.FindPromotionByCode(promotion.Code); err == nil {
+			return nil, ErrPromoCodeExists
+		}
+	}
+
+	if err := s.repo.UpdatePromotion(promotion); err != nil {
+		return nil, err
+	}
+	return promotion, nil
+}
+
+func (s *promoService) DeletePromotion(id uint) error {
+	promotion, err := s.repo.GetPromotionByID(id)
+	if err != nil {
+		return ErrPromotionNotFound
+	}
+	if promotion.UsedCount > 0 {
+		return ErrPromotionInUse
+	}
+	return s.repo.DeletePromotion(id)
+}
+
+func (s *promoService) ValidatePromo(userID uint, req ValidatePromoRequest) (*PromoQuote, error) {
+	if len(req.Items) == 0 {
+		return nil, ErrInvalidPromoRequest.Withf("items cannot be empty")
+	}
+
+	var items []PromoItem
+	for _, itemReq := range req.Items {
+		flightData, err := s.flightService.GetFlightByID(itemReq.FlightID)
+		if err != nil {
+			return nil, flight.ErrFlightNotFound
+		}
+
+		items = append(items, PromoItem{
+			FlightID:             flightData.ID,
+			AirlineID:            flightData.AirlineID,
+			OriginAirportID:      flightData.OriginAirportID,
+			DestinationAirportID: flightData.DestinationAirportID,
+			SeatClass:            itemReq.SeatClass,
+			Amount:               itemReq.Amount,
+		})
+	}
+
+	return s.Quote(userID, req.Code, items)
+}
+
+// Quote menghitung diskon tanpa menyimpan apa pun. Kuota tetap dicek ulang
+// secara atomik saat order dibuat.
+func (s *promoService) Quote(userID uint, code string, items []PromoItem) (*PromoQuote, error) {
+	code = normalizeCode(code)
+	promotion, err := s.repo.FindPromotionByCode(code)
+	if err != nil {
+		return nil, ErrPromoNotFound
+	}
+
+	now := time.Now()
+	if !promotion.IsActive || now.Before(promotion.ValidFrom) {
+		return nil, ErrPromoInactive
+	}
+	if now.After(promotion.ValidUntil) {
+		return nil, ErrPromoExpired
+	}
+	if promotion.UsageLimit > 0 && promotion.UsedCount >= promotion.UsageLimit {
+		return nil, ErrPromoUsageLimit
+	}
+
+	if promotion.PerUserLimit > 0 {
+		used, err := s.repo.CountActiveRedemptions(promotion.ID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if used >= int64(promotion.PerUserLimit) {
+			return nil, ErrPromoUserLimit
+		}
+	}
+
+	eligible := make([]bool, len(items))
+	eligibleAmount := decimal.Zero
+	for i, item := range items {
+		if isItemEligible(*promotion, item) {
+			eligible[i] = true
+			eligibleAmount = eligibleAmount.Add(item.Amount)
+		}
+	}
+
+	if eligibleAmount.IsZero() {
+		return nil, ErrPromoNotApplicable
+	}
+	if eligibleAmount.LessThan(promotion.MinSpend) {
+		return nil, ErrPromoMinSpendNotReached.Withf("minimum spend for this promo code is %s", promotion.MinSpend.StringFixed(0))
+	}
+
+	discount := promotion.DiscountValue
+	if promotion.DiscountType == models.PromoDiscountPercentage {
+		discount = eligibleAmount.Mul(promotion.DiscountValue).Div(decimal.NewFromInt(100)).Round(2)
+		if promotion.MaxDiscount != nil && discount.GreaterThan(*promotion.MaxDiscount) {
+			discount = *promotion.MaxDiscount
+		}
+	}
+	if discount.GreaterThan(eligibleAmount) {
+		discount = eligibleAmount
+	}
+
+	return &PromoQuote{
+		PromotionID:    promotion.ID,
+		Code:           promotion.Code,
+		Description:    promotion.Description,
+		EligibleAmount: eligibleAmount,
+		DiscountAmount: discount,
+		ItemDiscounts:  distributeDiscount(items, eligible, eligibleAmount, discount),
+	}, nil
+}
+
+func isItemEligible(promotion models.Promotion, item PromoItem) bool {
+	if promotion.OriginAirportID != nil && *promotion.OriginAirportID != item.OriginAirportID {
+		return false
+	}
+	if promotion.DestinationAirportID != nil && *promotion.DestinationAirportID != item.DestinationAirportID {
+		return false
+	}
+	if promotion.AirlineID != nil && *promotion.AirlineID != item.AirlineID {
+		return false
+	}
+	if promotion.SeatClass != "" && !strings.EqualFold(promotion.SeatClass, item.SeatClass) {
+		return false
+	}
+	return true
+}
+
+// distributeDiscount membagi diskon secara proporsional ke item yang eligible.
+// Sisa pembulatan dibebankan ke item eligible terakhir.
+func distributeDiscount(items []PromoItem, eligible []bool, eligibleAmount decimal.Decimal, discount decimal.Decimal) []decimal.Decimal {
+	result := make([]decimal.Decimal, len(items))
+	lastEligible := -1
+	allocated := decimal.Zero
+
+	for i, item := range items {
+		result[i] = decimal.Zero
+		if !eligible[i] {
+			continue
+		}
+		lastEligible = i
+		result[i] = discount.Mul(item.Amount).Div(eligibleAmount).Round(2)
+		allocated = allocated.Add(result[i])
+	}
+
+	if lastEligible >= 0 {
+		result[lastEligible] = result[lastEligible].Add(discount.Sub(allocated))
+	}
+	return result
+}
+
+func applyPromotionRequest(promotion *models.Promotion, req CreatePromotionRequest) error {
+	code := normalizeCode(req.Code)
+	if len(code) < 3 {
+		return ErrInvalidPromotion.Withf("promo code must be at least 3 characters")
+	}
+	if !req.ValidUntil.After(req.ValidFrom) {
+		return ErrInvalidPromotion.Withf("valid_until must be after valid_from")
+	}
+	if !req.DiscountValue.IsPositive() {
+		return ErrInvalidPromotion.Withf("discount value must be greater than zero")
+	}
+
+	switch req.DiscountType {
+	case models.PromoDiscountPercentage:
+		if req.DiscountValue.GreaterThan(decimal.NewFromInt(100)) {
+			return ErrInvalidPromotion.Withf("percentage discount cannot exceed 100")
+		}
+	case models.PromoDiscountFixed:
+	default:
+		return ErrInvalidPromotion.Withf("discount type must be percentage or fixed")
+	}
+
+	if req.MinSpend.IsNegative() {
+		return ErrInvalidPromotion.Withf("min spend cannot be negative")
+	}
+	if req.UsageLimit < 0 || req.PerUserLimit < 0 {
+		return ErrInvalidPromotion.Withf("usage limits cannot be negative")
+	}
+
+	promotion.Code = code
+	promotion.Description = req.Description
+	promotion.DiscountType = req.DiscountType
+	promotion.DiscountValue = req.DiscountValue
+	promotion.MaxDiscount = req.MaxDiscount
+	promotion.MinSpend = req.MinSpend
+	promotion.OriginAirportID = req.OriginAirportID
+	promotion.DestinationAirportID = req.DestinationAirportID
+	promotion.AirlineID = req.AirlineID
+	promotion.SeatClass = strings.ToLower(req.SeatClass)
+	promotion.ValidFrom = req.ValidFrom
+	promotion.ValidUntil = req.ValidUntil
+	promotion.UsageLimit = req.UsageLimit
+	promotion.PerUserLimit = req.PerUserLimit
+	if req.IsActive != nil {
+		promotion.IsActive = *req.IsActive
+	}
+
+	return nil
+}
+
+func normalizeCode(code string) string {
+	return strings.ToUpper(strings.TrimSpace(code))
+}
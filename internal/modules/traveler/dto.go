@@ -0,0 +1,11 @@
+package traveler
+
+type SaveTravelerRequest struct {
+	Title          string `json:"title" validate:"required,oneof=tuan nyonya nona mr ms mrs"`
+	FullName       string `json:"full_name" validate:"required,min=2"`
+	DOB            string `json:"dob" validate:"required,datetime=2006-01-02"`
+	Nationality    string `json:"nationality" validate:"required"`
+	PassportNumber string `json:"passport_number"`
+	IssuingCountry string `json:"issuing_country"`
+	ValidUntil     string `json:"valid_until" validate:"omitempty,datetime=2006-01-02"`
+}
@@ -0,0 +1,128 @@
+package traveler
+
+import (
+	"strconv"
+
+	"ezytix-be/internal/middleware"
+	"ezytix-be/pkg/apperror"
+	"ezytix-be/pkg/jwt"
+	"ezytix-be/pkg/validation"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidTravelerID = apperror.BadRequest("INVALID_TRAVELER_ID", "invalid traveler ID")
+
+type TravelerHandler struct {
+	service TravelerService
+}
+
+func NewTravelerHandler(service TravelerService) *TravelerHandler {
+	return &TravelerHandler{service}
+}
+
+func (h *TravelerHandler) GetTravelers(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	travelers, err := h.service.GetTravelers(userClaims.UserID)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": travelers,
+	})
+}
+
+func (h *TravelerHandler) GetTraveler(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidTravelerID
+	}
+
+	traveler, err := h.service.GetTraveler(userClaims.UserID, uint(id))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"data": traveler,
+	})
+}
+
+func (h *TravelerHandler) CreateTraveler(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	var req SaveTravelerRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	traveler, err := h.service.CreateTraveler(userClaims.UserID, req)
+	if err != nil {
+		return err
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(fiber.Map{
+		"message": "traveler saved successfully",
+		"data":    traveler,
+	})
+}
+
+func (h *TravelerHandler) UpdateTraveler(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidTravelerID
+	}
+
+	var req SaveTravelerRequest
+	if err := validation.ParseBody(c, &req); err != nil {
+		return err
+	}
+
+	traveler, err := h.service.UpdateTraveler(userClaims.UserID, uint(id), req)
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "traveler updated successfully",
+		"data":    traveler,
+	})
+}
+
+func (h *TravelerHandler) DeleteTraveler(c *fiber.Ctx) error {
+	userClaims, ok := c.Locals("user").(*jwt.JWTClaims)
+	if !ok || userClaims == nil {
+		return middleware.ErrUnauthorized
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return ErrInvalidTravelerID
+	}
+
+	if err := h.service.DeleteTraveler(userClaims.UserID, uint(id)); err != nil {
+		return err
+	}
+
+	return c.JSON(fiber.Map{
+		"message": "traveler deleted successfully",
+	})
+}
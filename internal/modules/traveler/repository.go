@@ -0,0 +1,58 @@
+package traveler
+
+import (
+	"ezytix-be/internal/models"
+
+	"gorm.io/gorm"
+)
+
+type TravelerRepository interface {
+	CreateTraveler(traveler *models.SavedTraveler) error
+	GetTravelersByUserID(userID uint) ([]models.SavedTraveler, error)
+	GetTravelerByID(userID uint, id uint) (*models.SavedTraveler, error)
+	UpdateTraveler(traveler *models.SavedTraveler) error
+	DeleteTraveler(userID uint, id uint) error
+}
+
+type travelerRepository struct {
+	db *gorm.DB
+}
+
+func NewTravelerRepository(db *gorm.DB) TravelerRepository {
+	return &travelerRepository{db}
+}
+
+func (r *travelerRepository) CreateTraveler(traveler *models.SavedTraveler) error {
+	return r.db.Create(traveler).Error
+}
+
+func (r *travelerRepository) GetTravelersByUserID(userID uint) ([]models.SavedTraveler, error) {
+	var travelers []models.SavedTraveler
+	err := r.db.Where("user_id = ?", userID).Order("full_name ASC").Find(&travelers).Error
+	return travelers, err
+}
+
+// GetTravelerByID selalu memfilter user_id agar traveler milik user lain
+// tidak bisa diakses.
+func (r *travelerRepository) GetTravelerByID(userID uint, id uint) (*models.SavedTraveler, error) {
+	var traveler models.SavedTraveler
+	if err := r.db.Where("id = ? AND user_id = ?", id, userID).First(&traveler).Error; err != nil {
+		return nil, err
+	}
+	return &traveler, nil
+}
+
+func (r *travelerRepository) UpdateTraveler(traveler *models.SavedTraveler) error {
+	return r.db.Save(traveler).Error
+}
+
+func (r *travelerRepository) DeleteTraveler(userID uint, id uint) error {
+	result := r.db.Where("id = ? AND user_id = ?", id, userID).Delete(&models.SavedTraveler{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
@@ -0,0 +1,22 @@
+package traveler
+
+import (
+	"ezytix-be/internal/middleware"
+
+	"github.com/gofiber/fiber/v2"
+	"gorm.io/gorm"
+)
+
+func TravelerRegisterRoutes(app *fiber.App, db *gorm.DB) {
+	repo := NewTravelerRepository(db)
+	service := NewTravelerService(repo)
+	handler := NewTravelerHandler(service)
+
+	travelers := app.Group("/api/v1/travelers")
+	travelers.Use(middleware.JWTMiddleware)
+	travelers.Get("/", handler.GetTravelers)
+	travelers.Post("/", handler.CreateTraveler)
+	travelers.Get("/:id", handler.GetTraveler)
+	travelers.Put("/:id", handler.UpdateTraveler)
+	travelers.Delete("/:id", handler.DeleteTraveler)
+}
@@ -0,0 +1,172 @@
+package traveler
+
+import (
+	"strings"
+	"time"
+
+	"ezytix-be/internal/models"
+	"ezytix-be/pkg/apperror"
+)
+
+// Batas jumlah traveler tersimpan per user
+const maxTravelersPerUser = 20
+
+var (
+	ErrTravelerNotFound      = apperror.NotFound("TRAVELER_NOT_FOUND", "traveler not found")
+	ErrTravelerLimitReached  = apperror.Conflict("TRAVELER_LIMIT_REACHED", "saved traveler limit has been reached")
+	ErrInvalidTraveler       = apperror.Validation("INVALID_TRAVELER", "invalid traveler")
+	ErrPassportExpiryMissing = apperror.Validation("PASSPORT_EXPIRY_REQUIRED", "passport expiry date is required")
+	ErrPassportExpired       = apperror.Validation("PASSPORT_EXPIRED", "passport expires before the travel date")
+)
+
+type TravelerService interface {
+	CreateTraveler(userID uint, req SaveTravelerRequest) (*models.SavedTraveler, error)
+	GetTravelers(userID uint) ([]models.SavedTraveler, error)
+	GetTraveler(userID uint, id uint) (*models.SavedTraveler, error)
+	UpdateTraveler(userID uint, id uint, req SaveTravelerRequest) (*models.SavedTraveler, error)
+	DeleteTraveler(userID uint, id uint) error
+}
+
+type travelerService struct {
+	repo TravelerRepository
+}
+
+func NewTravelerService(repo TravelerRepository) TravelerService {
+	return &travelerService{repo}
+}
+
+func (s *travelerService) CreateTraveler(userID uint, req SaveTravelerRequest) (*models.SavedTraveler, error) {
+	travelers, err := s.repo.GetTravelersByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if len(travelers) >= maxTravelersPerUser {
+		return nil, ErrTravelerLimitReached.Withf("a maximum of %d travelers can be saved", maxTravelersPerUser)
+	}
+
+	traveler := &models.SavedTraveler{UserID: userID}
+	if err := applyTravelerRequest(traveler, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.CreateTraveler(traveler); err != nil {
+		return nil, err
+	}
+	return traveler, nil
+}
+
+func (s *travelerService) GetTravelers(userID uint) ([]models.SavedTraveler, error) {
+	travelers, err := s.repo.GetTravelersByUserID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if travelers == nil {
+		travelers = []models.SavedTraveler{}
+	}
+	return travelers, nil
+}
+
+func (s *travelerService) GetTraveler(userID uint, id uint) (*models.SavedTraveler, error) {
+	traveler, err := s.repo.GetTravelerByID(userID, id)
+	if err != nil {
+		return nil, ErrTravelerNotFound
+	}
+	return traveler, nil
+}
+
+func (s *travelerService) UpdateTraveler(userID uint, id uint, req SaveTravelerRequest) (*models.SavedTraveler, error) {
+	traveler, err := s.repo.GetTravelerByID(userID, id)
+	if err != nil {
+		return nil, ErrTravelerNotFound
+	}
+
+	if err := applyTravelerRequest(traveler, req); err != nil {
+		return nil, err
+	}
+
+	if err := s.repo.UpdateTraveler(traveler); err != nil {
+		return nil, err
+	}
+	return traveler, nil
+}
+
+func (s *travelerService) DeleteTraveler(userID uint, id uint) error {
+	if err := s.repo.DeleteTraveler(userID, id); err != nil {
+		return ErrTravelerNotFound
+	}
+	return nil
+}
+
+// ValidatePassport memastikan paspor masih berlaku sampai tanggal tertentu.
+// Traveler tanpa paspor (penerbangan domestik) selalu lolos.
+func ValidatePassport(passportNumber *string, validUntil *time.Time, travelDate time.Time) error {
+	if passportNumber == nil || *passportNumber == "" {
+		return nil
+	}
+	if validUntil == nil {
+		return ErrPassportExpiryMissing
+	}
+
+	travelDay := time.Date(travelDate.Year(), travelDate.Month(), travelDate.Day(), 0, 0, 0, 0, time.UTC)
+	if validUntil.Before(travelDay) {
+		return ErrPassportExpired.Withf("passport %s expires on %s", *passportNumber, validUntil.Format("2006-01-02"))
+	}
+	return nil
+}
+
+func applyTravelerRequest(traveler *models.SavedTraveler, req SaveTravelerRequest) error {
+	title := strings.ToLower(strings.TrimSpace(req.Title))
+	switch title {
+	case "tuan", "nyonya", "nona", "mr", "ms", "mrs":
+	default:
+		return ErrInvalidTraveler.Withf("title must be one of tuan, nyonya, nona, mr, ms, mrs")
+	}
+
+	fullName := strings.TrimSpace(req.FullName)
+	if len(fullName) < 2 {
+		return ErrInvalidTraveler.Withf("full name is required")
+	}
+
+	dob, err := time.Parse("2006-01-02", req.DOB)
+	if err != nil {
+		return ErrInvalidTraveler.Withf("dob must use format YYYY-MM-DD")
+	}
+	if dob.After(time.Now()) {
+		return ErrInvalidTraveler.Withf("dob cannot be in the future")
+	}
+
+	nationality := strings.TrimSpace(req.Nationality)
+	if nationality == "" {
+		return ErrInvalidTraveler.Withf("nationality is required")
+	}
+
+	var passportNumber, issuingCountry *string
+	var validUntil *time.Time
+	if number := strings.ToUpper(strings.TrimSpace(req.PassportNumber)); number != "" {
+		country := strings.TrimSpace(req.IssuingCountry)
+		if country == "" {
+			return ErrInvalidTraveler.Withf("issuing country is required when passport number is set")
+		}
+
+		expiry, err := time.Parse("2006-01-02", req.ValidUntil)
+		if err != nil {
+			return ErrInvalidTraveler.Withf("valid_until must use format YYYY-MM-DD when passport number is set")
+		}
+		if err := ValidatePassport(&number, &expiry, time.Now()); err != nil {
+			return err
+		}
+
+		passportNumber = &number
+		issuingCountry = &country
+		validUntil = &expiry
+	}
+
+	traveler.Title = title
+	traveler.FullName = fullName
+	traveler.DOB = dob
+	traveler.Nationality = nationality
+	traveler.PassportNumber = passportNumber
+	traveler.IssuingCountry = issuingCountry
+	traveler.ValidUntil = validUntil
+	return nil
+}
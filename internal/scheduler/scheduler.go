@@ -10,6 +10,11 @@ type ExpiredBookingProcessor interface {
 	ProcessExpiredBookings() error
 }
 
+type PaymentReconciler interface {
+	ReconcilePendingPayments() error
+	ReportDailyMismatches() error
+}
+
 func StartCronJob(processor ExpiredBookingProcessor) {
 	c := cron.New(cron.WithChain(
 		cron.Recover(cron.DefaultLogger), 
@@ -26,4 +31,31 @@ func StartCronJob(processor ExpiredBookingProcessor) {
 
 	c.Start()
 	log.Println("✅ [SCHEDULER] Cron Job started: Strict Expiry Check active (Every 1 min)")
+}
+
+func StartReconcileJob(reconciler PaymentReconciler) {
+	c := cron.New(cron.WithChain(
+		cron.Recover(cron.DefaultLogger),
+	))
+
+	_, err := c.AddFunc("@every 1m", func() {
+		if err := reconciler.ReconcilePendingPayments(); err != nil {
+			log.Printf("❌ [SCHEDULER ERROR] Failed to reconcile pending payments: %v\n", err)
+		}
+	})
+	if err != nil {
+		log.Fatal("❌ [SCHEDULER] Failed to initialize Reconcile Job:", err)
+	}
+
+	_, err = c.AddFunc("@daily", func() {
+		if err := reconciler.ReportDailyMismatches(); err != nil {
+			log.Printf("❌ [SCHEDULER ERROR] Failed to build daily mismatch report: %v\n", err)
+		}
+	})
+	if err != nil {
+		log.Fatal("❌ [SCHEDULER] Failed to initialize Mismatch Report Job:", err)
+	}
+
+	c.Start()
+	log.Println("✅ [SCHEDULER] Reconcile Job started: Pending payment check (Every 1 min), Mismatch report (Daily)")
 }
\ No newline at end of file
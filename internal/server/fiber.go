@@ -2,6 +2,7 @@ package server
 
 import (
 	"ezytix-be/internal/database"
+	"ezytix-be/internal/middleware"
 
 	"github.com/gofiber/fiber/v2"
 	"github.com/gofiber/fiber/v2/middleware/cors"
@@ -14,7 +15,8 @@ type FiberServer struct {
 
 func New() *FiberServer {
 	app := fiber.New(fiber.Config{
-		AppName: "ezytix-backend",
+		AppName:      "ezytix-backend",
+		ErrorHandler: middleware.ErrorHandler,
 	})
 	
 	app.Use(cors.New(cors.Config{
@@ -22,7 +24,7 @@ func New() *FiberServer {
 		AllowMethods:     "GET,POST,PUT,PATCH,DELETE,OPTIONS",
 		AllowHeaders:     "Origin, Content-Type, Accept, Authorization",
 		AllowCredentials: true,
-		ExposeHeaders:    "Set-Cookie",
+		ExposeHeaders:    "Set-Cookie, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining, X-RateLimit-Reset",
 	}))
 
 	return &FiberServer{
@@ -6,12 +6,16 @@ import (
 
 	// --- Import Module ---
 	"ezytix-be/internal/modules/admin"
+	"ezytix-be/internal/modules/apikey"
 	"ezytix-be/internal/modules/airline" // <--- 1. IMPORT INI
 	"ezytix-be/internal/modules/airport"
 	"ezytix-be/internal/modules/auth"
 	"ezytix-be/internal/modules/booking"
 	"ezytix-be/internal/modules/flight"
 	"ezytix-be/internal/modules/payment"
+	"ezytix-be/internal/modules/pricing"
+	"ezytix-be/internal/modules/promo"
+	"ezytix-be/internal/modules/traveler"
 
 	"github.com/gofiber/contrib/websocket"
 )
@@ -22,11 +26,15 @@ func (s *FiberServer) RegisterRoutes() {
 	s.Get("/health", handlers.Health)
 	s.Get("/ws", websocket.New(handlers.Websocket))
 	auth.AuthRegisterRoutes(s.App, s.DB.GetGORMDB())
+	apikey.APIKeyRegisterRoutes(s.App, s.DB.GetGORMDB())
 	airport.AirportRegisterRoutes(s.App, s.DB.GetGORMDB())
 	airline.AirlineRegisterRoutes(s.App, s.DB.GetGORMDB())
 	flight.FlightRegisterRoutes(s.App, s.DB.GetGORMDB())
-	payment.PaymentRegisterRoutes(s.App, s.DB.GetGORMDB())
-	booking.BookingRegisterRoutes(s.App, s.DB.GetGORMDB())
+	promo.PromoRegisterRoutes(s.App, s.DB.GetGORMDB())
+	traveler.TravelerRegisterRoutes(s.App, s.DB.GetGORMDB())
+	pricing.PricingRegisterRoutes(s.App, s.DB.GetGORMDB())
+	paymentService := payment.PaymentRegisterRoutes(s.App, s.DB.GetGORMDB())
+	booking.BookingRegisterRoutes(s.App, s.DB.GetGORMDB(), paymentService)
 	admin.AdminRegisterRoutes(s.App, s.DB.GetGORMDB())
 
 	// admin := s.App.Group("/api/v1/admin")
@@ -0,0 +1,114 @@
+package utils
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// BCBPData berisi field wajib (mandatory items) IATA Bar Coded Boarding Pass
+// untuk satu leg.
+type BCBPData struct {
+	PassengerName  string
+	BookingCode    string
+	FromAirport    string
+	ToAirport      string
+	CarrierCode    string
+	FlightNumber   string
+	FlightDate     time.Time
+	SeatClass      string
+	SeatNumber     string
+	SequenceNumber int
+	IsInfant       bool
+}
+
+// BuildBCBP menyusun payload format "M1" (60 karakter, tanpa field
+// opsional) sesuai IATA Resolution 792.
+func BuildBCBP(d BCBPData) string {
+	var b strings.Builder
+	b.WriteString("M1")
+	b.WriteString(padRight(bcbpName(d.PassengerName), 20))
+	b.WriteString("E")
+	b.WriteString(padRight(strings.ToUpper(d.BookingCode), 7))
+	b.WriteString(padRight(strings.ToUpper(d.FromAirport), 3))
+	b.WriteString(padRight(strings.ToUpper(d.ToAirport), 3))
+	b.WriteString(padRight(strings.ToUpper(d.CarrierCode), 3))
+	b.WriteString(bcbpFlightNumber(d.FlightNumber))
+	b.WriteString(fmt.Sprintf("%03d", d.FlightDate.YearDay()))
+	b.WriteString(bcbpCompartment(d.SeatClass))
+	b.WriteString(bcbpSeat(d.SeatNumber, d.IsInfant))
+	b.WriteString(padRight(fmt.Sprintf("%04d", d.SequenceNumber), 5))
+	b.WriteString("1")
+	b.WriteString("00")
+	return b.String()
+}
+
+// bcbpName mengubah "Budi Santoso" menjadi "SANTOSO/BUDI".
+func bcbpName(fullName string) string {
+	parts := strings.Fields(strings.ToUpper(fullName))
+	if len(parts) == 0 {
+		return ""
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	return parts[len(parts)-1] + "/" + strings.Join(parts[:len(parts)-1], " ")
+}
+
+// bcbpFlightNumber mengambil angka nomor penerbangan ("GA-123" -> "0123 ").
+func bcbpFlightNumber(flightNumber string) string {
+	digits := ""
+	suffix := ""
+	for _, r := range flightNumber {
+		if unicode.IsDigit(r) {
+			digits += string(r)
+		} else if digits != "" && unicode.IsLetter(r) && suffix == "" {
+			suffix = strings.ToUpper(string(r))
+		}
+	}
+	if len(digits) > 4 {
+		digits = digits[len(digits)-4:]
+	}
+	return padLeftZero(digits, 4) + padRight(suffix, 1)
+}
+
+func bcbpCompartment(seatClass string) string {
+	switch strings.ToLower(seatClass) {
+	case "first_class":
+		return "F"
+	case "business":
+		return "C"
+	default:
+		return "Y"
+	}
+}
+
+// bcbpSeat menghasilkan 4 karakter, misalnya "12A" -> "012A".
+func bcbpSeat(seatNumber string, isInfant bool) string {
+	if isInfant {
+		return "INF "
+	}
+	seatNumber = strings.ToUpper(strings.TrimSpace(seatNumber))
+	if seatNumber == "" {
+		return "    "
+	}
+
+	row := strings.TrimRightFunc(seatNumber, unicode.IsLetter)
+	column := strings.TrimPrefix(seatNumber, row)
+	return padLeftZero(row, 3) + padRight(column, 1)
+}
+
+func padRight(s string, length int) string {
+	if len(s) >= length {
+		return s[:length]
+	}
+	return s + strings.Repeat(" ", length-len(s))
+}
+
+func padLeftZero(s string, length int) string {
+	if len(s) >= length {
+		return s[len(s)-length:]
+	}
+	return strings.Repeat("0", length-len(s)) + s
+}
@@ -5,6 +5,7 @@ type InvoiceItem struct {
     Product     string
     Description string
     Quantity    int
+    UnitPrice   string
     Total       string
 }
 
@@ -26,7 +27,12 @@ type InvoiceData struct {
     Passengers []Passenger
     Items      []InvoiceItem
     SubTotal   string
+    AirportTax string
+    VAT        string
+    Discount   string
+    PromoCode  string
     ServiceFee string
+    ChangeFee  string
     GrandTotal string
 }
 
@@ -59,6 +65,7 @@ type TicketPassenger struct {
     Number       int
     Name         string
     TicketNumber string
+    Seat         string
 }
 
 type TicketData struct {
@@ -71,4 +78,51 @@ type TicketData struct {
     QRCode      string
     Segments    []FlightSegment
     Passengers  []TicketPassenger
-}
\ No newline at end of file
+}
+
+type BoardingPass struct {
+    PassengerName string
+    TicketNumber  string
+    BookingCode   string
+    AirlineName   string
+    AirlineLogo   string
+    FlightNumber  string
+    FlightClass   string
+    Departure     FlightPoint
+    Arrival       FlightPoint
+    BoardingTime  string
+    Seat          string
+    Sequence      string
+    Barcode       string
+}
+
+type BoardingPassData struct {
+    HeaderImage string
+    Passes      []BoardingPass
+}
+
+type ManifestRow struct {
+    Number        int
+    Name          string
+    Type          string
+    DateOfBirth   string
+    Nationality   string
+    Passport      string
+    TicketNumber  string
+    BookingCode   string
+    BookingStatus string
+    SeatClass     string
+    Seat          string
+    CheckInStatus string
+}
+
+type ManifestData struct {
+    FlightCode      string
+    FlightNumber    string
+    Route           string
+    DepartureTime   string
+    ArrivalTime     string
+    GeneratedAt     string
+    TotalPassengers int
+    Passengers      []ManifestRow
+}
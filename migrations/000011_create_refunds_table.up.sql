@@ -0,0 +1,17 @@
+CREATE TABLE refunds (
+    id               SERIAL PRIMARY KEY,
+    order_id         VARCHAR(50) NOT NULL,
+    user_id          INT NOT NULL REFERENCES users(id),
+    refund_key       VARCHAR(100) UNIQUE NOT NULL,
+    paid_amount      NUMERIC(15,2) NOT NULL,
+    cancellation_fee NUMERIC(15,2) NOT NULL DEFAULT 0,
+    refund_amount    NUMERIC(15,2) NOT NULL,
+    reason           TEXT,
+    status           VARCHAR(20) NOT NULL DEFAULT 'pending',
+    failure_reason   TEXT,
+    processed_at     TIMESTAMP,
+    created_at       TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at       TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_refunds_order_id ON refunds(order_id);
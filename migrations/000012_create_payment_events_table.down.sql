@@ -0,0 +1 @@
+DROP TABLE IF EXISTS payment_events;
\ No newline at end of file
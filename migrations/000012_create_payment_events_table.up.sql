@@ -0,0 +1,19 @@
+CREATE TABLE payment_events (
+    id                 SERIAL PRIMARY KEY,
+    dedup_key          VARCHAR(255) UNIQUE NOT NULL,
+    order_id           VARCHAR(50),
+    transaction_id     VARCHAR(100),
+    transaction_status VARCHAR(50),
+    status_code        VARCHAR(10),
+    transaction_time   VARCHAR(50),
+    raw_payload        JSONB NOT NULL,
+    processing_status  VARCHAR(20) NOT NULL,
+    processing_note    TEXT,
+    replay_count       INT NOT NULL DEFAULT 0,
+    processed_at       TIMESTAMP,
+    created_at         TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at         TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_payment_events_order_id ON payment_events(order_id);
+CREATE INDEX idx_payment_events_transaction_id ON payment_events(transaction_id);
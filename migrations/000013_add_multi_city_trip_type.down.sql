@@ -0,0 +1,8 @@
+UPDATE bookings SET trip_type = 'round_trip' WHERE trip_type = 'multi_city';
+
+ALTER TYPE trip_type RENAME TO trip_type_old;
+CREATE TYPE trip_type AS ENUM ('one_way', 'round_trip');
+ALTER TABLE bookings ALTER COLUMN trip_type DROP DEFAULT;
+ALTER TABLE bookings ALTER COLUMN trip_type TYPE trip_type USING trip_type::text::trip_type;
+ALTER TABLE bookings ALTER COLUMN trip_type SET DEFAULT 'one_way';
+DROP TYPE trip_type_old;
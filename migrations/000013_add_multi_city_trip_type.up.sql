@@ -0,0 +1 @@
+ALTER TYPE trip_type ADD VALUE IF NOT EXISTS 'multi_city';
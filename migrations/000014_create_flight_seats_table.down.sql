@@ -0,0 +1 @@
+DROP TABLE IF EXISTS flight_seats;
\ No newline at end of file
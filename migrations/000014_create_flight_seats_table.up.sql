@@ -0,0 +1,19 @@
+CREATE TABLE flight_seats (
+    id                SERIAL PRIMARY KEY,
+    flight_leg_id     INT NOT NULL REFERENCES flight_legs(id) ON DELETE CASCADE,
+    seat_number       VARCHAR(5) NOT NULL,
+    row_number        INT NOT NULL,
+    column_letter     VARCHAR(2) NOT NULL,
+    seat_class        VARCHAR(50) NOT NULL,
+    is_exit_row       BOOLEAN NOT NULL DEFAULT FALSE,
+    is_window         BOOLEAN NOT NULL DEFAULT FALSE,
+    is_aisle          BOOLEAN NOT NULL DEFAULT FALSE,
+    is_blocked        BOOLEAN NOT NULL DEFAULT FALSE,
+    booking_detail_id INT REFERENCES booking_details(id) ON DELETE SET NULL,
+    created_at        TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (flight_leg_id, seat_number)
+);
+
+CREATE INDEX idx_flight_seats_flight_leg_id ON flight_seats(flight_leg_id);
+CREATE UNIQUE INDEX idx_flight_seats_leg_booking_detail ON flight_seats(flight_leg_id, booking_detail_id) WHERE booking_detail_id IS NOT NULL;
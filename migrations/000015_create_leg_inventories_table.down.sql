@@ -0,0 +1 @@
+DROP TABLE IF EXISTS leg_inventories;
\ No newline at end of file
@@ -0,0 +1,20 @@
+CREATE TABLE leg_inventories (
+    id                SERIAL PRIMARY KEY,
+    flight_number     VARCHAR(50) NOT NULL,
+    origin_airport_id INT NOT NULL REFERENCES airports(id),
+    departure_time    TIMESTAMP NOT NULL,
+    seat_class        VARCHAR(50) NOT NULL,
+    available_seats   INT NOT NULL DEFAULT 0 CHECK (available_seats >= 0),
+    created_at        TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at        TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (flight_number, origin_airport_id, departure_time, seat_class)
+);
+
+-- Isi awal dari stok per flight yang sudah ada. Jika satu leg dipakai beberapa
+-- flight, ambil stok terkecil supaya tidak terjadi overbooking.
+INSERT INTO leg_inventories (flight_number, origin_airport_id, departure_time, seat_class, available_seats)
+SELECT fl.flight_number, fl.origin_airport_id, fl.departure_time, fc.seat_class, MIN(fc.total_seats)
+FROM flight_legs fl
+JOIN flight_classes fc ON fc.flight_id = fl.flight_id
+WHERE fl.flight_number IS NOT NULL
+GROUP BY fl.flight_number, fl.origin_airport_id, fl.departure_time, fc.seat_class;
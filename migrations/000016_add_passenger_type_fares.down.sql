@@ -0,0 +1,5 @@
+ALTER TABLE flight_classes
+    DROP COLUMN IF EXISTS child_fare_type,
+    DROP COLUMN IF EXISTS child_fare_value,
+    DROP COLUMN IF EXISTS infant_fare_type,
+    DROP COLUMN IF EXISTS infant_fare_value;
@@ -0,0 +1,5 @@
+ALTER TABLE flight_classes
+    ADD COLUMN child_fare_type   VARCHAR(20) NOT NULL DEFAULT 'percentage',
+    ADD COLUMN child_fare_value  NUMERIC(15,2) NOT NULL DEFAULT 100,
+    ADD COLUMN infant_fare_type  VARCHAR(20) NOT NULL DEFAULT 'percentage',
+    ADD COLUMN infant_fare_value NUMERIC(15,2) NOT NULL DEFAULT 10;
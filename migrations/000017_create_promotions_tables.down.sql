@@ -0,0 +1,6 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS promo_code,
+    DROP COLUMN IF EXISTS discount_amount;
+
+DROP TABLE IF EXISTS promo_redemptions;
+DROP TABLE IF EXISTS promotions;
@@ -0,0 +1,40 @@
+CREATE TABLE promotions (
+    id                     SERIAL PRIMARY KEY,
+    code                   VARCHAR(30) UNIQUE NOT NULL,
+    description            TEXT,
+    discount_type          VARCHAR(20) NOT NULL,
+    discount_value         NUMERIC(15,2) NOT NULL,
+    max_discount           NUMERIC(15,2),
+    min_spend              NUMERIC(15,2) NOT NULL DEFAULT 0,
+    origin_airport_id      INT REFERENCES airports(id),
+    destination_airport_id INT REFERENCES airports(id),
+    airline_id             INT REFERENCES airlines(id),
+    seat_class             VARCHAR(50),
+    valid_from             TIMESTAMP NOT NULL,
+    valid_until            TIMESTAMP NOT NULL,
+    usage_limit            INT NOT NULL DEFAULT 0,
+    per_user_limit         INT NOT NULL DEFAULT 0,
+    used_count             INT NOT NULL DEFAULT 0,
+    is_active              BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at             TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at             TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE promo_redemptions (
+    id              SERIAL PRIMARY KEY,
+    promotion_id    INT NOT NULL REFERENCES promotions(id),
+    user_id         INT NOT NULL REFERENCES users(id),
+    order_id        VARCHAR(50) UNIQUE NOT NULL,
+    discount_amount NUMERIC(15,2) NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'active',
+    released_at     TIMESTAMP,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_promo_redemptions_promotion_id ON promo_redemptions(promotion_id);
+CREATE INDEX idx_promo_redemptions_user_id ON promo_redemptions(user_id);
+
+ALTER TABLE bookings
+    ADD COLUMN promo_code      VARCHAR(30),
+    ADD COLUMN discount_amount NUMERIC(15,2) NOT NULL DEFAULT 0;
@@ -0,0 +1,12 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS base_fare,
+    DROP COLUMN IF EXISTS airport_tax,
+    DROP COLUMN IF EXISTS vat_amount,
+    DROP COLUMN IF EXISTS convenience_fee;
+
+ALTER TABLE booking_details
+    DROP COLUMN IF EXISTS base_fare,
+    DROP COLUMN IF EXISTS airport_tax,
+    DROP COLUMN IF EXISTS vat_amount;
+
+DROP TABLE IF EXISTS fee_rules;
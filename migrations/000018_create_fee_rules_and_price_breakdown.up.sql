@@ -0,0 +1,37 @@
+CREATE TABLE fee_rules (
+    id             SERIAL PRIMARY KEY,
+    name           VARCHAR(100) NOT NULL,
+    fee_type       VARCHAR(30) NOT NULL,
+    calc_type      VARCHAR(20) NOT NULL,
+    value          NUMERIC(15,2) NOT NULL,
+    airport_id     INT REFERENCES airports(id),
+    payment_type   VARCHAR(30),
+    passenger_type VARCHAR(20),
+    is_active      BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at     TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at     TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_fee_rules_fee_type ON fee_rules(fee_type);
+
+INSERT INTO fee_rules (name, fee_type, calc_type, value, payment_type) VALUES
+    ('PPN 11%', 'vat', 'percentage', 11, NULL),
+    ('Biaya Layanan Virtual Account', 'convenience_fee', 'fixed', 4000, 'bank_transfer'),
+    ('Biaya Layanan Mandiri Bill', 'convenience_fee', 'fixed', 4000, 'echannel'),
+    ('Biaya Layanan QRIS', 'convenience_fee', 'percentage', 0.7, 'qris'),
+    ('Biaya Layanan GoPay', 'convenience_fee', 'percentage', 2, 'gopay');
+
+ALTER TABLE booking_details
+    ADD COLUMN base_fare   NUMERIC(15,2) NOT NULL DEFAULT 0,
+    ADD COLUMN airport_tax NUMERIC(15,2) NOT NULL DEFAULT 0,
+    ADD COLUMN vat_amount  NUMERIC(15,2) NOT NULL DEFAULT 0;
+
+ALTER TABLE bookings
+    ADD COLUMN base_fare       NUMERIC(15,2) NOT NULL DEFAULT 0,
+    ADD COLUMN airport_tax     NUMERIC(15,2) NOT NULL DEFAULT 0,
+    ADD COLUMN vat_amount      NUMERIC(15,2) NOT NULL DEFAULT 0,
+    ADD COLUMN convenience_fee NUMERIC(15,2) NOT NULL DEFAULT 0;
+
+-- Booking lama belum punya rincian, seluruh harga dianggap base fare
+UPDATE booking_details SET base_fare = price;
+UPDATE bookings SET base_fare = total_price + discount_amount;
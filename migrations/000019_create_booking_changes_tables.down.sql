@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS booking_change_passengers;
+DROP TABLE IF EXISTS booking_changes;
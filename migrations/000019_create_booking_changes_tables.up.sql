@@ -0,0 +1,40 @@
+CREATE TABLE booking_changes (
+    id              SERIAL PRIMARY KEY,
+    change_code     VARCHAR(50) UNIQUE NOT NULL,
+    order_id        VARCHAR(50) NOT NULL,
+    booking_id      INT NOT NULL REFERENCES bookings(id) ON DELETE CASCADE,
+    user_id         INT NOT NULL REFERENCES users(id),
+    old_flight_id   INT NOT NULL REFERENCES flights(id),
+    new_flight_id   INT NOT NULL REFERENCES flights(id),
+    old_seat_class  VARCHAR(50) NOT NULL,
+    new_seat_class  VARCHAR(50) NOT NULL,
+    old_fare_total  NUMERIC(15,2) NOT NULL,
+    new_fare_total  NUMERIC(15,2) NOT NULL,
+    fare_difference NUMERIC(15,2) NOT NULL,
+    change_fee      NUMERIC(15,2) NOT NULL DEFAULT 0,
+    amount_due      NUMERIC(15,2) NOT NULL,
+    status          VARCHAR(20) NOT NULL DEFAULT 'pending_payment',
+    expired_at      TIMESTAMP,
+    completed_at    TIMESTAMP,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_booking_changes_order_id ON booking_changes(order_id);
+CREATE INDEX idx_booking_changes_booking_id ON booking_changes(booking_id);
+CREATE INDEX idx_booking_changes_status_expired_at ON booking_changes(status, expired_at);
+
+CREATE TABLE booking_change_passengers (
+    id                SERIAL PRIMARY KEY,
+    booking_change_id INT NOT NULL REFERENCES booking_changes(id) ON DELETE CASCADE,
+    booking_detail_id INT NOT NULL REFERENCES booking_details(id) ON DELETE CASCADE,
+    old_ticket_number VARCHAR(50) NOT NULL,
+    new_ticket_number VARCHAR(50) UNIQUE NOT NULL,
+    base_fare         NUMERIC(15,2) NOT NULL,
+    airport_tax       NUMERIC(15,2) NOT NULL DEFAULT 0,
+    vat_amount        NUMERIC(15,2) NOT NULL DEFAULT 0,
+    price             NUMERIC(15,2) NOT NULL,
+    created_at        TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_booking_change_passengers_change_id ON booking_change_passengers(booking_change_id);
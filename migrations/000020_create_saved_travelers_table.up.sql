@@ -0,0 +1,15 @@
+CREATE TABLE saved_travelers (
+    id              SERIAL PRIMARY KEY,
+    user_id         INT NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    title           VARCHAR(10) NOT NULL,
+    full_name       VARCHAR(255) NOT NULL,
+    dob             DATE NOT NULL,
+    nationality     VARCHAR(50) NOT NULL,
+    passport_number VARCHAR(50),
+    issuing_country VARCHAR(50),
+    valid_until     DATE,
+    created_at      TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at      TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_saved_travelers_user_id ON saved_travelers(user_id);
@@ -0,0 +1,4 @@
+ALTER TABLE booking_details
+    DROP COLUMN IF EXISTS check_in_status,
+    DROP COLUMN IF EXISTS checked_in_at,
+    DROP COLUMN IF EXISTS boarding_sequence;
@@ -0,0 +1,4 @@
+ALTER TABLE booking_details
+    ADD COLUMN check_in_status   VARCHAR(20) NOT NULL DEFAULT 'not_checked_in',
+    ADD COLUMN checked_in_at     TIMESTAMP,
+    ADD COLUMN boarding_sequence INT NOT NULL DEFAULT 0;
@@ -0,0 +1,13 @@
+CREATE TABLE password_resets (
+    id          SERIAL PRIMARY KEY,
+    user_id     INT NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    token_hash  VARCHAR(64) NOT NULL UNIQUE,
+    expired_at  TIMESTAMP NOT NULL,
+    used_at     TIMESTAMP,
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_password_resets_user_id ON password_resets(user_id);
+
+ALTER TABLE users
+    ADD COLUMN password_changed_at TIMESTAMP;
@@ -0,0 +1,16 @@
+CREATE TABLE user_sessions (
+    id                  SERIAL PRIMARY KEY,
+    user_id             INT NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    refresh_token_hash  VARCHAR(64) NOT NULL DEFAULT '',
+    device              VARCHAR(255) NOT NULL DEFAULT '',
+    ip_address          VARCHAR(45) NOT NULL DEFAULT '',
+    user_agent          TEXT NOT NULL DEFAULT '',
+    last_used_at        TIMESTAMP NOT NULL DEFAULT NOW(),
+    expires_at          TIMESTAMP NOT NULL,
+    revoked_at          TIMESTAMP,
+    revoked_reason      VARCHAR(50),
+    created_at          TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at          TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_user_sessions_user_id ON user_sessions(user_id);
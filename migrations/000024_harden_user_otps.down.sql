@@ -0,0 +1,9 @@
+DROP TABLE IF EXISTS otp_send_logs;
+
+DELETE FROM user_otps;
+
+ALTER TABLE user_otps
+    DROP COLUMN IF EXISTS otp_hash,
+    DROP COLUMN IF EXISTS failed_attempts,
+    DROP COLUMN IF EXISTS locked_until,
+    ADD COLUMN otp_code VARCHAR(6) NOT NULL DEFAULT '';
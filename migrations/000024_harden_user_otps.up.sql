@@ -0,0 +1,19 @@
+-- Kode lama tersimpan plaintext dan tidak bisa di-hash ulang. Masa berlakunya
+-- hanya 5 menit, jadi user yang terdampak cukup meminta kode baru.
+DELETE FROM user_otps;
+
+ALTER TABLE user_otps
+    DROP COLUMN otp_code,
+    ADD COLUMN otp_hash        VARCHAR(64) NOT NULL DEFAULT '',
+    ADD COLUMN failed_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN locked_until    TIMESTAMPTZ;
+
+CREATE TABLE otp_send_logs (
+    id          SERIAL PRIMARY KEY,
+    email       VARCHAR(255) NOT NULL,
+    ip_address  VARCHAR(45) NOT NULL DEFAULT '',
+    created_at  TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_otp_send_logs_email_created_at ON otp_send_logs(email, created_at);
+CREATE INDEX idx_otp_send_logs_ip_address_created_at ON otp_send_logs(ip_address, created_at);
@@ -0,0 +1,3 @@
+ALTER TABLE users
+    ADD COLUMN failed_login_attempts INT NOT NULL DEFAULT 0,
+    ADD COLUMN login_locked_until    TIMESTAMP;
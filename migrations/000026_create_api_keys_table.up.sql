@@ -0,0 +1,19 @@
+CREATE TABLE api_keys (
+    id                      SERIAL PRIMARY KEY,
+    user_id                 INT NOT NULL REFERENCES users(id) ON UPDATE CASCADE ON DELETE CASCADE,
+    name                    VARCHAR(100) NOT NULL,
+    key_prefix              VARCHAR(16) NOT NULL,
+    key_hash                VARCHAR(64) NOT NULL,
+    scopes                  VARCHAR(255) NOT NULL DEFAULT '',
+    rate_limit_per_minute   INT NOT NULL DEFAULT 60,
+    last_used_at            TIMESTAMP,
+    last_used_ip            VARCHAR(45),
+    expires_at              TIMESTAMP,
+    revoked_at              TIMESTAMP,
+    created_by              INT NOT NULL REFERENCES users(id),
+    created_at              TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at              TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_api_keys_key_hash ON api_keys(key_hash);
+CREATE INDEX idx_api_keys_user_id ON api_keys(user_id);
@@ -0,0 +1,2 @@
+-- Nomor pengganti yang sudah terisi dibiarkan karena stok leg memakainya
+ALTER TABLE flight_legs ALTER COLUMN flight_number DROP NOT NULL;
@@ -0,0 +1,17 @@
+-- Stok per leg dikunci dengan flight_number, tetapi kolom ini sempat boleh
+-- kosong sehingga leg lama tidak ikut diisi di 000015 dan flight-nya tidak
+-- bisa dipesan. Leg tanpa nomor memakai kode flight induknya sebagai pengganti.
+UPDATE flight_legs fl
+SET flight_number = LEFT(COALESCE(NULLIF(f.flight_code, ''), 'FL' || f.id), 50)
+FROM flights f
+WHERE f.id = fl.flight_id
+  AND (fl.flight_number IS NULL OR fl.flight_number = '');
+
+ALTER TABLE flight_legs ALTER COLUMN flight_number SET NOT NULL;
+
+INSERT INTO leg_inventories (flight_number, origin_airport_id, departure_time, seat_class, available_seats)
+SELECT fl.flight_number, fl.origin_airport_id, fl.departure_time, fc.seat_class, MIN(fc.total_seats)
+FROM flight_legs fl
+JOIN flight_classes fc ON fc.flight_id = fl.flight_id
+GROUP BY fl.flight_number, fl.origin_airport_id, fl.departure_time, fc.seat_class
+ON CONFLICT (flight_number, origin_airport_id, departure_time, seat_class) DO NOTHING;
@@ -0,0 +1,25 @@
+-- Denah dikembalikan ke leg dengan id terkecil yang memakai leg fisik tersebut
+ALTER TABLE flight_seats ADD COLUMN flight_leg_id INT REFERENCES flight_legs(id) ON DELETE CASCADE;
+
+UPDATE flight_seats fs
+SET flight_leg_id = (
+    SELECT MIN(fl.id) FROM flight_legs fl
+    WHERE fl.flight_number = fs.flight_number
+      AND fl.origin_airport_id = fs.origin_airport_id
+      AND fl.departure_time = fs.departure_time
+);
+
+DELETE FROM flight_seats WHERE flight_leg_id IS NULL;
+
+DROP INDEX IF EXISTS idx_flight_seats_leg_seat;
+DROP INDEX IF EXISTS idx_flight_seats_leg_booking_detail;
+
+ALTER TABLE flight_seats
+    ALTER COLUMN flight_leg_id SET NOT NULL,
+    DROP COLUMN flight_number,
+    DROP COLUMN origin_airport_id,
+    DROP COLUMN departure_time,
+    ADD CONSTRAINT flight_seats_flight_leg_id_seat_number_key UNIQUE (flight_leg_id, seat_number);
+
+CREATE INDEX idx_flight_seats_flight_leg_id ON flight_seats(flight_leg_id);
+CREATE UNIQUE INDEX idx_flight_seats_leg_booking_detail ON flight_seats(flight_leg_id, booking_detail_id) WHERE booking_detail_id IS NOT NULL;
@@ -0,0 +1,54 @@
+-- Denah kursi dipindah dari flight_legs.id ke leg fisik (flight_number, bandara
+-- asal, jam berangkat), kunci yang sama dengan leg_inventories. Flight yang
+-- berbagi leg kini memakai satu denah, dan mengubah flight tidak lagi
+-- menghapus kursi lewat ON DELETE CASCADE.
+ALTER TABLE flight_seats
+    ADD COLUMN flight_number     VARCHAR(50),
+    ADD COLUMN origin_airport_id INT REFERENCES airports(id),
+    ADD COLUMN departure_time    TIMESTAMP;
+
+UPDATE flight_seats fs
+SET flight_number     = fl.flight_number,
+    origin_airport_id = fl.origin_airport_id,
+    departure_time    = fl.departure_time
+FROM flight_legs fl
+WHERE fl.id = fs.flight_leg_id;
+
+-- Leg fisik yang sempat punya beberapa denah disatukan ke denah leg dengan id
+-- terkecil. Penumpang di denah lain dipindah ke nomor kursi yang sama jika
+-- kursi itu masih kosong; sisanya dilepas dan bisa memilih kursi lagi.
+CREATE TEMP TABLE seat_map_keepers AS
+SELECT flight_number, origin_airport_id, departure_time, MIN(flight_leg_id) AS keep_leg_id
+FROM flight_seats
+GROUP BY flight_number, origin_airport_id, departure_time;
+
+UPDATE flight_seats keep
+SET booking_detail_id = dup.booking_detail_id
+FROM flight_seats dup
+JOIN seat_map_keepers k
+  ON k.flight_number = dup.flight_number
+ AND k.origin_airport_id = dup.origin_airport_id
+ AND k.departure_time = dup.departure_time
+WHERE dup.flight_leg_id <> k.keep_leg_id
+  AND dup.booking_detail_id IS NOT NULL
+  AND keep.flight_leg_id = k.keep_leg_id
+  AND keep.seat_number = dup.seat_number
+  AND keep.booking_detail_id IS NULL;
+
+DELETE FROM flight_seats fs
+USING seat_map_keepers k
+WHERE k.flight_number = fs.flight_number
+  AND k.origin_airport_id = fs.origin_airport_id
+  AND k.departure_time = fs.departure_time
+  AND fs.flight_leg_id <> k.keep_leg_id;
+
+DROP TABLE seat_map_keepers;
+
+ALTER TABLE flight_seats
+    ALTER COLUMN flight_number SET NOT NULL,
+    ALTER COLUMN origin_airport_id SET NOT NULL,
+    ALTER COLUMN departure_time SET NOT NULL,
+    DROP COLUMN flight_leg_id;
+
+CREATE UNIQUE INDEX idx_flight_seats_leg_seat ON flight_seats(flight_number, origin_airport_id, departure_time, seat_number);
+CREATE UNIQUE INDEX idx_flight_seats_leg_booking_detail ON flight_seats(flight_number, origin_airport_id, departure_time, booking_detail_id) WHERE booking_detail_id IS NOT NULL;
@@ -0,0 +1,14 @@
+ALTER TABLE booking_details
+    ADD COLUMN boarding_sequence INT NOT NULL DEFAULT 0;
+
+-- Kolom lama hanya menyimpan satu nomor, diambil dari leg pertama
+UPDATE booking_details bd
+SET boarding_sequence = first_leg.sequence
+FROM (
+    SELECT DISTINCT ON (booking_detail_id) booking_detail_id, sequence
+    FROM leg_boarding_sequences
+    ORDER BY booking_detail_id, departure_time
+) first_leg
+WHERE first_leg.booking_detail_id = bd.id;
+
+DROP TABLE IF EXISTS leg_boarding_sequences;
@@ -0,0 +1,34 @@
+-- Nomor urut boarding dihitung per leg fisik, bukan per flight: penumpang dari
+-- beberapa flight yang berbagi leg naik pesawat yang sama, dan penumpang
+-- transit punya nomor urut sendiri di setiap leg.
+CREATE TABLE leg_boarding_sequences (
+    id                SERIAL PRIMARY KEY,
+    booking_detail_id INT NOT NULL REFERENCES booking_details(id) ON DELETE CASCADE,
+    flight_number     VARCHAR(50) NOT NULL,
+    origin_airport_id INT NOT NULL REFERENCES airports(id),
+    departure_time    TIMESTAMP NOT NULL,
+    sequence          INT NOT NULL,
+    created_at        TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE UNIQUE INDEX idx_leg_boarding_sequences_leg_sequence
+    ON leg_boarding_sequences (flight_number, origin_airport_id, departure_time, sequence);
+CREATE UNIQUE INDEX idx_leg_boarding_sequences_leg_detail
+    ON leg_boarding_sequences (flight_number, origin_airport_id, departure_time, booking_detail_id);
+
+-- Penumpang yang sudah check-in diberi nomor ulang per leg fisik sesuai
+-- urutan check-in.
+INSERT INTO leg_boarding_sequences (booking_detail_id, flight_number, origin_airport_id, departure_time, sequence, created_at)
+SELECT bd.id, fl.flight_number, fl.origin_airport_id, fl.departure_time,
+       ROW_NUMBER() OVER (
+           PARTITION BY fl.flight_number, fl.origin_airport_id, fl.departure_time
+           ORDER BY bd.checked_in_at, bd.id
+       ),
+       COALESCE(bd.checked_in_at, NOW())
+FROM booking_details bd
+JOIN bookings b ON b.id = bd.booking_id
+JOIN flight_legs fl ON fl.flight_id = b.flight_id
+WHERE bd.check_in_status = 'checked_in';
+
+ALTER TABLE booking_details
+    DROP COLUMN IF EXISTS boarding_sequence;
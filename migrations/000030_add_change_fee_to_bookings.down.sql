@@ -0,0 +1,3 @@
+ALTER TABLE bookings
+    DROP COLUMN IF EXISTS change_fee,
+    DROP COLUMN IF EXISTS retained_fare_difference;
@@ -0,0 +1,19 @@
+ALTER TABLE bookings
+    ADD COLUMN change_fee               NUMERIC(15,2) NOT NULL DEFAULT 0,
+    ADD COLUMN retained_fare_difference NUMERIC(15,2) NOT NULL DEFAULT 0;
+
+-- Booking yang sudah di-reschedule: biaya perubahan diambil dari
+-- booking_changes, sisanya adalah selisih tarif yang tidak dikembalikan
+UPDATE bookings b
+SET change_fee = c.change_fee
+FROM (
+    SELECT booking_id, SUM(change_fee) AS change_fee
+    FROM booking_changes
+    WHERE status = 'completed'
+    GROUP BY booking_id
+) c
+WHERE c.booking_id = b.id;
+
+UPDATE bookings
+SET retained_fare_difference = GREATEST(total_price - (base_fare + airport_tax + vat_amount + convenience_fee - discount_amount) - change_fee, 0)
+WHERE id IN (SELECT booking_id FROM booking_changes WHERE status = 'completed');
@@ -0,0 +1,158 @@
+package apperror
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// Kind menentukan kategori error dan status HTTP yang dipakai error handler.
+type Kind string
+
+const (
+	KindBadRequest        Kind = "bad_request"
+	KindValidation        Kind = "validation"
+	KindUnauthorized      Kind = "unauthorized"
+	KindForbidden         Kind = "forbidden"
+	KindNotFound          Kind = "not_found"
+	KindConflict          Kind = "conflict"
+	KindExpired           Kind = "expired"
+	KindInsufficientStock Kind = "insufficient_stock"
+	KindTooManyRequests   Kind = "too_many_requests"
+	KindInternal          Kind = "internal"
+)
+
+const CodeInternal = "INTERNAL_ERROR"
+
+// Error adalah error domain dengan Code yang stabil untuk dibaca klien.
+// Dua Error dianggap sama oleh errors.Is jika Code-nya sama, sehingga
+// sentinel yang sudah diberi detail lewat Withf tetap bisa dicocokkan.
+type Error struct {
+	Kind    Kind
+	Code    string
+	Message string
+	Details interface{}
+	Err     error
+	// RetryAfter dikirim sebagai header Retry-After jika lebih dari nol
+	RetryAfter time.Duration
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %v", e.Message, e.Err)
+	}
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && t.Code == e.Code
+}
+
+// Withf mengembalikan salinan error dengan pesan yang lebih spesifik.
+func (e *Error) Withf(format string, args ...interface{}) *Error {
+	clone := *e
+	clone.Message = fmt.Sprintf(format, args...)
+	return &clone
+}
+
+// Wrap mengembalikan salinan error dengan penyebab aslinya.
+func (e *Error) Wrap(err error) *Error {
+	clone := *e
+	clone.Err = err
+	return &clone
+}
+
+func (e *Error) WithDetails(details interface{}) *Error {
+	clone := *e
+	clone.Details = details
+	return &clone
+}
+
+func (e *Error) WithRetryAfter(d time.Duration) *Error {
+	clone := *e
+	clone.RetryAfter = d
+	return &clone
+}
+
+func New(kind Kind, code, message string) *Error {
+	return &Error{Kind: kind, Code: code, Message: message}
+}
+
+func BadRequest(code, message string) *Error {
+	return New(KindBadRequest, code, message)
+}
+
+func Validation(code, message string) *Error {
+	return New(KindValidation, code, message)
+}
+
+func Unauthorized(code, message string) *Error {
+	return New(KindUnauthorized, code, message)
+}
+
+func Forbidden(code, message string) *Error {
+	return New(KindForbidden, code, message)
+}
+
+func NotFound(code, message string) *Error {
+	return New(KindNotFound, code, message)
+}
+
+func Conflict(code, message string) *Error {
+	return New(KindConflict, code, message)
+}
+
+func Expired(code, message string) *Error {
+	return New(KindExpired, code, message)
+}
+
+func InsufficientStock(code, message string) *Error {
+	return New(KindInsufficientStock, code, message)
+}
+
+func TooManyRequests(code, message string) *Error {
+	return New(KindTooManyRequests, code, message)
+}
+
+// Internal membungkus error tak terduga. Pesan aslinya tidak dikirim ke klien.
+func Internal(err error) *Error {
+	return &Error{Kind: KindInternal, Code: CodeInternal, Message: "internal server error", Err: err}
+}
+
+// From mencari *Error di rantai err; error lain dianggap Internal.
+func From(err error) *Error {
+	var appErr *Error
+	if errors.As(err, &appErr) {
+		return appErr
+	}
+	return Internal(err)
+}
+
+func HTTPStatus(kind Kind) int {
+	switch kind {
+	case KindBadRequest:
+		return http.StatusBadRequest
+	case KindValidation:
+		return http.StatusUnprocessableEntity
+	case KindUnauthorized:
+		return http.StatusUnauthorized
+	case KindForbidden:
+		return http.StatusForbidden
+	case KindNotFound:
+		return http.StatusNotFound
+	case KindConflict, KindInsufficientStock:
+		return http.StatusConflict
+	case KindExpired:
+		return http.StatusGone
+	case KindTooManyRequests:
+		return http.StatusTooManyRequests
+	default:
+		return http.StatusInternalServerError
+	}
+}
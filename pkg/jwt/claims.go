@@ -7,5 +7,27 @@ type JWTClaims struct {
 	Role   string `json:"role"`
 	Email  string `json:"email,omitempty"`
 	Phone  string `json:"phone,omitempty"`
+	// SessionID menunjuk baris user_sessions tempat token ini diterbitkan
+	SessionID uint `json:"sid,omitempty"`
 	jwt.RegisteredClaims
 }
+
+type RefreshClaims struct {
+	UserID    uint `json:"user_id"`
+	SessionID uint `json:"sid"`
+	jwt.RegisteredClaims
+}
+
+const RoleAdmin = "admin"
+
+func (c *JWTClaims) IsAdmin() bool {
+	return c != nil && c.Role == RoleAdmin
+}
+
+// CanAccess mengizinkan pemilik resource atau admin.
+func (c *JWTClaims) CanAccess(ownerID uint) bool {
+	if c == nil {
+		return false
+	}
+	return c.IsAdmin() || c.UserID == ownerID
+}
@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
 	"os"
 	"time"
@@ -8,14 +10,21 @@ import (
 	"github.com/golang-jwt/jwt/v5"
 )
 
-func CreateAccessToken(userID uint, role, email, phone string) (string, error) {
+// Masa berlaku access token
+const AccessTokenTTL = 15 * time.Minute
+
+// Masa berlaku refresh token sekaligus sesi login di server
+const RefreshTokenTTL = 7 * 24 * time.Hour
+
+func CreateAccessToken(userID uint, role, email, phone string, sessionID uint) (string, error) {
 	claims := &JWTClaims{
-		UserID: userID,
-		Role:   role,
-		Email:  email,
-		Phone:  phone,
+		UserID:    userID,
+		Role:      role,
+		Email:     email,
+		Phone:     phone,
+		SessionID: sessionID,
 		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(time.Now().Add(15 * time.Minute)),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(AccessTokenTTL)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 		},
 	}
@@ -30,11 +39,22 @@ func CreateAccessToken(userID uint, role, email, phone string) (string, error) {
 	return token.SignedString([]byte(secret))
 }
 
-func CreateRefreshToken(userID uint) (string, error) {
-	claims := jwt.MapClaims{
-		"user_id": userID,
-		"exp":     time.Now().Add(7 * 24 * time.Hour).Unix(),
-		"iat":     time.Now().Unix(),
+// CreateRefreshToken membuat refresh token untuk satu sesi. jti acak membuat
+// setiap token hasil rotasi berbeda walaupun terbit di detik yang sama.
+func CreateRefreshToken(userID uint, sessionID uint) (string, error) {
+	jti := make([]byte, 16)
+	if _, err := rand.Read(jti); err != nil {
+		return "", err
+	}
+
+	claims := &RefreshClaims{
+		UserID:    userID,
+		SessionID: sessionID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        hex.EncodeToString(jti),
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(RefreshTokenTTL)),
+			IssuedAt:  jwt.NewNumericDate(time.Now()),
+		},
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -69,28 +89,23 @@ func ValidateAccessToken(tokenString string) (*JWTClaims, error) {
 	return claims, nil
 }
 
-func ValidateRefreshToken(tokenString string) (uint, error) {
+func ValidateRefreshToken(tokenString string) (*RefreshClaims, error) {
 	secret := []byte(os.Getenv("JWT_REFRESH_SECRET"))
 	if len(secret) == 0 {
-		return 0, errors.New("JWT_REFRESH_SECRET is missing")
+		return nil, errors.New("JWT_REFRESH_SECRET is missing")
 	}
 
-	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &RefreshClaims{}, func(t *jwt.Token) (interface{}, error) {
 		return secret, nil
 	})
-	if err != nil || !token.Valid {
-		return 0, errors.New("invalid or expired refresh token")
+	if err != nil || token == nil || !token.Valid {
+		return nil, errors.New("invalid or expired refresh token")
 	}
 
-	mapClaims, ok := token.Claims.(jwt.MapClaims)
-	if !ok {
-		return 0, errors.New("invalid refresh token claims")
+	claims, ok := token.Claims.(*RefreshClaims)
+	if !ok || claims.UserID == 0 || claims.SessionID == 0 || claims.IssuedAt == nil {
+		return nil, errors.New("invalid refresh token claims")
 	}
 
-	rawUserID, ok := mapClaims["user_id"].(float64)
-	if !ok {
-		return 0, errors.New("invalid user_id in refresh token")
-	}
-
-	return uint(rawUserID), nil
+	return claims, nil
 }
\ No newline at end of file
@@ -12,6 +12,7 @@ import (
 
 type MailService interface {
 	SendOTPEmail(toEmail string, name string, otpCode string) error
+	SendPasswordResetEmail(toEmail string, name string, resetLink string) error
 }
 
 type mailService struct {
@@ -90,7 +91,73 @@ func (s *mailService) SendOTPEmail(toEmail string, name string, otpCode string)
 	</html>
 	`
 
-	t, err := template.New("otp_email").Parse(htmlTemplate)
+	return s.send(toEmail, "Ezytix - Kode Verifikasi Akun Anda", "otp_email", htmlTemplate, data)
+}
+
+func (s *mailService) SendPasswordResetEmail(toEmail string, name string, resetLink string) error {
+	data := struct {
+		Name      string
+		ResetLink string
+	}{
+		Name:      name,
+		ResetLink: resetLink,
+	}
+
+	htmlTemplate := `
+	<!DOCTYPE html>
+	<html>
+	<head>
+		<meta charset="UTF-8">
+		<title>Reset Password Ezytix</title>
+		<style>
+			body { font-family: 'Segoe UI', Tahoma, Geneva, Verdana, sans-serif; background-color: #f9fafb; margin: 0; padding: 0; }
+			.container { max-width: 600px; margin: 40px auto; background-color: #ffffff; border-radius: 12px; padding: 40px; box-shadow: 0 4px 6px rgba(0,0,0,0.05); }
+			.header { text-align: center; margin-bottom: 30px; }
+			.logo { color: #dc2626; font-size: 32px; font-weight: bold; margin: 0; letter-spacing: -1px; }
+			.logo span { color: #1f2937; }
+			.content { color: #4b5563; line-height: 1.6; font-size: 16px; }
+			.greeting { font-weight: bold; color: #111827; font-size: 18px; margin-bottom: 20px; }
+			.button-box { text-align: center; margin: 30px 0; }
+			.button { display: inline-block; background-color: #dc2626; color: #ffffff !important; text-decoration: none; font-weight: bold; padding: 14px 32px; border-radius: 8px; }
+			.link { word-break: break-all; font-size: 13px; color: #6b7280; }
+			.warning { font-size: 13px; color: #6b7280; text-align: center; margin-top: 10px; }
+			.footer { margin-top: 40px; padding-top: 20px; border-top: 1px solid #e5e7eb; text-align: center; font-size: 13px; color: #9ca3af; }
+		</style>
+	</head>
+	<body>
+		<div class="container">
+			<div class="header">
+				<h1 class="logo">Ezy<span>tix</span></h1>
+			</div>
+			<div class="content">
+				<div class="greeting">Halo, {{.Name}}!</div>
+				<p>Kami menerima permintaan untuk mengatur ulang password akun Ezytix Anda. Klik tombol di bawah ini untuk membuat password baru:</p>
+
+				<div class="button-box">
+					<a class="button" href="{{.ResetLink}}">Atur Ulang Password</a>
+				</div>
+
+				<p>Jika tombol tidak berfungsi, salin tautan berikut ke browser Anda:</p>
+				<p class="link">{{.ResetLink}}</p>
+
+				<p class="warning">⚠️ Tautan ini hanya berlaku selama 30 menit dan hanya dapat digunakan satu kali. Setelah password diganti, Anda akan keluar dari semua perangkat.</p>
+
+				<p>Jika Anda tidak merasa meminta reset password, abaikan email ini. Password Anda tidak akan berubah.</p>
+			</div>
+			<div class="footer">
+				<p>&copy; 2025 Ezytix. All rights reserved.</p>
+				<p>Surakarta, Central Java, Indonesia</p>
+			</div>
+		</div>
+	</body>
+	</html>
+	`
+
+	return s.send(toEmail, "Ezytix - Atur Ulang Password Anda", "password_reset_email", htmlTemplate, data)
+}
+
+func (s *mailService) send(toEmail, subject, name, htmlTemplate string, data interface{}) error {
+	t, err := template.New(name).Parse(htmlTemplate)
 	if err != nil {
 		return fmt.Errorf("gagal parsing template: %v", err)
 	}
@@ -103,10 +170,9 @@ func (s *mailService) SendOTPEmail(toEmail string, name string, otpCode string)
 	m := gomail.NewMessage()
 	m.SetHeader("From", s.sender)
 	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", "Ezytix - Kode Verifikasi Akun Anda")
+	m.SetHeader("Subject", subject)
 	m.SetBody("text/html", body.String())
 
-	// 4. Kirim Email
 	if err := s.dialer.DialAndSend(m); err != nil {
 		return fmt.Errorf("gagal mengirim email: %v", err)
 	}
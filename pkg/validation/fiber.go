@@ -0,0 +1,26 @@
+package validation
+
+import (
+	"ezytix-be/pkg/apperror"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+var ErrInvalidBody = apperror.BadRequest("INVALID_REQUEST_BODY", "invalid request body")
+var ErrInvalidQuery = apperror.BadRequest("INVALID_QUERY_PARAMS", "invalid query params")
+
+// ParseBody membaca body request ke out lalu menjalankan validasi struct tag.
+func ParseBody(c *fiber.Ctx, out interface{}) error {
+	if err := c.BodyParser(out); err != nil {
+		return ErrInvalidBody.Wrap(err)
+	}
+	return Struct(out)
+}
+
+// ParseQuery membaca query string ke out lalu menjalankan validasi struct tag.
+func ParseQuery(c *fiber.Ctx, out interface{}) error {
+	if err := c.QueryParser(out); err != nil {
+		return ErrInvalidQuery.Wrap(err)
+	}
+	return Struct(out)
+}
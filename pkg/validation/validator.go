@@ -0,0 +1,169 @@
+package validation
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"ezytix-be/pkg/apperror"
+
+	"github.com/go-playground/validator/v10"
+)
+
+var validate = newValidator()
+
+// FieldError menjelaskan satu field yang gagal validasi, dengan pesan
+// dalam Bahasa Indonesia dan Inggris.
+type FieldError struct {
+	Field     string `json:"field"`
+	Rule      string `json:"rule"`
+	Param     string `json:"param,omitempty"`
+	MessageID string `json:"message_id"`
+	MessageEN string `json:"message_en"`
+}
+
+// ErrValidationFailed membawa []FieldError di Details.
+var ErrValidationFailed = apperror.Validation("VALIDATION_FAILED", "validation failed")
+
+func newValidator() *validator.Validate {
+	v := validator.New(validator.WithRequiredStructEnabled())
+
+	// Nama field di pesan error mengikuti tag json/query, bukan nama struct Go
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		for _, tag := range []string{"json", "query", "form"} {
+			name := strings.SplitN(field.Tag.Get(tag), ",", 2)[0]
+			if name != "" && name != "-" {
+				return name
+			}
+		}
+		return field.Name
+	})
+
+	return v
+}
+
+// Struct menjalankan tag `validate` pada s. Pelanggaran dikembalikan sebagai
+// ErrValidationFailed dengan daftar FieldError.
+func Struct(s interface{}) error {
+	err := validate.Struct(s)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+
+	var fieldErrors []FieldError
+	for _, fe := range validationErrs {
+		field := fieldPath(fe.Namespace())
+		messageID, messageEN := describe(field, fe)
+		fieldErrors = append(fieldErrors, FieldError{
+			Field:     field,
+			Rule:      fe.Tag(),
+			Param:     fe.Param(),
+			MessageID: messageID,
+			MessageEN: messageEN,
+		})
+	}
+	return ErrValidationFailed.WithDetails(fieldErrors)
+}
+
+// fieldPath membuang nama struct paling luar: "CreateOrderRequest.items[0].dob" -> "items[0].dob".
+func fieldPath(namespace string) string {
+	if idx := strings.Index(namespace, "."); idx >= 0 {
+		return namespace[idx+1:]
+	}
+	return namespace
+}
+
+func describe(field string, fe validator.FieldError) (string, string) {
+	param := fe.Param()
+
+	switch fe.Tag() {
+	case "required":
+		return fmt.Sprintf("%s wajib diisi", field),
+			fmt.Sprintf("%s is required", field)
+	case "required_without":
+		return fmt.Sprintf("%s wajib diisi jika %s kosong", field, param),
+			fmt.Sprintf("%s is required when %s is empty", field, param)
+	case "required_if":
+		condID, condEN := describeCondition(param)
+		return fmt.Sprintf("%s wajib diisi jika %s", field, condID),
+			fmt.Sprintf("%s is required when %s", field, condEN)
+	case "email":
+		return fmt.Sprintf("%s harus berupa alamat email yang valid", field),
+			fmt.Sprintf("%s must be a valid email address", field)
+	case "url":
+		return fmt.Sprintf("%s harus berupa URL yang valid", field),
+			fmt.Sprintf("%s must be a valid URL", field)
+	case "uppercase":
+		return fmt.Sprintf("%s harus menggunakan huruf kapital", field),
+			fmt.Sprintf("%s must be uppercase", field)
+	case "oneof":
+		options := strings.Join(strings.Fields(param), ", ")
+		return fmt.Sprintf("%s harus salah satu dari: %s", field, options),
+			fmt.Sprintf("%s must be one of: %s", field, options)
+	case "datetime":
+		layout := humanDateLayout(param)
+		return fmt.Sprintf("%s harus berformat %s", field, layout),
+			fmt.Sprintf("%s must use the format %s", field, layout)
+	case "gtefield":
+		return fmt.Sprintf("%s harus lebih besar atau sama dengan %s", field, param),
+			fmt.Sprintf("%s must be greater than or equal to %s", field, param)
+	case "nefield":
+		return fmt.Sprintf("%s tidak boleh sama dengan %s", field, param),
+			fmt.Sprintf("%s must be different from %s", field, param)
+	case "min", "max", "len":
+		return describeLength(field, fe.Tag(), param, fe.Kind())
+	}
+
+	return fmt.Sprintf("%s tidak valid", field),
+		fmt.Sprintf("%s is invalid", field)
+}
+
+func describeLength(field, tag, param string, kind reflect.Kind) (string, string) {
+	unitID, unitEN := "", ""
+	switch kind {
+	case reflect.String:
+		unitID, unitEN = " karakter", " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		unitID, unitEN = " item", " items"
+	}
+
+	switch tag {
+	case "min":
+		return fmt.Sprintf("%s minimal %s%s", field, param, unitID),
+			fmt.Sprintf("%s must be at least %s%s", field, param, unitEN)
+	case "max":
+		return fmt.Sprintf("%s maksimal %s%s", field, param, unitID),
+			fmt.Sprintf("%s must be at most %s%s", field, param, unitEN)
+	default:
+		return fmt.Sprintf("%s harus tepat %s%s", field, param, unitID),
+			fmt.Sprintf("%s must be exactly %s%s", field, param, unitEN)
+	}
+}
+
+// describeCondition mengubah param required_if "TripType round_trip" menjadi kalimat.
+func describeCondition(param string) (string, string) {
+	parts := strings.Fields(param)
+	var condID, condEN []string
+	for i := 0; i+1 < len(parts); i += 2 {
+		condID = append(condID, fmt.Sprintf("%s bernilai %s", parts[i], parts[i+1]))
+		condEN = append(condEN, fmt.Sprintf("%s is %s", parts[i], parts[i+1]))
+	}
+	return strings.Join(condID, " dan "), strings.Join(condEN, " and ")
+}
+
+func humanDateLayout(layout string) string {
+	return strings.NewReplacer(
+		"2006", "YYYY",
+		"01", "MM",
+		"02", "DD",
+		"15", "HH",
+		"04", "mm",
+		"05", "ss",
+	).Replace(layout)
+}